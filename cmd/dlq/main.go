@@ -0,0 +1,180 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "log"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+    "github.com/nats-io/nats.go"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dlq"
+)
+
+const usage = `Usage: dlq [flags] <command> [seq...]
+
+Commands:
+  list               List dead letters
+  inspect <seq>      Show a dead letter including its raw payload
+  replay <seq>...    Re-publish dead letters onto casino.events and remove them
+  delete <seq>...    Remove dead letters without replaying
+
+Flags:
+`
+
+func main() {
+    enricher := flag.String("enricher", "", "Only list or replay dead letters from this enricher")
+    all := flag.Bool("all", false, "Replay every dead letter (combined with -enricher if set)")
+    flag.Usage = func() {
+        fmt.Fprint(os.Stderr, usage)
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() < 1 {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatalf("Failed to load config: %v", err)
+    }
+
+    nc, err := nats.Connect(cfg.NATSURL)
+    if err != nil {
+        log.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+    defer cancel()
+
+    store, err := dlq.NewStore(ctx, nc)
+    if err != nil {
+        log.Fatalf("Failed to open dead-letter store: %v", err)
+    }
+
+    switch cmd := flag.Arg(0); cmd {
+    case "list":
+        entries, err := store.List(ctx)
+        if err != nil {
+            log.Fatalf("Failed to list dead letters: %v", err)
+        }
+        printEntries(filter(entries, *enricher))
+
+    case "inspect":
+        for _, seq := range parseSeqs(flag.Args()[1:]) {
+            entry, err := store.Get(ctx, seq)
+            if err != nil {
+                log.Fatalf("%v", err)
+            }
+            printEntry(entry)
+        }
+
+    case "replay":
+        seqs := parseSeqs(flag.Args()[1:])
+        if *all {
+            entries, err := store.List(ctx)
+            if err != nil {
+                log.Fatalf("Failed to list dead letters: %v", err)
+            }
+            for _, e := range filter(entries, *enricher) {
+                seqs = append(seqs, e.Seq)
+            }
+        }
+        if len(seqs) == 0 {
+            log.Fatalf("Nothing to replay: pass sequence numbers or -all")
+        }
+        for _, seq := range seqs {
+            if err := store.Replay(ctx, seq); err != nil {
+                log.Fatalf("%v", err)
+            }
+            fmt.Printf("Replayed %d\n", seq)
+        }
+
+    case "delete":
+        for _, seq := range parseSeqs(flag.Args()[1:]) {
+            if err := store.Delete(ctx, seq); err != nil {
+                log.Fatalf("%v", err)
+            }
+            fmt.Printf("Deleted %d\n", seq)
+        }
+
+    default:
+        log.Printf("Unknown command %q", cmd)
+        flag.Usage()
+        os.Exit(2)
+    }
+}
+
+func parseSeqs(args []string) []uint64 {
+    seqs := make([]uint64, 0, len(args))
+    for _, arg := range args {
+        seq, err := strconv.ParseUint(arg, 10, 64)
+        if err != nil {
+            log.Fatalf("Invalid sequence number %q: %v", arg, err)
+        }
+        seqs = append(seqs, seq)
+    }
+    return seqs
+}
+
+func filter(entries []dlq.Entry, enricher string) []dlq.Entry {
+    if enricher == "" {
+        return entries
+    }
+    var out []dlq.Entry
+    for _, e := range entries {
+        if e.Enricher == enricher {
+            out = append(out, e)
+        }
+    }
+    return out
+}
+
+func printEntries(entries []dlq.Entry) {
+    fmt.Printf("%-8s %-25s %-20s %-8s %s\n", "Seq", "Failed At", "Enricher", "Attempts", "Error")
+    fmt.Println(strings.Repeat("-", 100))
+    for _, e := range entries {
+        fmt.Printf("%-8d %-25s %-20s %-8d %s\n",
+            e.Seq, e.FailedAt.Format(time.RFC3339), e.Enricher, e.Attempts, e.Error)
+    }
+    fmt.Printf("\n%d dead letter(s)\n", len(entries))
+}
+
+func printEntry(e dlq.Entry) {
+    fmt.Printf("Seq:       %d\n", e.Seq)
+    fmt.Printf("Failed At: %s\n", e.FailedAt.Format(time.RFC3339))
+    fmt.Printf("Enricher:  %s\n", e.Enricher)
+    fmt.Printf("Attempts:  %d\n", e.Attempts)
+    fmt.Printf("Error:     %s\n", e.Error)
+    if e.Reason != "" {
+        fmt.Printf("Reason:    %s\n", e.Reason)
+    }
+    if e.ContentType != "" {
+        fmt.Printf("Content:   %s\n", e.ContentType)
+    }
+
+    // Protobuf payloads that still decode are shown as JSON.
+    data := e.Payload
+    if c, err := codec.ForContentType(e.ContentType); err == nil && c != codec.JSON {
+        if env, err := c.UnmarshalEnvelope(data); err == nil {
+            data, _ = json.Marshal(env)
+        }
+    }
+
+    // Pretty-print JSON payloads, show anything else verbatim.
+    var payload interface{}
+    if err := json.Unmarshal(data, &payload); err == nil {
+        pretty, _ := json.MarshalIndent(payload, "           ", "  ")
+        fmt.Printf("Payload:   %s\n\n", pretty)
+    } else {
+        fmt.Printf("Payload:   %q\n\n", e.Payload)
+    }
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+	"github.com/nats-io/nats.go"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/generator"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/loadtest"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/publisher"
+)
+
+const usage = `Usage: loadtest [flags]
+
+Publishes generated events onto casino.events at a target rate, ramping
+up in steps, and measures end-to-end latency on casino.events.enriched.
+The subscriber must be running. The report is written at the end.
+
+Flags:
+`
+
+func main() {
+	target := flag.Float64("rate", 100, "Target events per second of the last step")
+	steps := flag.Int("steps", 1, "Number of equal steps ramping up to -rate")
+	step := flag.Duration("step", 30*time.Second, "Duration of each step")
+	plan := flag.String("plan", "", "Explicit steps as rate:duration,..., overriding -rate, -steps and -step")
+	maxInFlight := flag.Int("max-in-flight", loadtest.DefaultMaxInFlight, "Maximum outstanding sends; sends due beyond it are missed")
+	drain := flag.Duration("drain", 10*time.Second, "How long to wait for enriched events after the last step")
+	out := flag.String("report", "-", "Report file, - for stdout; .json files get JSON, others Markdown")
+	flag.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	stepPlan := loadtest.Ramp(*target, *steps, *step)
+	if *plan != "" {
+		var err error
+		if stepPlan, err = loadtest.ParseSteps(*plan); err != nil {
+			log.Fatalf("Invalid -plan: %v", err)
+		}
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Events come as fast as the rate asks; scenario pacing is ignored.
+	var gen generator.Generator = generator.GeneratorFunc(generator.Generate)
+	if cfg.GeneratorScenario != "" {
+		scenario, err := generator.LoadScenario(cfg.GeneratorScenario)
+		if err != nil {
+			log.Fatalf("Failed to load GENERATOR_SCENARIO: %v", err)
+		}
+		scenario.Realtime = false
+		gen = generator.NewSimulator(scenario)
+	}
+
+	var pub *publisher.Service
+	if cfg.NATSJetStream {
+		pub, err = publisher.NewJetStream(ctx, cfg.NATSURL, gen)
+	} else {
+		pub, err = publisher.New(cfg.NATSURL, gen)
+	}
+	if err != nil {
+		log.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
+
+	eventCodec, err := codec.ByName(cfg.EventCodec)
+	if err != nil {
+		log.Fatalf("Invalid EVENT_CODEC: %v", err)
+	}
+	pub.SetCodec(eventCodec)
+	pub.SetSource("loadtest")
+	pub.SetStampSentAt(true)
+
+	// The consumer runs in this process, so send and receive times come
+	// from the same clock.
+	nc, err := nats.Connect(cfg.NATSURL)
+	if err != nil {
+		log.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+	recorder := loadtest.NewRecorder()
+	if err := recorder.Subscribe(ctx, nc); err != nil {
+		log.Fatalf("Failed to subscribe to enriched events: %v", err)
+	}
+
+	genCtx, stopGen := context.WithCancel(ctx)
+	defer stopGen()
+	runner := loadtest.NewPublisher(pub.PublishEvent)
+	runner.SetMaxInFlight(*maxInFlight)
+
+	for i, s := range stepPlan {
+		log.Printf("Step %d: %.0f events/s for %s", i+1, s.Rate, s.Duration)
+	}
+	results := runner.Run(ctx, stepPlan, gen.Generate(genCtx))
+	stopGen()
+
+	var sent int64
+	for _, res := range results {
+		sent += res.Sent
+	}
+	log.Printf("Sent %d events, waiting up to %s for them to be enriched", sent, *drain)
+	waitFor(ctx, recorder, int(sent), *drain)
+
+	report := loadtest.NewReport(results, recorder)
+	if err := writeReport(report, *out); err != nil {
+		log.Fatalf("Failed to write report: %v", err)
+	}
+}
+
+// waitFor returns once n events are recorded, after timeout or when ctx
+// is done.
+func waitFor(ctx context.Context, r *loadtest.Recorder, n int, timeout time.Duration) {
+	deadline := time.After(timeout)
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	for r.Count() < n {
+		select {
+		case <-tick.C:
+		case <-deadline:
+			return
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func writeReport(report loadtest.Report, path string) error {
+	var w io.Writer = os.Stdout
+	if path != "-" {
+		f, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+	if filepath.Ext(path) == ".json" {
+		return report.WriteJSON(w)
+	}
+	return report.WriteMarkdown(w)
+}
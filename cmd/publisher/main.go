@@ -2,26 +2,22 @@ package main
 
 import (
 	"context"
-	"encoding/json"
 	"log"
 	"os"
 	"os/signal"
 	"strconv"
 	"syscall"
 	"time"
-	"github.com/joho/godotenv"
-	"github.com/nats-io/nats.go"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/config"
 	"github.com/Bitstarz-eng/event-processing-challenge/internal/generator"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/publisher"
 )
 
 func main() {
-	if err := godotenv.Load(); err != nil {
-		log.Printf("Warning: Error loading .env file: %v", err)
-	}
-
-	natsURL := os.Getenv("NATS_URL")
-	if natsURL == "" {
-		natsURL = "nats://localhost:4222"
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
 	}
 
 	// Get delay from env
@@ -30,28 +26,48 @@ func main() {
 		delayMs = 0 // default to no delay
 	}
 
-	// Connect to NATS
-	nc, err := nats.Connect(natsURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to NATS: %v", err)
-	}
-	defer nc.Close()
-
 	// Handle graceful shutdown
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// Generate and publish events
-	log.Printf("Starting publisher with NATS URL: %s and delay: %dms", natsURL, delayMs)
-	events := generator.Generate(ctx)
-	for event := range events {
-		data, err := json.Marshal(event)
+	var gen generator.Generator = generator.GeneratorFunc(generator.Generate)
+	if cfg.GeneratorScenario != "" {
+		scenario, err := generator.LoadScenario(cfg.GeneratorScenario)
 		if err != nil {
-			log.Printf("Failed to marshal event: %v", err)
-			continue
+			log.Fatalf("Failed to load GENERATOR_SCENARIO: %v", err)
+		}
+		gen = generator.NewSimulator(scenario)
+		if scenario.Realtime {
+			// The simulated clock paces the events already.
+			delayMs = 0
 		}
+		log.Printf("Simulating %d players from scenario %s", scenario.Players.Count, cfg.GeneratorScenario)
+	}
+
+	// Connect to NATS
+	var pub *publisher.Service
+	if cfg.NATSJetStream {
+		pub, err = publisher.NewJetStream(ctx, cfg.NATSURL, gen)
+	} else {
+		pub, err = publisher.New(cfg.NATSURL, gen)
+	}
+	if err != nil {
+		log.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
 
-		if err := nc.Publish("casino.events", data); err != nil {
+	eventCodec, err := codec.ByName(cfg.EventCodec)
+	if err != nil {
+		log.Fatalf("Invalid EVENT_CODEC: %v", err)
+	}
+	pub.SetCodec(eventCodec)
+
+	// Generate and publish events
+	log.Printf("Starting publisher with NATS URL: %s, JetStream: %t, codec: %s and delay: %dms",
+		cfg.NATSURL, cfg.NATSJetStream, eventCodec.Name(), delayMs)
+	events := gen.Generate(ctx)
+	for event := range events {
+		if err := pub.PublishEvent(ctx, event); err != nil {
 			log.Printf("Failed to publish event: %v", err)
 			continue
 		}
@@ -61,4 +77,4 @@ func main() {
 			time.Sleep(time.Duration(delayMs) * time.Millisecond)
 		}
 	}
-} 
\ No newline at end of file
+}
@@ -1,39 +1,32 @@
 package main
 
 import (
+    "context"
     "database/sql"
     "flag"
     "fmt"
     "log"
-    "os"
     "strings"
     "time"
     _ "github.com/lib/pq"
-    "github.com/joho/godotenv"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/exchange"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/stack"
 )
 
 func main() {
-    refresh := flag.Bool("refresh", false, "Refresh rates from API")
+    refresh := flag.Bool("refresh", false, "Refresh rates from the provider chain")
+    from := flag.String("from", "", "Backfill rate history from this date (YYYY-MM-DD)")
+    to := flag.String("to", "", "Backfill rate history up to this date (YYYY-MM-DD, default today)")
     flag.Parse()
 
-    // Load .env file
-    if err := godotenv.Load(); err != nil {
-        log.Printf("Warning: Error loading .env file: %v", err)
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatalf("Failed to load config: %v", err)
     }
 
     // Connect to database
-    dbURL := fmt.Sprintf(
-        "host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
-        os.Getenv("DB_HOST"),
-        os.Getenv("DB_PORT"),
-        os.Getenv("DB_USER"),
-        os.Getenv("DB_PASSWORD"),
-        os.Getenv("DB_NAME"),
-        os.Getenv("DB_SSL_MODE"),
-    )
-
-    db, err := sql.Open("postgres", dbURL)
+    db, err := sql.Open("postgres", cfg.GetDBURL())
     if err != nil {
         log.Fatalf("Failed to connect to database: %v", err)
     }
@@ -44,6 +37,11 @@ func main() {
     if err != nil {
         log.Fatalf("Failed to create exchange service: %v", err)
     }
+    chain, err := exchange.NewChainFromConfig(stack.ExchangeProviders(cfg), db)
+    if err != nil {
+        log.Fatalf("Invalid EXCHANGE_RATE_PROVIDERS: %v", err)
+    }
+    svc.SetChain(chain)
 
     // Refresh rates if requested
     if *refresh {
@@ -52,6 +50,31 @@ func main() {
         }
     }
 
+    // Backfill history if requested
+    if *from != "" {
+        start, err := time.Parse(time.DateOnly, *from)
+        if err != nil {
+            log.Fatalf("Invalid -from date: %v", err)
+        }
+        end := time.Now().UTC()
+        if *to != "" {
+            if end, err = time.Parse(time.DateOnly, *to); err != nil {
+                log.Fatalf("Invalid -to date: %v", err)
+            }
+        }
+        if end.Before(start) {
+            log.Fatalf("-to %s is before -from %s", end.Format(time.DateOnly), *from)
+        }
+
+        ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+        defer cancel()
+        n, err := svc.Backfill(ctx, start, end)
+        if err != nil {
+            log.Fatalf("Failed to backfill rates: %v", err)
+        }
+        fmt.Printf("Stored %d historical rates from %s to %s\n", n, start.Format(time.DateOnly), end.Format(time.DateOnly))
+    }
+
     // Display current rates
     rows, err := db.Query(`
         SELECT currency, rate_to_eur, updated_at 
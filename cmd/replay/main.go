@@ -0,0 +1,204 @@
+package main
+
+import (
+    "bufio"
+    "context"
+    "database/sql"
+    "encoding/json"
+    "flag"
+    "fmt"
+    "io"
+    "log"
+    "os"
+    "os/signal"
+    "syscall"
+    "time"
+    _ "github.com/lib/pq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/stack"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/eventstore"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/publisher"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/replay"
+)
+
+const usage = `Usage: replay [flags]
+
+Reads events from a JSONL file (-file) or from the event store (-from/-to)
+and publishes them onto casino.events, or with -dry-run enriches them
+in-process and writes the enriched events as JSONL to -out.
+
+Flags:
+`
+
+// options are the parsed command-line flags.
+type options struct {
+    file     string
+    from     string
+    to       string
+    playerID int
+    speed    float64
+    dryRun   bool
+    out      string
+}
+
+func main() {
+    var opts options
+    flag.StringVar(&opts.file, "file", "", "Read events from this JSONL file instead of the event store")
+    flag.StringVar(&opts.from, "from", "", "Replay stored events created at or after this RFC 3339 time")
+    flag.StringVar(&opts.to, "to", "", "Replay stored events created before this RFC 3339 time")
+    flag.IntVar(&opts.playerID, "player", 0, "Only replay stored events of this player")
+    flag.Float64Var(&opts.speed, "speed", 1, "Pacing relative to the original gaps between events; 0 replays as fast as possible")
+    flag.BoolVar(&opts.dryRun, "dry-run", false, "Enrich in-process and write the output instead of publishing")
+    flag.StringVar(&opts.out, "out", "-", "Dry-run output file, - for stdout")
+    flag.Usage = func() {
+        fmt.Fprint(os.Stderr, usage)
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    if flag.NArg() > 0 || opts.speed < 0 || (opts.file == "" && opts.from == "" && opts.to == "") {
+        flag.Usage()
+        os.Exit(2)
+    }
+
+    if err := run(opts); err != nil {
+        log.Printf("Replay failed: %v", err)
+        os.Exit(1)
+    }
+}
+
+// run replays the events selected by opts. Everything it opens is closed
+// and the dry-run output flushed before it returns, error or not, so main
+// can exit without losing output.
+func run(opts options) (rerr error) {
+    cfg, err := config.Load()
+    if err != nil {
+        return fmt.Errorf("failed to load config: %w", err)
+    }
+
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    var src replay.Source
+    if opts.file != "" {
+        f, err := os.Open(opts.file)
+        if err != nil {
+            return fmt.Errorf("failed to open events file: %w", err)
+        }
+        defer f.Close()
+        src = replay.NewFileSource(f)
+    } else {
+        from, err := parseTime("from", opts.from)
+        if err != nil {
+            return err
+        }
+        to, err := parseTime("to", opts.to)
+        if err != nil {
+            return err
+        }
+        db, err := sql.Open("postgres", cfg.GetDBURL())
+        if err != nil {
+            return fmt.Errorf("failed to open database: %w", err)
+        }
+        defer db.Close()
+        src = replay.NewStoreSource(eventstore.New(db), eventstore.Filter{
+            PlayerID: opts.playerID,
+            From:     from,
+            To:       to,
+        })
+    }
+
+    var sink replay.Sink
+    if opts.dryRun {
+        // Dry runs enrich one event at a time, so player lookups have
+        // nothing to batch with.
+        cfg.SubscriberWorkers = 1
+        enrichers, err := stack.New(cfg)
+        if err != nil {
+            return fmt.Errorf("failed to build enrichers: %w", err)
+        }
+        defer enrichers.Close()
+        pipeline, err := enricher.NewPipeline(enrichers.Enrichers...)
+        if err != nil {
+            return fmt.Errorf("invalid enrichment pipeline: %w", err)
+        }
+
+        w := os.Stdout
+        if opts.out != "-" {
+            w, err = os.Create(opts.out)
+            if err != nil {
+                return fmt.Errorf("failed to create output file: %w", err)
+            }
+        }
+        buf := bufio.NewWriter(w)
+        defer func() {
+            if err := buf.Flush(); err != nil && rerr == nil {
+                rerr = fmt.Errorf("failed to write output: %w", err)
+            }
+            if err := w.Close(); err != nil && rerr == nil {
+                rerr = fmt.Errorf("failed to write output: %w", err)
+            }
+        }()
+        sink = enrich(pipeline, buf)
+    } else {
+        var pub *publisher.Service
+        if cfg.NATSJetStream {
+            pub, err = publisher.NewJetStream(ctx, cfg.NATSURL, nil)
+        } else {
+            pub, err = publisher.New(cfg.NATSURL, nil)
+        }
+        if err != nil {
+            return fmt.Errorf("failed to create publisher: %w", err)
+        }
+        defer pub.Close()
+
+        eventCodec, err := codec.ByName(cfg.EventCodec)
+        if err != nil {
+            return fmt.Errorf("invalid EVENT_CODEC: %w", err)
+        }
+        pub.SetCodec(eventCodec)
+        pub.SetSource("replay")
+        sink = pub.PublishEvent
+    }
+
+    start := time.Now()
+    stats, err := replay.New(opts.speed).Run(ctx, src, sink)
+    log.Printf("Replayed %d events (%d failed) in %s", stats.Events, stats.Failed, time.Since(start).Round(time.Millisecond))
+    if err != nil {
+        return fmt.Errorf("replay stopped: %w", err)
+    }
+    return nil
+}
+
+// enrich runs each event through the pipeline and writes it as a JSON line.
+// Events whose required stages fail are still written, as far as they got,
+// so that the output lines up with the input when diffed.
+func enrich(pipeline *enricher.Pipeline, w io.Writer) replay.Sink {
+    enc := json.NewEncoder(w)
+    return func(ctx context.Context, event casino.Event) error {
+        var failed error
+        if err := event.Validate(); err != nil {
+            failed = fmt.Errorf("invalid event: %w", err)
+        } else if _, err := pipeline.Run(ctx, &event); err != nil {
+            failed = err
+        }
+        if err := enc.Encode(event); err != nil {
+            return fmt.Errorf("failed to write event: %w", err)
+        }
+        return failed
+    }
+}
+
+func parseTime(name, s string) (time.Time, error) {
+    if s == "" {
+        return time.Time{}, nil
+    }
+    t, err := time.Parse(time.RFC3339Nano, s)
+    if err != nil {
+        return time.Time{}, fmt.Errorf("invalid -%s %q: want an RFC 3339 time", name, s)
+    }
+    return t, nil
+}
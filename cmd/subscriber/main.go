@@ -6,10 +6,16 @@ import (
     "os"
     "os/signal"
     "syscall"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dedup"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/eventstore"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/subscriber"
-    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/player"
-    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/description"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/watermark"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/game"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/stack"
 )
 
 func main() {
@@ -24,25 +30,121 @@ func main() {
     log.Printf("DB URL: %s", cfg.GetDBURL())
 
     // Create enrichers
-    playerEnricher, err := player.New(cfg.GetDBURL())
+    enrichers, err := stack.New(cfg)
     if err != nil {
-        log.Fatalf("Failed to create player enricher: %v", err)
+        log.Fatalf("Failed to create enrichers: %v", err)
     }
-    defer playerEnricher.Close()
-
-    descriptionEnricher := description.New()
+    defer enrichers.Close()
+    playerEnricher := enrichers.Player
+    games := enrichers.Games
 
     // Create and start subscriber
-    sub, err := subscriber.New(cfg.NATSURL, playerEnricher, descriptionEnricher)
+    sub, err := subscriber.New(cfg.NATSURL, enrichers.Enrichers...)
     if err != nil {
         log.Fatalf("Failed to create subscriber: %v", err)
     }
     defer sub.Close()
+    sub.SetDB(playerEnricher.DB())
+    sub.SetRateRefresher(enrichers.Exchange)
+    sub.SetWorkerPool(cfg.SubscriberWorkers, cfg.SubscriberQueueSize)
+    eventCodec, err := codec.ByName(cfg.EventCodec)
+    if err != nil {
+        log.Fatalf("Invalid EVENT_CODEC: %v", err)
+    }
+    sub.SetCodec(eventCodec)
+    if cfg.AdminToken == "" {
+        log.Printf("ADMIN_TOKEN is not set, POST and PUT /games are disabled")
+    }
+    gamesAPI := game.Handler(games, cfg.AdminToken)
+    sub.Handle("/games", gamesAPI)
+    sub.Handle("/games/", gamesAPI)
+
+    if cfg.EventStore {
+        storeWait, err := time.ParseDuration(cfg.EventStoreBatchWait)
+        if err != nil {
+            log.Fatalf("Invalid EVENT_STORE_BATCH_WAIT %q: %v", cfg.EventStoreBatchWait, err)
+        }
+        events := eventstore.New(playerEnricher.DB())
+        events.SetBatching(cfg.EventStoreBatchSize, storeWait)
+        defer events.Close()
+        sub.SetEventStore(events)
+        sub.Handle("/events", eventstore.Handler(events))
+    }
 
     // Handle graceful shutdown
     ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
     defer stop()
 
+    go games.Watch(ctx, cfg.GetDBURL(), enrichers.GameReload)
+
+    retention, err := time.ParseDuration(cfg.AggregateRetention)
+    if err != nil {
+        log.Fatalf("Invalid AGGREGATE_RETENTION %q: %v", cfg.AggregateRetention, err)
+    }
+    sub.SetAggregateRetention(retention)
+
+    if cfg.Sketches {
+        sketches := sketch.Config{
+            HLLPrecision: uint8(min(max(cfg.SketchHLLPrecision, 0), 255)),
+            CMSEpsilon:   cfg.SketchCMSEpsilon,
+            CMSDelta:     cfg.SketchCMSDelta,
+            TopK:         cfg.SketchTopK,
+            Compression:  cfg.SketchTDigestCompression,
+        }
+        if err := sketches.Validate(); err != nil {
+            log.Fatalf("Invalid SKETCH_* settings: %v", err)
+        }
+        sub.SetSketches(sketches)
+    }
+
+    lateness, err := time.ParseDuration(cfg.EventTimeLateness)
+    if err != nil {
+        log.Fatalf("Invalid EVENT_TIME_LATENESS %q: %v", cfg.EventTimeLateness, err)
+    }
+    latePolicy, err := watermark.ParsePolicy(cfg.EventTimeLatePolicy)
+    if err != nil {
+        log.Fatalf("Invalid EVENT_TIME_LATE_POLICY: %v", err)
+    }
+    sub.SetEventTime(lateness, latePolicy)
+
+    if cfg.DedupSize > 0 {
+        dedupWindow, err := time.ParseDuration(cfg.DedupWindow)
+        if err != nil {
+            log.Fatalf("Invalid DEDUP_WINDOW %q: %v", cfg.DedupWindow, err)
+        }
+        processed := dedup.New(cfg.DedupSize, dedupWindow)
+        if cfg.DedupPersist {
+            processed.SetStore(dedup.NewPostgresStore(playerEnricher.DB()))
+            if err := processed.Load(ctx); err != nil {
+                log.Printf("Failed to restore dedup window, starting empty: %v", err)
+            }
+            go processed.Run(ctx, time.Minute)
+        }
+        sub.SetDedup(processed)
+    } else {
+        sub.SetDedup(nil)
+    }
+
+    if cfg.PlayerReplica {
+        if err := playerEnricher.EnableReplica(ctx, cfg.GetDBURL()); err != nil {
+            log.Printf("Player replica disabled, using direct queries: %v", err)
+        }
+    }
+
+    if cfg.NATSJetStream {
+        ackWait, err := time.ParseDuration(cfg.NATSAckWait)
+        if err != nil {
+            log.Fatalf("Invalid NATS_ACK_WAIT %q: %v", cfg.NATSAckWait, err)
+        }
+        if err := sub.EnableJetStream(ctx, subscriber.JetStreamConfig{
+            Durable:    cfg.NATSConsumer,
+            AckWait:    ackWait,
+            MaxDeliver: cfg.NATSMaxDeliver,
+        }); err != nil {
+            log.Fatalf("Failed to enable JetStream: %v", err)
+        }
+    }
+
     log.Println("Starting subscriber...")
     if err := sub.Start(ctx); err != nil {
         log.Printf("Subscriber stopped with error: %v", err)
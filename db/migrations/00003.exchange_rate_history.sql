@@ -0,0 +1,14 @@
+-- One row per currency and the time a rate became effective, so events
+-- can be converted at the rate valid when they happened.
+CREATE TABLE IF NOT EXISTS exchange_rate_history (
+    currency TEXT NOT NULL,
+    rate_to_eur DECIMAL NOT NULL,
+    effective_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    provider TEXT NOT NULL DEFAULT 'seed',
+    PRIMARY KEY (currency, effective_at)
+);
+
+-- Seed rates apply to any event older than the first refresh
+INSERT INTO exchange_rate_history (currency, rate_to_eur, effective_at)
+SELECT currency, rate_to_eur, 'epoch'::timestamptz FROM exchange_rates
+ON CONFLICT (currency, effective_at) DO NOTHING;
@@ -0,0 +1,32 @@
+-- Publish every change to players on the players_changed channel so the
+-- player enricher can keep its in-memory replica current. The payload only
+-- names the row; listeners re-read it, so they always see committed data.
+CREATE OR REPLACE FUNCTION notify_player_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'TRUNCATE' THEN
+        PERFORM pg_notify('players_changed', json_build_object('op', TG_OP)::text);
+        RETURN NULL;
+    END IF;
+
+    PERFORM pg_notify('players_changed', json_build_object(
+        'op', TG_OP,
+        'id', CASE WHEN TG_OP = 'DELETE' THEN OLD.id ELSE NEW.id END
+    )::text);
+
+    -- An UPDATE that changes the primary key removes the old id
+    IF TG_OP = 'UPDATE' AND OLD.id <> NEW.id THEN
+        PERFORM pg_notify('players_changed', json_build_object('op', 'DELETE', 'id', OLD.id)::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS players_notify ON players;
+CREATE TRIGGER players_notify
+    AFTER INSERT OR UPDATE OR DELETE ON players
+    FOR EACH ROW EXECUTE FUNCTION notify_player_change();
+
+DROP TRIGGER IF EXISTS players_notify_truncate ON players;
+CREATE TRIGGER players_notify_truncate
+    AFTER TRUNCATE ON players
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_player_change();
@@ -0,0 +1,27 @@
+-- Profile fields exposed on enriched events. All are optional except the
+-- self-exclusion flag and account status, which have safe defaults.
+ALTER TABLE players
+    ADD COLUMN IF NOT EXISTS country text,             -- ISO 3166-1 alpha-2
+    ADD COLUMN IF NOT EXISTS preferred_currency text,  -- ISO 4217 or BTC
+    ADD COLUMN IF NOT EXISTS vip_tier text,            -- loyalty tier, e.g. bronze, silver, gold
+    ADD COLUMN IF NOT EXISTS registered_at timestamptz,
+    ADD COLUMN IF NOT EXISTS self_excluded boolean NOT NULL DEFAULT false,
+    ADD COLUMN IF NOT EXISTS account_status text NOT NULL DEFAULT 'active';
+
+ALTER TABLE players DROP CONSTRAINT IF EXISTS players_account_status_check;
+ALTER TABLE players ADD CONSTRAINT players_account_status_check
+    CHECK (account_status IN ('active', 'suspended', 'closed'));
+
+UPDATE players SET
+    country = v.country,
+    preferred_currency = v.currency,
+    vip_tier = v.tier,
+    registered_at = now() - v.age
+FROM (VALUES
+    (10, 'DE', 'EUR', 'gold',   interval '400d'),
+    (11, 'GB', 'GBP', 'silver', interval '200d'),
+    (12, 'US', 'USD', 'bronze', interval '30d'),
+    (13, 'NZ', 'NZD', NULL,     interval '7d'),
+    (14, 'MT', 'BTC', 'gold',   interval '900d')
+) AS v(id, country, currency, tier, age)
+WHERE players.id = v.id AND players.registered_at IS NULL;
@@ -0,0 +1,43 @@
+BEGIN;
+
+-- Game catalogue, replacing the hard-coded casino.Games map. RTP is the
+-- theoretical return to player in percent.
+CREATE TABLE IF NOT EXISTS games (
+    id integer PRIMARY KEY,
+    title text NOT NULL,
+    provider text,
+    category text,
+    rtp numeric(5,2) CHECK (rtp >= 0 AND rtp <= 100),
+    volatility text CHECK (volatility IN ('low', 'medium', 'high')),
+    active boolean NOT NULL DEFAULT true,
+    updated_at timestamptz NOT NULL DEFAULT now()
+);
+
+INSERT INTO games (id, title, provider, category, rtp, volatility) VALUES
+    (100, 'Rocket Dice', 'BGaming', 'dice', 99.00, 'low'),
+    (101, 'It''s bananas!', 'Thunderkick', 'slots', 96.10, 'medium'),
+    (102, 'Wild Spin', 'BGaming', 'slots', 96.00, 'medium'),
+    (103, 'Book of Dead', 'Play''n GO', 'slots', 96.21, 'high'),
+    (104, 'Pirate Jackpots', 'Quickspin', 'slots', 96.40, 'medium'),
+    (105, 'Western Gold 2', 'Spinomenal', 'slots', 96.03, 'medium'),
+    (106, 'Super Rainbow Megaways', 'Booming Games', 'slots', 96.07, 'high'),
+    (107, '#BarsAndBells', 'BGaming', 'slots', 97.00, 'low'),
+    (108, 'Fortune Three', 'Pragmatic Play', 'slots', 96.50, 'medium'),
+    (109, 'ChilliPop', 'Betsoft', 'slots', 96.45, 'high')
+ON CONFLICT (id) DO NOTHING;
+
+-- Tell game enrichers to reload their catalogue. The table is small, so
+-- the payload carries nothing and listeners read it whole.
+CREATE OR REPLACE FUNCTION notify_game_change() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('games_changed', TG_OP);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS games_notify ON games;
+CREATE TRIGGER games_notify
+    AFTER INSERT OR UPDATE OR DELETE OR TRUNCATE ON games
+    FOR EACH STATEMENT EXECUTE FUNCTION notify_game_change();
+
+COMMIT;
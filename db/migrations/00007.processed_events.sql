@@ -0,0 +1,13 @@
+BEGIN;
+
+-- Keys of recently processed events, so the subscriber's dedup window
+-- survives restarts when DEDUP_PERSIST is on. Rows older than the window
+-- are pruned by the subscriber.
+CREATE TABLE IF NOT EXISTS processed_events (
+    event_key text PRIMARY KEY,
+    processed_at timestamptz NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS processed_events_processed_at_idx ON processed_events (processed_at);
+
+COMMIT;
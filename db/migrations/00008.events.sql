@@ -0,0 +1,47 @@
+BEGIN;
+
+-- Enriched events, partitioned by the UTC day of created_at. The
+-- subscriber creates each day's partition with events_create_partition
+-- before writing to it. event holds the enriched event as published; the
+-- other columns are copies for filtering. event_key identifies an event
+-- across redeliveries: the envelope's event_id, or "id:<id>" for bare
+-- events. id alone is not unique, as publishers restart numbering at 1.
+CREATE TABLE IF NOT EXISTS events (
+    seq bigserial,
+    event_key text NOT NULL,
+    id bigint NOT NULL,
+    player_id integer NOT NULL,
+    game_id integer,
+    type text NOT NULL,
+    currency text,
+    amount bigint,
+    amount_eur bigint,
+    created_at timestamptz NOT NULL,
+    stored_at timestamptz NOT NULL DEFAULT now(),
+    event jsonb NOT NULL,
+    PRIMARY KEY (created_at, seq)
+) PARTITION BY RANGE (created_at);
+
+CREATE INDEX IF NOT EXISTS events_player_idx ON events (player_id, created_at, seq);
+CREATE INDEX IF NOT EXISTS events_game_idx ON events (game_id, created_at, seq);
+
+-- Creates the partition for one UTC day, e.g. events_20240224, unless it
+-- already exists. Its unique index on event_key makes (day, event_key)
+-- unique across the table, which a constraint on the partitioned table
+-- itself cannot express as it would have to include created_at; writers
+-- insert with ON CONFLICT DO NOTHING so a redelivered event is stored once.
+CREATE OR REPLACE FUNCTION events_create_partition(day date) RETURNS void AS $$
+DECLARE
+    partition text := 'events_' || to_char(day, 'YYYYMMDD');
+BEGIN
+    EXECUTE format(
+        'CREATE TABLE IF NOT EXISTS %I PARTITION OF events FOR VALUES FROM (%L) TO (%L)',
+        partition,
+        day::timestamp AT TIME ZONE 'UTC',
+        (day + 1)::timestamp AT TIME ZONE 'UTC'
+    );
+    EXECUTE format('CREATE UNIQUE INDEX IF NOT EXISTS %I ON %I (event_key)', partition || '_event_key', partition);
+END;
+$$ LANGUAGE plpgsql;
+
+COMMIT;
@@ -0,0 +1,64 @@
+package aggregator
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "time"
+)
+
+// Handler serves GET /aggregates. Without parameters it returns the
+// lifetime aggregates. With window it returns that window over event time:
+//
+//  window    minute, hour or day (tumbling) or a duration such as 5m (sliding)
+//  group_by  game or currency, optional
+//  at        RFC 3339 event time inside the window, the newest by default
+func Handler(s *Service) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        if q.Get("window") == "" {
+            if q.Get("group_by") != "" || q.Get("at") != "" {
+                writeError(w, http.StatusBadRequest, "group_by and at need a window")
+                return
+            }
+            agg := s.GetAggregates()
+            writeJSON(w, http.StatusOK, &agg)
+            return
+        }
+
+        spec, err := ParseWindow(q.Get("window"))
+        if err != nil {
+            writeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        var at time.Time
+        if v := q.Get("at"); v != "" {
+            if at, err = time.Parse(time.RFC3339Nano, v); err != nil {
+                writeError(w, http.StatusBadRequest, "invalid at: want an RFC 3339 time")
+                return
+            }
+        }
+
+        window, err := s.Query(spec, at, q.Get("group_by"))
+        switch {
+        case errors.Is(err, ErrInvalidGroupBy):
+            writeError(w, http.StatusBadRequest, err.Error())
+        case errors.Is(err, ErrNotRetained):
+            writeError(w, http.StatusNotFound, err.Error())
+        case err != nil:
+            writeError(w, http.StatusInternalServerError, err.Error())
+        default:
+            writeJSON(w, http.StatusOK, window)
+        }
+    })
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+    writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,46 @@
+package aggregator
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+func TestHandler(t *testing.T) {
+    s := New(DefaultRetention)
+    process(s, bet(10, 100, "EUR", 100, 0))
+    process(s, bet(11, 101, "USD", 200, time.Minute))
+
+    tests := []struct {
+        query  string
+        status int
+    }{
+        {"", http.StatusOK},
+        {"?window=5m&group_by=game", http.StatusOK},
+        {"?window=hour&at=2024-02-24T12:30:00Z", http.StatusOK},
+        {"?window=week", http.StatusBadRequest},
+        {"?window=5m&group_by=player", http.StatusBadRequest},
+        {"?window=5m&at=noon", http.StatusBadRequest},
+        {"?group_by=game", http.StatusBadRequest},
+        {"?window=day&at=2024-02-20T12:00:00Z", http.StatusNotFound},
+    }
+    for _, tt := range tests {
+        rec := httptest.NewRecorder()
+        Handler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/aggregates"+tt.query, nil))
+        if rec.Code != tt.status {
+            t.Errorf("GET /aggregates%s = %d, want %d: %s", tt.query, rec.Code, tt.status, rec.Body)
+        }
+    }
+
+    rec := httptest.NewRecorder()
+    Handler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/aggregates?window=5m&group_by=game", nil))
+    var w Window
+    if err := json.NewDecoder(rec.Body).Decode(&w); err != nil {
+        t.Fatal(err)
+    }
+    if w.Kind != Sliding || w.Totals.BetsEUR != 300 || w.Groups["101"].BetsEUR != 200 {
+        t.Errorf("window = %+v", w)
+    }
+}
@@ -1,23 +1,42 @@
 package aggregator
 
 import (
+    "maps"
     "sync"
     "time"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
 )
 
 type Aggregates struct {
     TotalBetsEUR     int64
     TotalDepositsEUR int64
     TotalWinsEUR     int64
-    UniqueUsers      map[int]bool
+    UniqueUsers      map[int]bool // nil with sketches
+    UniqueUsersCount int          // estimated with sketches
     ActiveGames      map[int]int // gameID -> active players
+    BetSizeEUR       *Quantiles  `json:",omitempty"` // with sketches only
     mu              sync.RWMutex
+
+    uniqueUsers *sketch.HyperLogLog
+    betSizes    *sketch.TDigest
 }
 
+// Service keeps lifetime aggregates, updated by Process as events are
+// processed, and the panes that windowed queries merge, updated by
+// ProcessWindowed in event-time order.
 type Service struct {
     aggregates *Aggregates
-    window     time.Duration
+
+    wmu       sync.RWMutex
+    panes     map[int64]*pane // by pane start in Unix seconds
+    latest    time.Time       // newest event time seen, up to the wall clock
+    retention time.Duration
+    now       func() time.Time
+
+    // sketches, if set, bound the memory for unique players and add bet
+    // size quantiles.
+    sketches *sketch.Config
 }
 
 type Aggregate struct {
@@ -27,31 +46,56 @@ type Aggregate struct {
     EndTime   time.Time `json:"end_time"`
 }
 
-func New(window time.Duration) *Service {
+// New returns an aggregator that keeps windowed aggregates for retention
+// behind the newest event time, DefaultRetention if zero.
+func New(retention time.Duration) *Service {
+    if retention <= 0 {
+        retention = DefaultRetention
+    }
     return &Service{
         aggregates: &Aggregates{
             UniqueUsers: make(map[int]bool),
             ActiveGames: make(map[int]int),
         },
-        window: window,
+        panes:     make(map[int64]*pane),
+        retention: retention,
+        now:       time.Now,
     }
 }
 
+// SetSketches counts unique players in HyperLogLogs instead of sets of
+// player IDs, lifetime and per pane and group, and estimates bet size
+// quantiles with t-digests. It must be called before the first event.
+func (s *Service) SetSketches(cfg sketch.Config) {
+    s.sketches = &cfg
+    s.aggregates.UniqueUsers = nil
+    s.aggregates.uniqueUsers = cfg.NewHyperLogLog()
+    s.aggregates.betSizes = cfg.NewTDigest()
+}
+
+// Process updates the lifetime aggregates.
 func (s *Service) Process(event casino.Event) {
     s.aggregates.mu.Lock()
     defer s.aggregates.mu.Unlock()
 
     // Track unique users
-    s.aggregates.UniqueUsers[event.PlayerID] = true
+    if s.aggregates.uniqueUsers != nil {
+        s.aggregates.uniqueUsers.Add(uint64(event.PlayerID))
+    } else {
+        s.aggregates.UniqueUsers[event.PlayerID] = true
+    }
 
     switch event.Type {
     case "bet":
-        s.aggregates.TotalBetsEUR = s.aggregates.TotalBetsEUR + int64(event.AmountEUR)
+        s.aggregates.TotalBetsEUR = s.aggregates.TotalBetsEUR + event.AmountEUR.Amount
+        if s.aggregates.betSizes != nil {
+            s.aggregates.betSizes.Add(float64(event.AmountEUR.Amount))
+        }
         if event.HasWon {
-            s.aggregates.TotalWinsEUR = s.aggregates.TotalWinsEUR + int64(event.AmountEUR)
+            s.aggregates.TotalWinsEUR = s.aggregates.TotalWinsEUR + event.AmountEUR.Amount
         }
     case "deposit":
-        s.aggregates.TotalDepositsEUR = s.aggregates.TotalDepositsEUR + int64(event.AmountEUR)
+        s.aggregates.TotalDepositsEUR = s.aggregates.TotalDepositsEUR + event.AmountEUR.Amount
     case "game_start":
         s.aggregates.ActiveGames[event.GameID]++
     case "game_stop":
@@ -65,12 +109,19 @@ func (s *Service) GetAggregates() Aggregates {
     s.aggregates.mu.RLock()
     defer s.aggregates.mu.RUnlock()
 
+    uniqueUsers := len(s.aggregates.UniqueUsers)
+    if s.aggregates.uniqueUsers != nil {
+        uniqueUsers = int(s.aggregates.uniqueUsers.Count())
+    }
+
     // Return a copy to avoid race conditions
     return Aggregates{
         TotalBetsEUR:     s.aggregates.TotalBetsEUR,
         TotalDepositsEUR: s.aggregates.TotalDepositsEUR,
         TotalWinsEUR:     s.aggregates.TotalWinsEUR,
-        UniqueUsers:      make(map[int]bool),
-        ActiveGames:      make(map[int]int),
+        UniqueUsers:      maps.Clone(s.aggregates.UniqueUsers),
+        UniqueUsersCount: uniqueUsers,
+        ActiveGames:      maps.Clone(s.aggregates.ActiveGames),
+        BetSizeEUR:       quantiles(s.aggregates.betSizes),
     }
 } 
\ No newline at end of file
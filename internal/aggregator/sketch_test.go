@@ -0,0 +1,105 @@
+package aggregator
+
+import (
+    "context"
+    "math"
+    "sort"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/generator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
+)
+
+// simulate returns n events of a seeded session simulation with many
+// players. Amounts stand in for their EUR value.
+func simulate(t *testing.T, n, players int) []casino.Event {
+    t.Helper()
+    sc := generator.DefaultScenario()
+    sc.Seed = 1
+    sc.Start = time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+    sc.Players = generator.Population{Count: players, FirstID: 1}
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    events := make([]casino.Event, 0, n)
+    for e := range generator.NewSimulator(sc).Generate(ctx) {
+        e.AmountEUR = money.EUR(int64(e.Amount))
+        events = append(events, e)
+        if len(events) == n {
+            break
+        }
+    }
+    return events
+}
+
+func TestSketchesAgainstExact(t *testing.T) {
+    events := simulate(t, 100000, 20000)
+    cfg := sketch.DefaultConfig()
+    exact, approx := New(DefaultRetention), New(DefaultRetention)
+    approx.SetSketches(cfg)
+    for _, e := range events {
+        process(exact, e)
+        process(approx, e)
+    }
+
+    // Three standard errors, and at least one player for small counts.
+    bound := func(n int) float64 {
+        return math.Max(3*sketch.NewHyperLogLog(cfg.HLLPrecision).RelativeError()*float64(n), 1)
+    }
+
+    want, got := exact.GetAggregates(), approx.GetAggregates()
+    if got.UniqueUsers != nil {
+        t.Error("UniqueUsers kept with sketches")
+    }
+    if diff := math.Abs(float64(got.UniqueUsersCount - want.UniqueUsersCount)); diff > bound(want.UniqueUsersCount) {
+        t.Errorf("UniqueUsersCount = %d, exact %d", got.UniqueUsersCount, want.UniqueUsersCount)
+    }
+    if got.TotalBetsEUR != want.TotalBetsEUR {
+        t.Errorf("TotalBetsEUR = %d, exact %d", got.TotalBetsEUR, want.TotalBetsEUR)
+    }
+
+    spec, _ := ParseWindow("15m")
+    wantWindow, _ := exact.Query(spec, time.Time{}, GroupByGame)
+    gotWindow, _ := approx.Query(spec, time.Time{}, GroupByGame)
+    if wantWindow.Totals.UniquePlayers < 1000 {
+        t.Fatalf("only %d players in the window, want a larger sample", wantWindow.Totals.UniquePlayers)
+    }
+    if diff := math.Abs(float64(gotWindow.Totals.UniquePlayers - wantWindow.Totals.UniquePlayers)); diff > bound(wantWindow.Totals.UniquePlayers) {
+        t.Errorf("window UniquePlayers = %d, exact %d", gotWindow.Totals.UniquePlayers, wantWindow.Totals.UniquePlayers)
+    }
+    for game, w := range wantWindow.Groups {
+        g := gotWindow.Groups[game]
+        if diff := math.Abs(float64(g.UniquePlayers - w.UniquePlayers)); diff > bound(w.UniquePlayers) {
+            t.Errorf("game %s UniquePlayers = %d, exact %d", game, g.UniquePlayers, w.UniquePlayers)
+        }
+    }
+    if wantWindow.Totals.BetSizeEUR != nil {
+        t.Error("BetSizeEUR set without sketches")
+    }
+
+    // Bet size quantiles within one percent of rank of the exact ones.
+    var bets []float64
+    for _, e := range events {
+        if e.Type == "bet" {
+            bets = append(bets, float64(e.AmountEUR.Amount))
+        }
+    }
+    sort.Float64s(bets)
+    q := got.BetSizeEUR
+    if q == nil {
+        t.Fatal("BetSizeEUR not set with sketches")
+    }
+    for _, c := range []struct {
+        q   float64
+        got float64
+    }{{0.5, q.P50}, {0.9, q.P90}, {0.99, q.P99}} {
+        lo := sort.SearchFloat64s(bets, c.got)
+        hi := sort.Search(len(bets), func(i int) bool { return bets[i] > c.got })
+        // Bet sizes repeat, so the estimate may cover a range of ranks.
+        if float64(lo)/float64(len(bets)) > c.q+0.01 || float64(hi)/float64(len(bets)) < c.q-0.01 {
+            t.Errorf("P%v = %v at ranks %d-%d of %d", c.q*100, c.got, lo, hi, len(bets))
+        }
+    }
+}
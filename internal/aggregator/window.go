@@ -0,0 +1,335 @@
+package aggregator
+
+import (
+    "errors"
+    "fmt"
+    "strconv"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
+)
+
+// Windowed aggregates are kept in panes of one minute of event time.
+// Tumbling and sliding windows are answered by merging the panes they
+// cover, so sliding windows advance a minute at a time.
+const Pane = time.Minute
+
+const DefaultRetention = 24 * time.Hour
+
+// Window kinds.
+const (
+    Tumbling = "tumbling"
+    Sliding  = "sliding"
+)
+
+// Grouping keys for Query.
+const (
+    GroupByGame     = "game"
+    GroupByCurrency = "currency"
+)
+
+var (
+    ErrInvalidWindow  = errors.New("invalid window")
+    ErrInvalidGroupBy = errors.New("invalid group_by: want game or currency")
+    ErrNotRetained    = errors.New("window is older than the retained panes")
+)
+
+// tumblingSizes are the named tumbling windows.
+var tumblingSizes = map[string]time.Duration{
+    "minute": time.Minute,
+    "hour":   time.Hour,
+    "day":    24 * time.Hour,
+}
+
+// WindowSpec selects a window: a tumbling minute, hour or day, or a
+// sliding window of a whole number of minutes.
+type WindowSpec struct {
+    Name string
+    Kind string
+    Size time.Duration
+}
+
+// ParseWindow parses "minute", "hour" or "day" as tumbling windows and
+// durations such as "5m" or "1h30m" as sliding windows.
+func ParseWindow(s string) (WindowSpec, error) {
+    if size, ok := tumblingSizes[s]; ok {
+        return WindowSpec{Name: s, Kind: Tumbling, Size: size}, nil
+    }
+    size, err := time.ParseDuration(s)
+    if err != nil {
+        return WindowSpec{}, fmt.Errorf("%w %q: want minute, hour, day or a duration such as 5m", ErrInvalidWindow, s)
+    }
+    if size < Pane || size%Pane != 0 {
+        return WindowSpec{}, fmt.Errorf("%w %q: sliding windows are whole minutes", ErrInvalidWindow, s)
+    }
+    return WindowSpec{Name: s, Kind: Sliding, Size: size}, nil
+}
+
+// Bounds returns the window at the given event time: the tumbling window
+// containing at, or the sliding window ending with the minute of at. Start
+// is inclusive and end exclusive.
+func (spec WindowSpec) Bounds(at time.Time) (start, end time.Time) {
+    at = at.UTC()
+    if spec.Kind == Tumbling {
+        start = at.Truncate(spec.Size)
+        return start, start.Add(spec.Size)
+    }
+    end = at.Truncate(Pane).Add(Pane)
+    return end.Add(-spec.Size), end
+}
+
+// Totals are the aggregates of one window or group. Amounts are EUR cents.
+type Totals struct {
+    Events        int   `json:"events"`
+    Bets          int   `json:"bets"`
+    Wins          int   `json:"wins"`
+    Deposits      int   `json:"deposits"`
+    GameStarts    int   `json:"game_starts"`
+    BetsEUR       int64 `json:"bets_eur"`
+    WinsEUR       int64 `json:"wins_eur"`
+    DepositsEUR   int64 `json:"deposits_eur"`
+    UniquePlayers int   `json:"unique_players"`
+
+    // With sketches, the bet size quantiles of the window's totals.
+    BetSizeEUR *Quantiles `json:"bet_size_eur,omitempty"`
+}
+
+// Quantiles are estimated quantiles of amounts in EUR cents.
+type Quantiles struct {
+    P50 float64 `json:"p50"`
+    P90 float64 `json:"p90"`
+    P99 float64 `json:"p99"`
+}
+
+func quantiles(td *sketch.TDigest) *Quantiles {
+    if td == nil || td.Count() == 0 {
+        return nil
+    }
+    return &Quantiles{P50: td.Quantile(0.5), P90: td.Quantile(0.9), P99: td.Quantile(0.99)}
+}
+
+// Window is the result of a query.
+type Window struct {
+    Window  string            `json:"window"`
+    Kind    string            `json:"kind"`
+    Start   time.Time         `json:"start"`
+    End     time.Time         `json:"end"`
+    Totals  Totals            `json:"totals"`
+    GroupBy string            `json:"group_by,omitempty"`
+    Groups  map[string]Totals `json:"groups,omitempty"`
+}
+
+// counts accumulates Totals and the players behind them: exactly, or with
+// sketches in a HyperLogLog. Overall counts with sketches also keep a
+// t-digest of bet sizes.
+type counts struct {
+    Totals
+    players  map[int]struct{}
+    unique   *sketch.HyperLogLog
+    betSizes *sketch.TDigest
+}
+
+// newCounts returns exact counts if cfg is nil.
+func newCounts(cfg *sketch.Config) *counts {
+    if cfg == nil {
+        return &counts{players: make(map[int]struct{})}
+    }
+    return &counts{unique: cfg.NewHyperLogLog()}
+}
+
+func (c *counts) add(event casino.Event) {
+    c.Events++
+    if c.unique != nil {
+        c.unique.Add(uint64(event.PlayerID))
+    } else {
+        c.players[event.PlayerID] = struct{}{}
+    }
+    switch event.Type {
+    case "bet":
+        c.Bets++
+        c.BetsEUR += event.AmountEUR.Amount
+        if c.betSizes != nil {
+            c.betSizes.Add(float64(event.AmountEUR.Amount))
+        }
+        if event.HasWon {
+            c.Wins++
+            c.WinsEUR += event.AmountEUR.Amount
+        }
+    case "deposit":
+        c.Deposits++
+        c.DepositsEUR += event.AmountEUR.Amount
+    case "game_start":
+        c.GameStarts++
+    }
+}
+
+func (c *counts) merge(o *counts) {
+    c.Events += o.Events
+    c.Bets += o.Bets
+    c.Wins += o.Wins
+    c.Deposits += o.Deposits
+    c.GameStarts += o.GameStarts
+    c.BetsEUR += o.BetsEUR
+    c.WinsEUR += o.WinsEUR
+    c.DepositsEUR += o.DepositsEUR
+    if c.unique != nil {
+        c.unique.Merge(o.unique)
+    } else {
+        for id := range o.players {
+            c.players[id] = struct{}{}
+        }
+    }
+    if c.betSizes != nil && o.betSizes != nil {
+        c.betSizes.Merge(o.betSizes)
+    }
+}
+
+func (c *counts) totals() Totals {
+    t := c.Totals
+    if c.unique != nil {
+        t.UniquePlayers = int(c.unique.Count())
+    } else {
+        t.UniquePlayers = len(c.players)
+    }
+    t.BetSizeEUR = quantiles(c.betSizes)
+    return t
+}
+
+// pane holds the aggregates of one minute, overall and per group.
+type pane struct {
+    cfg        *sketch.Config
+    all        *counts
+    games      map[int]*counts
+    currencies map[string]*counts
+}
+
+func newPane(cfg *sketch.Config) *pane {
+    return &pane{cfg: cfg, all: newOverall(cfg), games: make(map[int]*counts), currencies: make(map[string]*counts)}
+}
+
+// newOverall returns counts with bet size quantiles if sketches are on.
+func newOverall(cfg *sketch.Config) *counts {
+    c := newCounts(cfg)
+    if cfg != nil {
+        c.betSizes = cfg.NewTDigest()
+    }
+    return c
+}
+
+func (p *pane) add(event casino.Event) {
+    p.all.add(event)
+    // Deposits have no game and game starts and stops no currency; they
+    // only count in the groups they belong to.
+    if event.GameID != 0 {
+        group(p.games, event.GameID, p.cfg).add(event)
+    }
+    if event.Currency != "" {
+        group(p.currencies, event.Currency, p.cfg).add(event)
+    }
+}
+
+func group[K comparable](groups map[K]*counts, key K, cfg *sketch.Config) *counts {
+    c, ok := groups[key]
+    if !ok {
+        c = newCounts(cfg)
+        groups[key] = c
+    }
+    return c
+}
+
+// ProcessWindowed adds event to the pane of its event time. Events should
+// come in created_at order, as released by a watermark.Buffer; late events
+// passed on by its update policy still update their pane if it is retained.
+// An event created after the wall clock counts in its pane but does not
+// move the windows forward, so a skewed clock cannot expire the others.
+func (s *Service) ProcessWindowed(event casino.Event) {
+    s.wmu.Lock()
+    defer s.wmu.Unlock()
+
+    at := event.CreatedAt
+    if !s.latest.IsZero() && at.Before(s.latest.Add(-s.retention).Truncate(Pane)) {
+        return
+    }
+    if at.After(s.latest) && !at.After(s.now()) {
+        s.latest = at
+        s.prune()
+    }
+
+    key := at.Truncate(Pane).Unix()
+    p, ok := s.panes[key]
+    if !ok {
+        p = newPane(s.sketches)
+        s.panes[key] = p
+        metrics.AggregatePanes.Set(float64(len(s.panes)))
+    }
+    p.add(event)
+}
+
+// prune drops panes that ended before the retention. The caller holds
+// s.wmu.
+func (s *Service) prune() {
+    oldest := s.latest.Add(-s.retention).Truncate(Pane).Unix()
+    for key := range s.panes {
+        if key < oldest {
+            delete(s.panes, key)
+        }
+    }
+    metrics.AggregatePanes.Set(float64(len(s.panes)))
+}
+
+// Query returns the window spec at the given event time: the tumbling
+// window containing at, or the sliding window ending with the minute of
+// at. A zero at means the newest event time seen. groupBy is empty,
+// GroupByGame or GroupByCurrency.
+func (s *Service) Query(spec WindowSpec, at time.Time, groupBy string) (Window, error) {
+    if groupBy != "" && groupBy != GroupByGame && groupBy != GroupByCurrency {
+        return Window{}, ErrInvalidGroupBy
+    }
+
+    s.wmu.RLock()
+    defer s.wmu.RUnlock()
+
+    if at.IsZero() {
+        at = s.latest
+        if at.IsZero() {
+            at = time.Now()
+        }
+    }
+    at = at.UTC()
+
+    w := Window{Window: spec.Name, Kind: spec.Kind, GroupBy: groupBy}
+    w.Start, w.End = spec.Bounds(at)
+    if !s.latest.IsZero() && w.Start.Before(s.latest.Add(-s.retention).Truncate(Pane)) {
+        return Window{}, ErrNotRetained
+    }
+
+    all := newOverall(s.sketches)
+    groups := make(map[string]*counts)
+    for key, p := range s.panes {
+        start := time.Unix(key, 0)
+        if start.Before(w.Start) || !start.Before(w.End) {
+            continue
+        }
+        all.merge(p.all)
+        switch groupBy {
+        case GroupByGame:
+            for id, c := range p.games {
+                group(groups, strconv.Itoa(id), s.sketches).merge(c)
+            }
+        case GroupByCurrency:
+            for currency, c := range p.currencies {
+                group(groups, currency, s.sketches).merge(c)
+            }
+        }
+    }
+
+    w.Totals = all.totals()
+    if groupBy != "" {
+        w.Groups = make(map[string]Totals, len(groups))
+        for key, c := range groups {
+            w.Groups[key] = c.totals()
+        }
+    }
+    return w, nil
+}
@@ -0,0 +1,186 @@
+package aggregator
+
+import (
+    "errors"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+var base = time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+
+func bet(player, game int, currency string, eur int64, at time.Duration) casino.Event {
+    return casino.Event{
+        ID: 1, PlayerID: player, GameID: game, Type: "bet",
+        Amount: int(eur), Currency: currency, AmountEUR: money.EUR(eur),
+        CreatedAt: base.Add(at),
+    }
+}
+
+// process feeds event to the lifetime and the windowed aggregates.
+func process(s *Service, event casino.Event) {
+    s.Process(event)
+    s.ProcessWindowed(event)
+}
+
+func TestParseWindow(t *testing.T) {
+    tests := []struct {
+        in   string
+        want WindowSpec
+    }{
+        {"minute", WindowSpec{"minute", Tumbling, time.Minute}},
+        {"hour", WindowSpec{"hour", Tumbling, time.Hour}},
+        {"day", WindowSpec{"day", Tumbling, 24 * time.Hour}},
+        {"5m", WindowSpec{"5m", Sliding, 5 * time.Minute}},
+        {"1h30m", WindowSpec{"1h30m", Sliding, 90 * time.Minute}},
+    }
+    for _, tt := range tests {
+        got, err := ParseWindow(tt.in)
+        if err != nil || got != tt.want {
+            t.Errorf("ParseWindow(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+        }
+    }
+
+    for _, bad := range []string{"week", "30s", "90s", "-5m", ""} {
+        if _, err := ParseWindow(bad); !errors.Is(err, ErrInvalidWindow) {
+            t.Errorf("ParseWindow(%q) error = %v, want ErrInvalidWindow", bad, err)
+        }
+    }
+}
+
+func TestQueryTumbling(t *testing.T) {
+    s := New(DefaultRetention)
+    process(s, bet(10, 100, "EUR", 100, 10*time.Second))
+    process(s, bet(11, 100, "USD", 200, 50*time.Second))
+    process(s, bet(10, 101, "EUR", 300, 70*time.Second))
+
+    minute, _ := ParseWindow("minute")
+    w, err := s.Query(minute, base.Add(30*time.Second), "")
+    if err != nil {
+        t.Fatalf("Query() error = %v", err)
+    }
+    if !w.Start.Equal(base) || !w.End.Equal(base.Add(time.Minute)) {
+        t.Errorf("window = %v to %v, want the minute from %v", w.Start, w.End, base)
+    }
+    if w.Totals.Bets != 2 || w.Totals.BetsEUR != 300 || w.Totals.UniquePlayers != 2 {
+        t.Errorf("totals = %+v, want 2 bets of 300 cents by 2 players", w.Totals)
+    }
+
+    hour, _ := ParseWindow("hour")
+    w, _ = s.Query(hour, time.Time{}, GroupByGame)
+    if w.Totals.Bets != 3 || w.Totals.BetsEUR != 600 {
+        t.Errorf("hour totals = %+v, want 3 bets of 600 cents", w.Totals)
+    }
+    if g := w.Groups["100"]; g.Bets != 2 || g.UniquePlayers != 2 {
+        t.Errorf("game 100 = %+v", g)
+    }
+    if g := w.Groups["101"]; g.Bets != 1 || g.BetsEUR != 300 {
+        t.Errorf("game 101 = %+v", g)
+    }
+}
+
+func TestQuerySliding(t *testing.T) {
+    s := New(DefaultRetention)
+    for i := 0; i < 10; i++ {
+        process(s, bet(10+i, 100, "EUR", 100, time.Duration(i)*time.Minute))
+    }
+    deposit := casino.Event{PlayerID: 30, Type: "deposit", Amount: 1000, Currency: "GBP", AmountEUR: money.EUR(1170), CreatedAt: base.Add(9 * time.Minute)}
+    process(s, deposit)
+
+    // The newest event is at 12:09, so 5m covers 12:05 to 12:10.
+    spec, _ := ParseWindow("5m")
+    w, err := s.Query(spec, time.Time{}, GroupByCurrency)
+    if err != nil {
+        t.Fatalf("Query() error = %v", err)
+    }
+    if !w.Start.Equal(base.Add(5*time.Minute)) || !w.End.Equal(base.Add(10*time.Minute)) {
+        t.Errorf("window = %v to %v", w.Start, w.End)
+    }
+    if w.Totals.Bets != 5 || w.Totals.Deposits != 1 || w.Totals.DepositsEUR != 1170 || w.Totals.UniquePlayers != 6 {
+        t.Errorf("totals = %+v", w.Totals)
+    }
+    if g := w.Groups["EUR"]; g.Bets != 5 || g.Deposits != 0 {
+        t.Errorf("EUR = %+v", g)
+    }
+    if g := w.Groups["GBP"]; g.Deposits != 1 || g.Bets != 0 {
+        t.Errorf("GBP = %+v", g)
+    }
+
+    // Earlier sliding windows end with the minute of at.
+    w, _ = s.Query(spec, base.Add(2*time.Minute+30*time.Second), "")
+    if w.Totals.Bets != 3 {
+        t.Errorf("bets up to 12:03 = %d, want 3", w.Totals.Bets)
+    }
+}
+
+func TestProcessWindowedLateUpdate(t *testing.T) {
+    s := New(time.Hour)
+    process(s, bet(10, 100, "EUR", 100, 10*time.Minute))
+    // Passed on late by the update policy: its pane is updated.
+    process(s, bet(11, 100, "EUR", 100, 5*time.Minute))
+    // Older than the retention: only the lifetime totals count it.
+    process(s, bet(12, 100, "EUR", 100, -2*time.Hour))
+
+    spec, _ := ParseWindow("minute")
+    w, _ := s.Query(spec, base.Add(5*time.Minute), "")
+    if w.Totals.Bets != 1 {
+        t.Errorf("bets in the late event's minute = %d, want 1", w.Totals.Bets)
+    }
+    spec, _ = ParseWindow("hour")
+    if w, _ = s.Query(spec, time.Time{}, ""); w.Totals.Bets != 2 {
+        t.Errorf("windowed bets = %d, want 2 without the expired one", w.Totals.Bets)
+    }
+    if got := s.GetAggregates().TotalBetsEUR; got != 300 {
+        t.Errorf("lifetime bets = %d, want 300", got)
+    }
+}
+
+func TestProcessWindowedSkewedEvent(t *testing.T) {
+    s := New(time.Hour)
+    s.now = func() time.Time { return base.Add(time.Hour) }
+    process(s, bet(10, 100, "EUR", 100, 10*time.Minute))
+    process(s, bet(11, 100, "EUR", 100, 365*24*time.Hour)) // clock a year ahead
+
+    if !s.latest.Equal(base.Add(10 * time.Minute)) {
+        t.Errorf("latest = %v, want it unmoved by the skewed event", s.latest)
+    }
+    // The earlier pane is still retained and events keep counting.
+    process(s, bet(12, 100, "EUR", 100, 20*time.Minute))
+    spec, _ := ParseWindow("hour")
+    if w, err := s.Query(spec, time.Time{}, ""); err != nil || w.Totals.Bets != 2 {
+        t.Errorf("bets in the hour = %d, %v, want 2", w.Totals.Bets, err)
+    }
+}
+
+func TestRetention(t *testing.T) {
+    s := New(time.Hour)
+    process(s, bet(10, 100, "EUR", 100, 0))
+    process(s, bet(11, 100, "EUR", 100, 2*time.Hour))
+
+    if len(s.panes) != 1 {
+        t.Errorf("%d panes held, want 1 after pruning", len(s.panes))
+    }
+    spec, _ := ParseWindow("minute")
+    if _, err := s.Query(spec, base, ""); !errors.Is(err, ErrNotRetained) {
+        t.Errorf("Query() of a pruned window error = %v, want ErrNotRetained", err)
+    }
+    if _, err := s.Query(spec, time.Time{}, "player"); !errors.Is(err, ErrInvalidGroupBy) {
+        t.Errorf("Query() error = %v, want ErrInvalidGroupBy", err)
+    }
+}
+
+func TestGetAggregatesCopiesMaps(t *testing.T) {
+    s := New(DefaultRetention)
+    s.Process(casino.Event{PlayerID: 10, GameID: 100, Type: "game_start", CreatedAt: base})
+    s.Process(casino.Event{PlayerID: 11, GameID: 100, Type: "game_start", CreatedAt: base})
+
+    agg := s.GetAggregates()
+    if len(agg.UniqueUsers) != 2 || agg.ActiveGames[100] != 2 {
+        t.Errorf("UniqueUsers = %v, ActiveGames = %v", agg.UniqueUsers, agg.ActiveGames)
+    }
+    agg.ActiveGames[100] = 0
+    if s.GetAggregates().ActiveGames[100] != 2 {
+        t.Error("GetAggregates() shares its maps with the service")
+    }
+}
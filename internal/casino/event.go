@@ -2,6 +2,8 @@ package casino
 
 import (
 	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
 )
 
 var EventTypes = []string{
@@ -33,7 +35,33 @@ type Event struct {
 
 	CreatedAt time.Time `json:"created_at"`
 
-	AmountEUR   float64   `json:"amount_eur"`
+	// Amount converted to EUR cents, encoded as a plain integer.
+	AmountEUR   money.Money `json:"amount_eur"`
 	Player      Player    `json:"player"`
+	Game        *Game     `json:"game,omitempty"`
 	Description string    `json:"description,omitempty"`
 }
+
+// Money returns Amount in its currency's minor unit.
+func (e Event) Money() money.Money {
+	return money.New(int64(e.Amount), e.Currency)
+}
+
+// GameTitle returns the title of the event's game from the enriched Game,
+// or the built-in catalogue if it was not enriched, or "" if unknown.
+func (e Event) GameTitle() string {
+	if e.Game != nil {
+		return e.Game.Title
+	}
+	return Games[e.GameID].Title
+}
+
+// Raw returns the event without its enrichment fields, as it was
+// originally published.
+func (e Event) Raw() Event {
+	e.AmountEUR = money.Money{}
+	e.Player = Player{}
+	e.Game = nil
+	e.Description = ""
+	return e
+}
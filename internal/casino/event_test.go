@@ -0,0 +1,23 @@
+package casino
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+func TestEventRaw(t *testing.T) {
+	raw := Event{ID: 1, PlayerID: 10, GameID: 100, Type: "bet", Amount: 500, Currency: "USD", HasWon: true, CreatedAt: time.Now()}
+
+	enriched := raw
+	enriched.AmountEUR = money.EUR(468)
+	enriched.Player = Player{Email: "john@example.com"}
+	enriched.Game = &Game{ID: 100, Title: "Rocket Dice"}
+	enriched.Description = "Player #10 placed a bet."
+
+	if got := enriched.Raw(); !reflect.DeepEqual(got, raw) {
+		t.Errorf("Raw() = %+v, want %+v", got, raw)
+	}
+}
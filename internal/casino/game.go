@@ -1,18 +1,26 @@
 package casino
 
+// Games is the built-in catalogue, used when the games table is not
+// available. The game enricher reads the catalogue from Postgres.
 var Games = map[int]Game{
-	100: {Title: "Rocket Dice"},
-	101: {Title: "It's bananas!"},
-	102: {Title: "Wild Spin"},
-	103: {Title: "Book of Dead"},
-	104: {Title: "Pirate Jackpots"},
-	105: {Title: "Western Gold 2"},
-	106: {Title: "Super Rainbow Megaways"},
-	107: {Title: "#BarsAndBells"},
-	108: {Title: "Fortune Three"},
-	109: {Title: "ChilliPop"},
+	100: {ID: 100, Title: "Rocket Dice", Active: true},
+	101: {ID: 101, Title: "It's bananas!", Active: true},
+	102: {ID: 102, Title: "Wild Spin", Active: true},
+	103: {ID: 103, Title: "Book of Dead", Active: true},
+	104: {ID: 104, Title: "Pirate Jackpots", Active: true},
+	105: {ID: 105, Title: "Western Gold 2", Active: true},
+	106: {ID: 106, Title: "Super Rainbow Megaways", Active: true},
+	107: {ID: 107, Title: "#BarsAndBells", Active: true},
+	108: {ID: 108, Title: "Fortune Three", Active: true},
+	109: {ID: 109, Title: "ChilliPop", Active: true},
 }
 
 type Game struct {
-	Title string
+	ID         int     `json:"id"`
+	Title      string  `json:"title"`
+	Provider   string  `json:"provider,omitempty"`
+	Category   string  `json:"category,omitempty"`
+	RTP        float64 `json:"rtp,omitempty"` // return to player, percent
+	Volatility string  `json:"volatility,omitempty"`
+	Active     bool    `json:"active"`
 }
@@ -5,6 +5,15 @@ import "time"
 type Player struct {
 	Email          string    `json:"email"`
 	LastSignedInAt time.Time `json:"last_signed_in_at"`
+
+	// Profile fields, omitted when unknown so existing consumers see the
+	// same payload as before.
+	Country           string     `json:"country,omitempty"`            // ISO 3166-1 alpha-2
+	PreferredCurrency string     `json:"preferred_currency,omitempty"` // ISO 4217 or BTC
+	VIPTier           string     `json:"vip_tier,omitempty"`
+	RegisteredAt      *time.Time `json:"registered_at,omitempty"`
+	SelfExcluded      bool       `json:"self_excluded,omitempty"`
+	AccountStatus     string     `json:"account_status,omitempty"` // active, suspended or closed
 }
 
 func (p Player) IsZero() bool {
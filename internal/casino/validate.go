@@ -0,0 +1,87 @@
+package casino
+
+import (
+	"fmt"
+	"slices"
+)
+
+// Validation failure reasons, used as metric labels.
+const (
+	ReasonInvalidID          = "invalid_id"
+	ReasonMissingPlayer      = "missing_player"
+	ReasonUnknownType        = "unknown_type"
+	ReasonMissingGame        = "missing_game"
+	ReasonUnexpectedGame     = "unexpected_game"
+	ReasonInvalidAmount      = "invalid_amount"
+	ReasonUnexpectedAmount   = "unexpected_amount"
+	ReasonMissingCurrency    = "missing_currency"
+	ReasonUnknownCurrency    = "unknown_currency"
+	ReasonUnexpectedCurrency = "unexpected_currency"
+	ReasonUnexpectedHasWon   = "unexpected_has_won"
+	ReasonMissingCreatedAt   = "missing_created_at"
+)
+
+// ValidationError describes why an event breaks the schema.
+type ValidationError struct {
+	Reason  string
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid event (%s): %s", e.Reason, e.Message)
+}
+
+func invalid(reason, format string, args ...interface{}) *ValidationError {
+	return &ValidationError{Reason: reason, Message: fmt.Sprintf(format, args...)}
+}
+
+// Validate checks the event against the rules documented on Event: a game
+// for every type but deposit, a positive amount in a known currency for
+// bets and deposits only, and has_won for bets only. It returns a
+// *ValidationError for the first rule broken.
+func (e Event) Validate() error {
+	if e.ID <= 0 {
+		return invalid(ReasonInvalidID, "id %d is not positive", e.ID)
+	}
+	if e.PlayerID <= 0 {
+		return invalid(ReasonMissingPlayer, "player_id %d is not positive", e.PlayerID)
+	}
+	if !slices.Contains(EventTypes, e.Type) {
+		return invalid(ReasonUnknownType, "type %q is not one of %v", e.Type, EventTypes)
+	}
+	if e.CreatedAt.IsZero() {
+		return invalid(ReasonMissingCreatedAt, "created_at is missing")
+	}
+
+	if e.Type == "deposit" {
+		if e.GameID != 0 {
+			return invalid(ReasonUnexpectedGame, "deposit has game_id %d", e.GameID)
+		}
+	} else if e.GameID <= 0 {
+		return invalid(ReasonMissingGame, "%s has no game_id", e.Type)
+	}
+
+	if e.Type == "bet" || e.Type == "deposit" {
+		if e.Amount <= 0 {
+			return invalid(ReasonInvalidAmount, "%s amount %d is not positive", e.Type, e.Amount)
+		}
+		if e.Currency == "" {
+			return invalid(ReasonMissingCurrency, "%s has no currency", e.Type)
+		}
+		if !slices.Contains(Currencies, e.Currency) {
+			return invalid(ReasonUnknownCurrency, "currency %q is not one of %v", e.Currency, Currencies)
+		}
+	} else {
+		if e.Amount != 0 {
+			return invalid(ReasonUnexpectedAmount, "%s has amount %d", e.Type, e.Amount)
+		}
+		if e.Currency != "" {
+			return invalid(ReasonUnexpectedCurrency, "%s has currency %q", e.Type, e.Currency)
+		}
+	}
+
+	if e.HasWon && e.Type != "bet" {
+		return invalid(ReasonUnexpectedHasWon, "%s has has_won set", e.Type)
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package casino
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	at := time.Date(2022, 1, 10, 12, 34, 56, 0, time.UTC)
+	valid := map[string]Event{
+		"game_start": {ID: 1, PlayerID: 10, GameID: 100, Type: "game_start", CreatedAt: at},
+		"game_stop":  {ID: 1, PlayerID: 10, GameID: 100, Type: "game_stop", CreatedAt: at},
+		"bet":        {ID: 1, PlayerID: 10, GameID: 100, Type: "bet", Amount: 500, Currency: "USD", HasWon: true, CreatedAt: at},
+		"deposit":    {ID: 1, PlayerID: 10, Type: "deposit", Amount: 100000, Currency: "BTC", CreatedAt: at},
+	}
+	for name, e := range valid {
+		if err := e.Validate(); err != nil {
+			t.Errorf("%s: Validate() = %v, want nil", name, err)
+		}
+	}
+
+	tests := []struct {
+		name   string
+		base   string
+		modify func(*Event)
+		reason string
+	}{
+		{"zero id", "bet", func(e *Event) { e.ID = 0 }, ReasonInvalidID},
+		{"no player", "bet", func(e *Event) { e.PlayerID = 0 }, ReasonMissingPlayer},
+		{"unknown type", "game_start", func(e *Event) { e.Type = "test" }, ReasonUnknownType},
+		{"no created_at", "bet", func(e *Event) { e.CreatedAt = time.Time{} }, ReasonMissingCreatedAt},
+		{"bet without game", "bet", func(e *Event) { e.GameID = 0 }, ReasonMissingGame},
+		{"deposit with game", "deposit", func(e *Event) { e.GameID = 100 }, ReasonUnexpectedGame},
+		{"negative amount", "bet", func(e *Event) { e.Amount = -5 }, ReasonInvalidAmount},
+		{"zero deposit", "deposit", func(e *Event) { e.Amount = 0 }, ReasonInvalidAmount},
+		{"bet without currency", "bet", func(e *Event) { e.Currency = "" }, ReasonMissingCurrency},
+		{"unknown currency", "deposit", func(e *Event) { e.Currency = "XYZ" }, ReasonUnknownCurrency},
+		{"game_start with amount", "game_start", func(e *Event) { e.Amount = 5 }, ReasonUnexpectedAmount},
+		{"game_stop with currency", "game_stop", func(e *Event) { e.Currency = "EUR" }, ReasonUnexpectedCurrency},
+		{"won deposit", "deposit", func(e *Event) { e.HasWon = true }, ReasonUnexpectedHasWon},
+	}
+	for _, tt := range tests {
+		e := valid[tt.base]
+		tt.modify(&e)
+
+		var verr *ValidationError
+		if err := e.Validate(); !errors.As(err, &verr) || verr.Reason != tt.reason {
+			t.Errorf("%s: Validate() = %v, want reason %s", tt.name, err, tt.reason)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+// Package codec encodes casino events for NATS. Messages name their codec
+// in the Content-Type header so JSON and protobuf producers can share a
+// subject; messages without the header are JSON, as published before
+// codecs existed.
+package codec
+
+import (
+	"fmt"
+	"mime"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+)
+
+// Header is the NATS message header naming the content type.
+const Header = "Content-Type"
+
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeProtobuf = "application/x-protobuf"
+)
+
+// Codec encodes raw events in their envelope and enriched events, which
+// are published bare.
+type Codec interface {
+	// Name is the codec's configuration name, "json" or "protobuf".
+	Name() string
+	ContentType() string
+
+	MarshalEnvelope(env envelope.Envelope) ([]byte, error)
+	// UnmarshalEnvelope follows envelope.Decode: malformed payloads return
+	// a decode error, unsupported versions and missing metadata a
+	// *casino.ValidationError. The event itself is not validated.
+	UnmarshalEnvelope(data []byte) (envelope.Envelope, error)
+
+	MarshalEvent(event casino.Event) ([]byte, error)
+	UnmarshalEvent(data []byte) (casino.Event, error)
+}
+
+var (
+	JSON     Codec = jsonCodec{}
+	Protobuf Codec = protobufCodec{}
+)
+
+// ByName returns the codec configured by name.
+func ByName(name string) (Codec, error) {
+	switch name {
+	case "json", "":
+		return JSON, nil
+	case "protobuf":
+		return Protobuf, nil
+	}
+	return nil, fmt.Errorf("unknown codec %q, want json or protobuf", name)
+}
+
+// ForContentType returns the codec for a Content-Type header value.
+// Parameters such as charset are ignored and an empty value means JSON.
+func ForContentType(contentType string) (Codec, error) {
+	if contentType == "" {
+		return JSON, nil
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return nil, fmt.Errorf("invalid content type %q: %w", contentType, err)
+	}
+	switch mediaType {
+	case ContentTypeJSON:
+		return JSON, nil
+	case ContentTypeProtobuf, "application/protobuf":
+		return Protobuf, nil
+	}
+	return nil, fmt.Errorf("unsupported content type %q", contentType)
+}
@@ -0,0 +1,215 @@
+package codec
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+var codecs = []Codec{JSON, Protobuf}
+
+func rawEnvelope() envelope.Envelope {
+	return envelope.Envelope{
+		SchemaVersion: envelope.Version,
+		EventID:       "0123456789abcdef0123456789abcdef",
+		Source:        "test",
+		ProducedAt:    time.Date(2022, 2, 2, 23, 45, 13, 0, time.UTC),
+		Event: casino.Event{
+			ID:        7,
+			PlayerID:  11,
+			GameID:    101,
+			Type:      "bet",
+			Amount:    500,
+			Currency:  "USD",
+			HasWon:    true,
+			CreatedAt: time.Date(2022, 2, 2, 23, 45, 12, 500, time.UTC),
+		},
+	}
+}
+
+func enrichedEvent() casino.Event {
+	registered := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	event := rawEnvelope().Event
+	event.AmountEUR = money.Money{Amount: 468}
+	event.Player = casino.Player{
+		Email:             "john@example.com",
+		LastSignedInAt:    time.Date(2022, 2, 1, 8, 0, 0, 0, time.UTC),
+		Country:           "DE",
+		PreferredCurrency: "EUR",
+		VIPTier:           "gold",
+		RegisteredAt:      &registered,
+		AccountStatus:     "active",
+	}
+	event.Game = &casino.Game{ID: 101, Title: "It's bananas!", Provider: "Push", Category: "slots", RTP: 96.5, Volatility: "high", Active: true}
+	event.Description = `Player #11 placed a bet.`
+	return event
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	for _, c := range codecs {
+		t.Run(c.Name(), func(t *testing.T) {
+			want := rawEnvelope()
+			data, err := c.MarshalEnvelope(want)
+			if err != nil {
+				t.Fatalf("MarshalEnvelope() error = %v", err)
+			}
+			got, err := c.UnmarshalEnvelope(data)
+			if err != nil {
+				t.Fatalf("UnmarshalEnvelope() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestEventRoundTrip(t *testing.T) {
+	for _, c := range codecs {
+		t.Run(c.Name(), func(t *testing.T) {
+			want := enrichedEvent()
+			data, err := c.MarshalEvent(want)
+			if err != nil {
+				t.Fatalf("MarshalEvent() error = %v", err)
+			}
+			got, err := c.UnmarshalEvent(data)
+			if err != nil {
+				t.Fatalf("UnmarshalEvent() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("round trip = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalEnvelopeChecksMetadata(t *testing.T) {
+	for _, c := range codecs {
+		t.Run(c.Name(), func(t *testing.T) {
+			env := rawEnvelope()
+			env.SchemaVersion = envelope.Version + 1
+			data, err := c.MarshalEnvelope(env)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var verr *casino.ValidationError
+			if _, err := c.UnmarshalEnvelope(data); !errors.As(err, &verr) || verr.Reason != envelope.ReasonUnsupportedVersion {
+				t.Errorf("UnmarshalEnvelope(version %d) error = %v, want %s", env.SchemaVersion, err, envelope.ReasonUnsupportedVersion)
+			}
+
+			env = rawEnvelope()
+			env.Source = ""
+			data, _ = c.MarshalEnvelope(env)
+			if _, err := c.UnmarshalEnvelope(data); !errors.As(err, &verr) || verr.Reason != envelope.ReasonMissingSource {
+				t.Errorf("UnmarshalEnvelope(no source) error = %v, want %s", err, envelope.ReasonMissingSource)
+			}
+		})
+	}
+}
+
+func TestUnmarshalGarbage(t *testing.T) {
+	for _, c := range codecs {
+		if _, err := c.UnmarshalEnvelope([]byte{0xff, 0xff, 0xff}); err == nil {
+			t.Errorf("%s: UnmarshalEnvelope(garbage) succeeded, want error", c.Name())
+		}
+	}
+}
+
+func TestForContentType(t *testing.T) {
+	tests := []struct {
+		contentType string
+		want        Codec
+	}{
+		{"", JSON},
+		{"application/json", JSON},
+		{"application/json; charset=utf-8", JSON},
+		{"application/x-protobuf", Protobuf},
+		{"application/protobuf", Protobuf},
+	}
+	for _, tt := range tests {
+		got, err := ForContentType(tt.contentType)
+		if err != nil || got != tt.want {
+			t.Errorf("ForContentType(%q) = %v, %v, want %s", tt.contentType, got, err, tt.want.Name())
+		}
+	}
+	if _, err := ForContentType("text/plain"); err == nil {
+		t.Error("ForContentType(text/plain) succeeded, want error")
+	}
+}
+
+func TestByName(t *testing.T) {
+	for _, c := range codecs {
+		if got, err := ByName(c.Name()); err != nil || got != c {
+			t.Errorf("ByName(%q) = %v, %v", c.Name(), got, err)
+		}
+	}
+	if _, err := ByName("xml"); err == nil {
+		t.Error("ByName(xml) succeeded, want error")
+	}
+}
+
+// Compare with: go test ./internal/codec -bench . -benchmem
+func BenchmarkMarshalEnvelope(b *testing.B) {
+	env := rawEnvelope()
+	for _, c := range codecs {
+		b.Run(c.Name(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := c.MarshalEnvelope(env); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalEnvelope(b *testing.B) {
+	for _, c := range codecs {
+		data, err := c.MarshalEnvelope(rawEnvelope())
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(c.Name(), func(b *testing.B) {
+			b.ReportMetric(float64(len(data)), "bytes/msg")
+			for i := 0; i < b.N; i++ {
+				if _, err := c.UnmarshalEnvelope(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkMarshalEnrichedEvent(b *testing.B) {
+	event := enrichedEvent()
+	for _, c := range codecs {
+		b.Run(c.Name(), func(b *testing.B) {
+			for i := 0; i < b.N; i++ {
+				if _, err := c.MarshalEvent(event); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshalEnrichedEvent(b *testing.B) {
+	for _, c := range codecs {
+		data, err := c.MarshalEvent(enrichedEvent())
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.Run(c.Name(), func(b *testing.B) {
+			b.ReportMetric(float64(len(data)), "bytes/msg")
+			for i := 0; i < b.N; i++ {
+				if _, err := c.UnmarshalEvent(data); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package codec
+
+import (
+	"encoding/json"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string        { return "json" }
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) MarshalEnvelope(env envelope.Envelope) ([]byte, error) {
+	return env.Marshal()
+}
+
+// UnmarshalEnvelope also accepts bare events, as envelope version 0.
+func (jsonCodec) UnmarshalEnvelope(data []byte) (envelope.Envelope, error) {
+	return envelope.Decode(data)
+}
+
+func (jsonCodec) MarshalEvent(event casino.Event) ([]byte, error) {
+	return json.Marshal(event)
+}
+
+func (jsonCodec) UnmarshalEvent(data []byte) (casino.Event, error) {
+	var event casino.Event
+	err := json.Unmarshal(data, &event)
+	return event, err
+}
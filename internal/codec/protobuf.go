@@ -0,0 +1,162 @@
+package codec
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casinopb"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+// protobufCodec encodes the casinopb messages generated from
+// proto/casino/v1/event.proto. Timestamps are decoded in UTC and unlike
+// JSON there is no bare form: raw events must be enveloped.
+type protobufCodec struct{}
+
+func (protobufCodec) Name() string        { return "protobuf" }
+func (protobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (protobufCodec) MarshalEnvelope(env envelope.Envelope) ([]byte, error) {
+	return proto.Marshal(&casinopb.Envelope{
+		SchemaVersion: int32(env.SchemaVersion),
+		EventId:       env.EventID,
+		Source:        env.Source,
+		ProducedAt:    timestamp(env.ProducedAt),
+		Event:         eventToProto(env.Event),
+	})
+}
+
+func (protobufCodec) UnmarshalEnvelope(data []byte) (envelope.Envelope, error) {
+	var pb casinopb.Envelope
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return envelope.Envelope{}, err
+	}
+	env := envelope.Envelope{
+		SchemaVersion: int(pb.SchemaVersion),
+		EventID:       pb.EventId,
+		Source:        pb.Source,
+		ProducedAt:    fromTimestamp(pb.ProducedAt),
+		Event:         eventFromProto(pb.Event),
+	}
+	if err := env.Check(); err != nil {
+		return envelope.Envelope{}, err
+	}
+	return env, nil
+}
+
+func (protobufCodec) MarshalEvent(event casino.Event) ([]byte, error) {
+	return proto.Marshal(eventToProto(event))
+}
+
+func (protobufCodec) UnmarshalEvent(data []byte) (casino.Event, error) {
+	var pb casinopb.Event
+	if err := proto.Unmarshal(data, &pb); err != nil {
+		return casino.Event{}, err
+	}
+	return eventFromProto(&pb), nil
+}
+
+func eventToProto(e casino.Event) *casinopb.Event {
+	pb := &casinopb.Event{
+		Id:          int64(e.ID),
+		PlayerId:    int64(e.PlayerID),
+		GameId:      int64(e.GameID),
+		Type:        e.Type,
+		Amount:      int64(e.Amount),
+		Currency:    e.Currency,
+		HasWon:      e.HasWon,
+		CreatedAt:   timestamp(e.CreatedAt),
+		AmountEur:   e.AmountEUR.Amount,
+		Description: e.Description,
+	}
+	if e.Player != (casino.Player{}) {
+		pb.Player = &casinopb.Player{
+			Email:             e.Player.Email,
+			LastSignedInAt:    timestamp(e.Player.LastSignedInAt),
+			Country:           e.Player.Country,
+			PreferredCurrency: e.Player.PreferredCurrency,
+			VipTier:           e.Player.VIPTier,
+			SelfExcluded:      e.Player.SelfExcluded,
+			AccountStatus:     e.Player.AccountStatus,
+		}
+		if e.Player.RegisteredAt != nil {
+			pb.Player.RegisteredAt = timestamppb.New(*e.Player.RegisteredAt)
+		}
+	}
+	if g := e.Game; g != nil {
+		pb.Game = &casinopb.Game{
+			Id:         int64(g.ID),
+			Title:      g.Title,
+			Provider:   g.Provider,
+			Category:   g.Category,
+			Rtp:        g.RTP,
+			Volatility: g.Volatility,
+			Active:     g.Active,
+		}
+	}
+	return pb
+}
+
+func eventFromProto(pb *casinopb.Event) casino.Event {
+	if pb == nil {
+		return casino.Event{}
+	}
+	e := casino.Event{
+		ID:          int(pb.Id),
+		PlayerID:    int(pb.PlayerId),
+		GameID:      int(pb.GameId),
+		Type:        pb.Type,
+		Amount:      int(pb.Amount),
+		Currency:    pb.Currency,
+		HasWon:      pb.HasWon,
+		CreatedAt:   fromTimestamp(pb.CreatedAt),
+		AmountEUR:   money.Money{Amount: pb.AmountEur}, // as decoded from JSON
+		Description: pb.Description,
+	}
+	if p := pb.Player; p != nil {
+		e.Player = casino.Player{
+			Email:             p.Email,
+			LastSignedInAt:    fromTimestamp(p.LastSignedInAt),
+			Country:           p.Country,
+			PreferredCurrency: p.PreferredCurrency,
+			VIPTier:           p.VipTier,
+			SelfExcluded:      p.SelfExcluded,
+			AccountStatus:     p.AccountStatus,
+		}
+		if p.RegisteredAt != nil {
+			t := p.RegisteredAt.AsTime()
+			e.Player.RegisteredAt = &t
+		}
+	}
+	if g := pb.Game; g != nil {
+		e.Game = &casino.Game{
+			ID:         int(g.Id),
+			Title:      g.Title,
+			Provider:   g.Provider,
+			Category:   g.Category,
+			RTP:        g.Rtp,
+			Volatility: g.Volatility,
+			Active:     g.Active,
+		}
+	}
+	return e
+}
+
+// timestamp leaves zero times unset rather than encoding year 1.
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
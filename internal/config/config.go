@@ -19,7 +19,71 @@ type Config struct {
 	NATSURL    string
 	EventDelayMS int
 
+	// Generator scenario file; empty for independent random events
+	GeneratorScenario string
+
+	// JetStream settings
+	NATSJetStream      bool
+	NATSConsumer       string
+	NATSAckWait        string
+	NATSMaxDeliver     int
+
+	// Wire format of published events: json or protobuf
+	EventCodec string
+
+	// Subscriber worker pool settings
+	SubscriberWorkers   int
+	SubscriberQueueSize int
+
+	// Duplicate event detection
+	DedupSize    int
+	DedupWindow  string
+	DedupPersist bool
+
+	// Windowed aggregates
+	AggregateRetention string
+
+	// Event-time watermark
+	EventTimeLateness   string
+	EventTimeLatePolicy string
+
+	// Probabilistic sketches instead of exact per-player state
+	Sketches                 bool
+	SketchHLLPrecision       int
+	SketchCMSEpsilon         float64
+	SketchCMSDelta           float64
+	SketchTopK               int
+	SketchTDigestCompression float64
+
+	// Event store
+	EventStore          bool
+	EventStoreBatchSize int
+	EventStoreBatchWait string
+
+	// Player lookup batching
+	PlayerBatchSize int
+	PlayerBatchWait string
+	PlayerReplica   bool
+
+	// Description templates
+	DescriptionLocale       string
+	DescriptionTemplatesDir string
+	DescriptionShowEUR      bool
+
+	// Game catalogue
+	GameReloadInterval string
+	AdminToken         string
+
 	// Exchange rate settings
+	CurrencyRateSource              string
+	CurrencyRounding                string
+	ExchangeRateProviders           string
+	ExchangeRateAPIURL              string
+	ExchangeRateAPIKey              string
+	ExchangeRateSourceCurrency      string
+	ExchangeRateECBURL              string
+	ExchangeRateECBHistoryURL       string
+	ExchangeRateFile                string
 	ExchangeRateMemoryCacheDuration string
 	ExchangeRateDBCacheDuration    string
 	ExchangeRateRefreshInterval    string
@@ -40,7 +104,71 @@ func Load() (*Config, error) {
 		NATSURL:    getEnv("NATS_URL", "nats://localhost:4222"),
 		EventDelayMS: getIntEnv("EVENT_DELAY_MS", 1000),
 
+		// Generator
+		GeneratorScenario: getEnv("GENERATOR_SCENARIO", ""),
+
+		// JetStream settings
+		NATSJetStream:  getBoolEnv("NATS_JETSTREAM", false),
+		NATSConsumer:   getEnv("NATS_CONSUMER", "casino-subscriber"),
+		NATSAckWait:    getEnv("NATS_ACK_WAIT", "30s"),
+		NATSMaxDeliver: getIntEnv("NATS_MAX_DELIVER", 5),
+
+		// Wire format
+		EventCodec: getEnv("EVENT_CODEC", "json"),
+
+		// Subscriber worker pool settings
+		SubscriberWorkers:   getIntEnv("SUBSCRIBER_WORKERS", 4),
+		SubscriberQueueSize: getIntEnv("SUBSCRIBER_QUEUE_SIZE", 100),
+
+		// Duplicate event detection
+		DedupSize:    getIntEnv("DEDUP_SIZE", 100000),
+		DedupWindow:  getEnv("DEDUP_WINDOW", "10m"),
+		DedupPersist: getBoolEnv("DEDUP_PERSIST", false),
+
+		// Windowed aggregates
+		AggregateRetention: getEnv("AGGREGATE_RETENTION", "24h"),
+
+		// Event-time watermark
+		EventTimeLateness:   getEnv("EVENT_TIME_LATENESS", "5s"),
+		EventTimeLatePolicy: getEnv("EVENT_TIME_LATE_POLICY", "drop"),
+
+		// Probabilistic sketches
+		Sketches:                 getBoolEnv("SKETCHES", false),
+		SketchHLLPrecision:       getIntEnv("SKETCH_HLL_PRECISION", 14),
+		SketchCMSEpsilon:         getFloatEnv("SKETCH_CMS_EPSILON", 0.001),
+		SketchCMSDelta:           getFloatEnv("SKETCH_CMS_DELTA", 0.01),
+		SketchTopK:               getIntEnv("SKETCH_TOP_K", 100),
+		SketchTDigestCompression: getFloatEnv("SKETCH_TDIGEST_COMPRESSION", 100),
+
+		// Event store
+		EventStore:          getBoolEnv("EVENT_STORE", false),
+		EventStoreBatchSize: getIntEnv("EVENT_STORE_BATCH_SIZE", 500),
+		EventStoreBatchWait: getEnv("EVENT_STORE_BATCH_WAIT", "20ms"),
+
+		// Player lookup batching
+		PlayerBatchSize: getIntEnv("PLAYER_BATCH_SIZE", 50),
+		PlayerBatchWait: getEnv("PLAYER_BATCH_WAIT", "5ms"),
+		PlayerReplica:   getBoolEnv("PLAYER_REPLICA", false),
+
+		// Description templates
+		DescriptionLocale:       getEnv("DESCRIPTION_LOCALE", "en"),
+		DescriptionTemplatesDir: getEnv("DESCRIPTION_TEMPLATES_DIR", ""),
+		DescriptionShowEUR:      getBoolEnv("DESCRIPTION_SHOW_EUR", true),
+
+		// Game catalogue
+		GameReloadInterval: getEnv("GAME_RELOAD_INTERVAL", "1m"),
+		AdminToken:         getEnv("ADMIN_TOKEN", ""),
+
 		// Exchange rate settings
+		CurrencyRateSource:              getEnv("CURRENCY_RATE_SOURCE", "api"),
+		CurrencyRounding:                getEnv("CURRENCY_ROUNDING", "half_even"),
+		ExchangeRateProviders:           getEnv("EXCHANGE_RATE_PROVIDERS", "api"),
+		ExchangeRateAPIURL:              getEnv("EXCHANGE_RATE_API_URL", ""),
+		ExchangeRateAPIKey:              getEnv("EXCHANGE_RATE_API_KEY", ""),
+		ExchangeRateSourceCurrency:      getEnv("EXCHANGE_RATE_SOURCE_CURRENCY", "EUR"),
+		ExchangeRateECBURL:              getEnv("EXCHANGE_RATE_ECB_URL", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"),
+		ExchangeRateECBHistoryURL:       getEnv("EXCHANGE_RATE_ECB_HISTORY_URL", "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"),
+		ExchangeRateFile:                getEnv("EXCHANGE_RATE_FILE", ""),
 		ExchangeRateMemoryCacheDuration: getEnv("EXCHANGE_RATE_MEMORY_CACHE_DURATION", "1m"),
 		ExchangeRateDBCacheDuration:    getEnv("EXCHANGE_RATE_DB_CACHE_DURATION", "24h"),
 		ExchangeRateRefreshInterval:    getEnv("EXCHANGE_RATE_REFRESH_INTERVAL", "1h"),
@@ -70,4 +198,18 @@ func getIntEnv(key string, defaultValue int) int {
 		return value
 	}
 	return defaultValue
-} 
\ No newline at end of file
+} 
+
+func getFloatEnv(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+func getBoolEnv(key string, defaultValue bool) bool {
+	if value, err := strconv.ParseBool(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,60 @@
+package dedup
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// PostgresStore keeps processed keys in the processed_events table
+// (migration 00007).
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Load(ctx context.Context, since time.Time) ([]Entry, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT event_key, processed_at
+		 FROM processed_events
+		 WHERE processed_at >= $1
+		 ORDER BY processed_at`,
+		since,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query processed events: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []Entry
+	for rows.Next() {
+		var e Entry
+		if err := rows.Scan(&e.Key, &e.At); err != nil {
+			return nil, fmt.Errorf("failed to scan processed event: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to query processed events: %w", err)
+	}
+	return entries, nil
+}
+
+func (s *PostgresStore) Save(ctx context.Context, e Entry) error {
+	_, err := s.db.ExecContext(ctx,
+		`INSERT INTO processed_events (event_key, processed_at)
+		 VALUES ($1, $2)
+		 ON CONFLICT (event_key) DO UPDATE SET processed_at = EXCLUDED.processed_at`,
+		e.Key, e.At,
+	)
+	return err
+}
+
+func (s *PostgresStore) Prune(ctx context.Context, before time.Time) error {
+	_, err := s.db.ExecContext(ctx, `DELETE FROM processed_events WHERE processed_at < $1`, before)
+	return err
+}
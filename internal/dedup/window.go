@@ -0,0 +1,174 @@
+// Package dedup remembers recently processed events so that redelivered
+// and republished copies are dropped instead of being counted twice.
+package dedup
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+const (
+	DefaultSize = 100000
+	DefaultTTL  = 10 * time.Minute
+)
+
+// Entry is a processed event key and when it was processed.
+type Entry struct {
+	Key string
+	At  time.Time
+}
+
+// Store persists processed keys so the window survives restarts.
+type Store interface {
+	// Load returns the entries processed after since, oldest first.
+	Load(ctx context.Context, since time.Time) ([]Entry, error)
+	Save(ctx context.Context, e Entry) error
+	// Prune deletes the entries processed before before.
+	Prune(ctx context.Context, before time.Time) error
+}
+
+// Window is a bounded set of recently processed keys. A key is forgotten
+// once it is older than the TTL or when more than size newer keys have
+// been added, whichever comes first.
+type Window struct {
+	size  int
+	ttl   time.Duration
+	store Store
+	now   func() time.Time
+
+	mu    sync.Mutex
+	keys  map[string]*list.Element
+	order *list.List // of Entry, oldest at the front
+}
+
+func New(size int, ttl time.Duration) *Window {
+	if size < 1 {
+		size = 1
+	}
+	return &Window{
+		size:  size,
+		ttl:   ttl,
+		now:   time.Now,
+		keys:  make(map[string]*list.Element),
+		order: list.New(),
+	}
+}
+
+// SetStore persists every added key to store. Call Load afterwards to
+// restore the keys processed before a restart.
+func (w *Window) SetStore(store Store) {
+	w.store = store
+}
+
+// Load fills the window from the store and deletes expired entries there.
+func (w *Window) Load(ctx context.Context) error {
+	if w.store == nil {
+		return nil
+	}
+	since := w.now().Add(-w.ttl)
+	if err := w.store.Prune(ctx, since); err != nil {
+		return fmt.Errorf("failed to prune processed events: %w", err)
+	}
+	entries, err := w.store.Load(ctx, since)
+	if err != nil {
+		return fmt.Errorf("failed to load processed events: %w", err)
+	}
+
+	w.mu.Lock()
+	for _, e := range entries {
+		w.add(e)
+	}
+	size := w.order.Len()
+	w.mu.Unlock()
+
+	metrics.DedupWindowSize.Set(float64(size))
+	return nil
+}
+
+// Seen reports whether key was added within the window.
+func (w *Window) Seen(key string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.expire()
+	_, ok := w.keys[key]
+	return ok
+}
+
+// Add records key as processed. It is kept in memory even if persisting
+// it fails, in which case only a restart can let a duplicate through.
+func (w *Window) Add(ctx context.Context, key string) error {
+	e := Entry{Key: key, At: w.now()}
+
+	w.mu.Lock()
+	w.expire()
+	w.add(e)
+	size := w.order.Len()
+	w.mu.Unlock()
+	metrics.DedupWindowSize.Set(float64(size))
+
+	if w.store == nil {
+		return nil
+	}
+	if err := w.store.Save(ctx, e); err != nil {
+		return fmt.Errorf("failed to persist processed event %s: %w", key, err)
+	}
+	return nil
+}
+
+// Len returns the number of keys in the window.
+func (w *Window) Len() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.expire()
+	return w.order.Len()
+}
+
+// Run prunes expired entries from the store every interval until ctx is
+// done. Without a store there is nothing to do.
+func (w *Window) Run(ctx context.Context, interval time.Duration) {
+	if w.store == nil {
+		return
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.store.Prune(ctx, w.now().Add(-w.ttl)); err != nil {
+				log.Printf("Failed to prune processed events: %v", err)
+			}
+		}
+	}
+}
+
+// add must be called with mu held.
+func (w *Window) add(e Entry) {
+	if el, ok := w.keys[e.Key]; ok {
+		w.order.Remove(el)
+	}
+	w.keys[e.Key] = w.order.PushBack(e)
+	for w.order.Len() > w.size {
+		w.remove(w.order.Front())
+	}
+}
+
+// expire drops keys older than the TTL. It must be called with mu held.
+func (w *Window) expire() {
+	cutoff := w.now().Add(-w.ttl)
+	for el := w.order.Front(); el != nil && el.Value.(Entry).At.Before(cutoff); el = w.order.Front() {
+		w.remove(el)
+	}
+}
+
+func (w *Window) remove(el *list.Element) {
+	delete(w.keys, el.Value.(Entry).Key)
+	w.order.Remove(el)
+}
@@ -0,0 +1,162 @@
+package dedup
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// memStore is an in-memory Store.
+type memStore struct {
+	entries map[string]time.Time
+	saveErr error
+}
+
+func newMemStore() *memStore {
+	return &memStore{entries: make(map[string]time.Time)}
+}
+
+func (m *memStore) Load(ctx context.Context, since time.Time) ([]Entry, error) {
+	var entries []Entry
+	for k, at := range m.entries {
+		if !at.Before(since) {
+			entries = append(entries, Entry{Key: k, At: at})
+		}
+	}
+	return entries, nil
+}
+
+func (m *memStore) Save(ctx context.Context, e Entry) error {
+	if m.saveErr != nil {
+		return m.saveErr
+	}
+	m.entries[e.Key] = e.At
+	return nil
+}
+
+func (m *memStore) Prune(ctx context.Context, before time.Time) error {
+	for k, at := range m.entries {
+		if at.Before(before) {
+			delete(m.entries, k)
+		}
+	}
+	return nil
+}
+
+// clock returns a controllable now function.
+func clock(w *Window) *time.Time {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	return &now
+}
+
+func TestWindowSeen(t *testing.T) {
+	w := New(10, time.Minute)
+	ctx := context.Background()
+
+	if w.Seen("a") {
+		t.Fatal("Seen(a) before Add = true")
+	}
+	if err := w.Add(ctx, "a"); err != nil {
+		t.Fatal(err)
+	}
+	if !w.Seen("a") {
+		t.Error("Seen(a) after Add = false")
+	}
+	if w.Seen("b") {
+		t.Error("Seen(b) = true, never added")
+	}
+}
+
+func TestWindowEvictsOldestBeyondSize(t *testing.T) {
+	w := New(3, time.Hour)
+	ctx := context.Background()
+	for i := 1; i <= 5; i++ {
+		w.Add(ctx, fmt.Sprint(i))
+	}
+
+	if got := w.Len(); got != 3 {
+		t.Errorf("Len() = %d, want 3", got)
+	}
+	for key, want := range map[string]bool{"1": false, "2": false, "3": true, "4": true, "5": true} {
+		if got := w.Seen(key); got != want {
+			t.Errorf("Seen(%s) = %t, want %t", key, got, want)
+		}
+	}
+}
+
+func TestWindowExpires(t *testing.T) {
+	w := New(10, time.Minute)
+	now := clock(w)
+	ctx := context.Background()
+
+	w.Add(ctx, "old")
+	*now = now.Add(40 * time.Second)
+	w.Add(ctx, "new")
+	*now = now.Add(30 * time.Second)
+
+	if w.Seen("old") {
+		t.Error("Seen(old) = true after the TTL")
+	}
+	if !w.Seen("new") {
+		t.Error("Seen(new) = false within the TTL")
+	}
+}
+
+func TestWindowReAddMovesToBack(t *testing.T) {
+	w := New(2, time.Hour)
+	ctx := context.Background()
+	w.Add(ctx, "a")
+	w.Add(ctx, "b")
+	w.Add(ctx, "a")
+	w.Add(ctx, "c")
+
+	if !w.Seen("a") || w.Seen("b") {
+		t.Errorf("Seen(a), Seen(b) = %t, %t, want true, false", w.Seen("a"), w.Seen("b"))
+	}
+}
+
+func TestWindowStore(t *testing.T) {
+	store := newMemStore()
+	ctx := context.Background()
+
+	w := New(10, time.Minute)
+	now := clock(w)
+	w.SetStore(store)
+	w.Add(ctx, "old")
+	*now = now.Add(2 * time.Minute)
+	w.Add(ctx, "recent")
+
+	// A restarted subscriber remembers what is still within the window.
+	restarted := New(10, time.Minute)
+	restarted.now = w.now
+	restarted.SetStore(store)
+	if err := restarted.Load(ctx); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if !restarted.Seen("recent") {
+		t.Error("Seen(recent) after Load = false")
+	}
+	if restarted.Seen("old") {
+		t.Error("Seen(old) after Load = true, want expired")
+	}
+	if _, ok := store.entries["old"]; ok {
+		t.Error("Load() did not prune the expired entry from the store")
+	}
+}
+
+func TestWindowStoreError(t *testing.T) {
+	store := newMemStore()
+	store.saveErr = errors.New("database down")
+
+	w := New(10, time.Minute)
+	w.SetStore(store)
+	if err := w.Add(context.Background(), "a"); err == nil {
+		t.Error("Add() with failing store succeeded, want error")
+	}
+	if !w.Seen("a") {
+		t.Error("Seen(a) = false, want the key kept in memory")
+	}
+}
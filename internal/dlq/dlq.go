@@ -0,0 +1,173 @@
+package dlq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+)
+
+const (
+	// Subject receiving events the subscriber gave up on.
+	Subject = "casino.events.dlq"
+
+	// StreamName is the JetStream stream storing dead letters until replayed.
+	StreamName = "CASINO_EVENTS_DLQ"
+)
+
+// DeadLetter wraps a raw event payload with the reason it could not be processed.
+type DeadLetter struct {
+	// Payload is the original message body, kept verbatim so malformed
+	// events can be inspected and replayed byte for byte.
+	Payload     []byte    `json:"payload"`
+	ContentType string    `json:"content_type,omitempty"` // header of the original message, restored on replay
+	Enricher    string    `json:"enricher"`
+	Error       string    `json:"error"`
+	Reason      string    `json:"reason,omitempty"` // validation failures only, e.g. unknown_currency
+	Attempts    int       `json:"attempts"`
+	FailedAt    time.Time `json:"failed_at"`
+}
+
+// Entry is a stored dead letter together with its stream sequence number,
+// which identifies it for inspect and replay.
+type Entry struct {
+	Seq uint64 `json:"seq"`
+	DeadLetter
+}
+
+func (d DeadLetter) Marshal() ([]byte, error) {
+	return json.Marshal(d)
+}
+
+// StreamConfig keeps dead letters for 30 days.
+func StreamConfig() jetstream.StreamConfig {
+	return jetstream.StreamConfig{
+		Name:     StreamName,
+		Subjects: []string{Subject},
+		Storage:  jetstream.FileStorage,
+		MaxAge:   30 * 24 * time.Hour,
+	}
+}
+
+// Ensure creates the dead-letter stream or updates it to the current config.
+func Ensure(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
+	s, err := js.CreateOrUpdateStream(ctx, StreamConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare stream %s: %w", StreamName, err)
+	}
+	return s, nil
+}
+
+// Store lists, inspects and replays stored dead letters.
+type Store struct {
+	nc     *nats.Conn
+	js     jetstream.JetStream
+	stream jetstream.Stream
+}
+
+func NewStore(ctx context.Context, nc *nats.Conn) (*Store, error) {
+	js, err := jetstream.New(nc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	s, err := Ensure(ctx, js)
+	if err != nil {
+		return nil, err
+	}
+	return &Store{nc: nc, js: js, stream: s}, nil
+}
+
+// List returns every dead letter still in the stream, oldest first.
+func (s *Store) List(ctx context.Context) ([]Entry, error) {
+	info, err := s.stream.Info(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stream info: %w", err)
+	}
+
+	var entries []Entry
+	if info.State.Msgs == 0 {
+		return entries, nil
+	}
+
+	for seq := info.State.FirstSeq; seq <= info.State.LastSeq; seq++ {
+		entry, err := s.Get(ctx, seq)
+		if errors.Is(err, jetstream.ErrMsgNotFound) {
+			continue // already replayed or deleted
+		}
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (s *Store) Get(ctx context.Context, seq uint64) (Entry, error) {
+	msg, err := s.stream.GetMsg(ctx, seq)
+	if err != nil {
+		return Entry{}, fmt.Errorf("failed to get dead letter %d: %w", seq, err)
+	}
+
+	entry := Entry{Seq: seq}
+	if err := json.Unmarshal(msg.Data, &entry.DeadLetter); err != nil {
+		return Entry{}, fmt.Errorf("failed to decode dead letter %d: %w", seq, err)
+	}
+	return entry, nil
+}
+
+// Replay publishes the original payload and content type back onto the
+// events subject and removes the dead letter once the publish has been
+// acknowledged.
+func (s *Store) Replay(ctx context.Context, seq uint64) error {
+	entry, err := s.Get(ctx, seq)
+	if err != nil {
+		return err
+	}
+
+	msg := nats.NewMsg(stream.EventsSubject)
+	msg.Data = entry.Payload
+	if entry.ContentType != "" {
+		msg.Header.Set(codec.Header, entry.ContentType)
+	}
+	if err := s.publish(ctx, msg); err != nil {
+		return fmt.Errorf("failed to replay dead letter %d: %w", seq, err)
+	}
+
+	if err := s.stream.DeleteMsg(ctx, seq); err != nil {
+		return fmt.Errorf("replayed dead letter %d but failed to delete it: %w", seq, err)
+	}
+	return nil
+}
+
+// publish waits for a JetStream ack, falling back to core NATS when no
+// stream captures the events subject (subscriber running without JetStream).
+func (s *Store) publish(ctx context.Context, msg *nats.Msg) error {
+	_, err := s.js.StreamNameBySubject(ctx, stream.EventsSubject)
+	if err == nil {
+		_, err = s.js.PublishMsg(ctx, msg)
+		return err
+	}
+	if !errors.Is(err, jetstream.ErrStreamNotFound) {
+		return err
+	}
+
+	if err := s.nc.PublishMsg(msg); err != nil {
+		return err
+	}
+	return s.nc.FlushWithContext(ctx)
+}
+
+func (s *Store) Delete(ctx context.Context, seq uint64) error {
+	if err := s.stream.DeleteMsg(ctx, seq); err != nil {
+		return fmt.Errorf("failed to delete dead letter %d: %w", seq, err)
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package currency
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MockRates are the rates served by NewMock.
+var MockRates = map[string]float64{
+	"USD": 0.91,
+	"GBP": 1.17,
+	"NZD": 0.56,
+	"BTC": 35000,
+}
+
+// MockSource is an in-memory RateSource whose rates can be changed
+// while running and which counts lookups. Rates set with SetRateFrom
+// apply from their effective time on; before that the base rate applies.
+type MockSource struct {
+	mu      sync.Mutex
+	rates   map[string]float64
+	history map[string][]mockRate
+	calls   int
+}
+
+type mockRate struct {
+	from time.Time
+	rate float64
+}
+
+func NewMockSource(rates map[string]float64) *MockSource {
+	m := &MockSource{
+		rates:   make(map[string]float64, len(rates)),
+		history: make(map[string][]mockRate),
+	}
+	for c, r := range rates {
+		m.rates[c] = r
+	}
+	return m
+}
+
+func (m *MockSource) Rate(ctx context.Context, currency string, at time.Time) (float64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+
+	var best *mockRate
+	for i, r := range m.history[currency] {
+		if !r.from.After(at) && (best == nil || r.from.After(best.from)) {
+			best = &m.history[currency][i]
+		}
+	}
+	if best != nil {
+		return best.rate, nil
+	}
+	return StaticSource(m.rates).Rate(ctx, currency, at)
+}
+
+// SetRateFrom makes rate effective for currency from the given time.
+func (m *MockSource) SetRateFrom(currency string, from time.Time, rate float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.history[currency] = append(m.history[currency], mockRate{from: from, rate: rate})
+}
+
+func (m *MockSource) SetRate(currency string, rate float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.rates[currency] = rate
+}
+
+func (m *MockSource) Calls() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// NewMock returns a currency enricher backed by MockRates, for tests
+// that must not depend on the API or the database.
+func NewMock() *Service {
+	return New(NewMockSource(MockRates))
+}
@@ -0,0 +1,79 @@
+package currency
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+// Timeout for a single rate lookup, which may fall through to the API.
+const enrichTimeout = 2 * time.Second
+
+// RateSource returns how many EUR one unit of currency was worth at a
+// given time. Sources without history return their current rate.
+type RateSource interface {
+	Rate(ctx context.Context, currency string, at time.Time) (float64, error)
+}
+
+// Service converts bet and deposit amounts to EUR. It is the only enricher
+// writing AmountEUR, so a missing rate does not affect other enrichers.
+type Service struct {
+	source   RateSource
+	rounding money.RoundingMode
+}
+
+func New(source RateSource) *Service {
+	return &Service{source: source, rounding: money.HalfEven}
+}
+
+// SetRounding changes how converted amounts are rounded to whole cents.
+func (s *Service) SetRounding(mode money.RoundingMode) {
+	s.rounding = mode
+}
+
+func (s *Service) Spec() enricher.Spec {
+	return enricher.Spec{
+		Name:     "currency",
+		Required: true, // aggregates are meaningless without EUR amounts
+		Timeout:  enrichTimeout,
+		Produces: []string{enricher.FieldAmountEUR},
+	}
+}
+
+func (s *Service) Enrich(ctx context.Context, event *casino.Event) error {
+	switch event.Currency {
+	case "":
+		// game_start and game_stop carry no amount
+		return nil
+	case "EUR":
+		event.AmountEUR = money.EUR(int64(event.Amount))
+		return nil
+	}
+
+	// Convert at the rate valid when the event happened, not when it is
+	// processed, so late and replayed events keep their original value.
+	r, err := s.source.Rate(ctx, event.Currency, event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to get rate: %w", err)
+	}
+	rate, err := money.RateFromFloat(r)
+	if err != nil {
+		return fmt.Errorf("invalid rate for %s: %w", event.Currency, err)
+	}
+
+	eur, err := money.Convert(event.Money(), "EUR", rate, s.rounding)
+	if err != nil {
+		return err
+	}
+	event.AmountEUR = eur
+
+	log.Printf("Converting %s to EUR: amount=%s, rate=%s",
+		event.Money(), eur, rate)
+
+	return nil
+}
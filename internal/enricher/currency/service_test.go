@@ -0,0 +1,121 @@
+package currency
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func TestCurrencyEnricher(t *testing.T) {
+	tests := []struct {
+		name    string
+		event   casino.Event
+		wantEUR int64
+		wantErr error
+	}{
+		{
+			name:    "EUR is passed through",
+			event:   casino.Event{Type: "deposit", Amount: 10000, Currency: "EUR"},
+			wantEUR: 10000,
+		},
+		{
+			name:    "USD is converted",
+			event:   casino.Event{Type: "bet", Amount: 1000, Currency: "USD"},
+			wantEUR: 910,
+		},
+		{
+			name:    "BTC satoshi to EUR cents",
+			event:   casino.Event{Type: "deposit", Amount: 100000, Currency: "BTC"}, // 0.001 BTC
+			wantEUR: 3500,
+		},
+		{
+			name:    "rounds half to even",
+			event:   casino.Event{Type: "bet", Amount: 150, Currency: "USD"}, // 1.365 EUR
+			wantEUR: 136,
+		},
+		{
+			name:    "no currency on game events",
+			event:   casino.Event{Type: "game_start", GameID: 100},
+			wantEUR: 0,
+		},
+		{
+			name:    "unknown currency",
+			event:   casino.Event{Type: "bet", Amount: 1000, Currency: "XYZ"},
+			wantErr: ErrUnknownCurrency,
+		},
+	}
+
+	svc := NewMock()
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := svc.Enrich(context.Background(), &tt.event)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Enrich() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			if tt.event.AmountEUR.Amount != tt.wantEUR {
+				t.Errorf("AmountEUR = %v, want %v", tt.event.AmountEUR, tt.wantEUR)
+			}
+		})
+	}
+}
+
+func TestCurrencyEnricherDoesNotTouchPlayer(t *testing.T) {
+	src := NewMockSource(nil)
+	svc := New(src)
+
+	event := casino.Event{Type: "bet", Amount: 500, Currency: "GBP", Player: casino.Player{Email: "john@example.com"}}
+	if err := svc.Enrich(context.Background(), &event); err == nil {
+		t.Fatal("Expected error for missing GBP rate")
+	}
+	if event.Player.Email != "john@example.com" {
+		t.Errorf("Player data changed by currency enricher: %+v", event.Player)
+	}
+
+	src.SetRate("GBP", 1.2)
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.AmountEUR.Amount != 600 {
+		t.Errorf("AmountEUR = %v, want 600", event.AmountEUR)
+	}
+	if src.Calls() != 2 {
+		t.Errorf("Rate lookups = %d, want 2", src.Calls())
+	}
+}
+
+func TestCurrencyEnricherUsesEventTime(t *testing.T) {
+	change := time.Date(2024, 1, 5, 16, 0, 0, 0, time.UTC)
+	src := NewMockSource(map[string]float64{"USD": 0.5})
+	src.SetRateFrom("USD", change, 0.91)
+	svc := New(src)
+
+	tests := []struct {
+		name    string
+		at      time.Time
+		wantEUR int64
+	}{
+		{name: "before the change", at: change.Add(-time.Minute), wantEUR: 500},
+		{name: "after the change", at: change.Add(time.Minute), wantEUR: 910},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event := casino.Event{Type: "bet", Amount: 1000, Currency: "USD", CreatedAt: tt.at}
+			if err := svc.Enrich(context.Background(), &event); err != nil {
+				t.Fatalf("Enrich() error = %v", err)
+			}
+			if event.AmountEUR.Amount != tt.wantEUR {
+				t.Errorf("AmountEUR = %v, want %d", event.AmountEUR, tt.wantEUR)
+			}
+		})
+	}
+}
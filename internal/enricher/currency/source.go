@@ -0,0 +1,86 @@
+package currency
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/exchange"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// ExchangeSource reads rates through the exchange service, which caches
+// current rates in memory and falls back to the database and then the
+// provider chain; older times are looked up in the rate history.
+type ExchangeSource struct {
+	svc *exchange.Service
+}
+
+func NewExchangeSource(svc *exchange.Service) *ExchangeSource {
+	return &ExchangeSource{svc: svc}
+}
+
+func (s *ExchangeSource) Rate(ctx context.Context, currency string, at time.Time) (float64, error) {
+	return s.svc.GetRateAt(ctx, currency, at)
+}
+
+// DBSource reads rates straight from the database: the latest rate in
+// exchange_rate_history effective at the given time, or the current
+// exchange_rates row when there is no history for it.
+type DBSource struct {
+	db *sql.DB
+}
+
+func NewDBSource(db *sql.DB) *DBSource {
+	return &DBSource{db: db}
+}
+
+func (s *DBSource) Rate(ctx context.Context, currency string, at time.Time) (float64, error) {
+	if at.IsZero() {
+		at = time.Now()
+	}
+
+	var rate float64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT rate_to_eur FROM (
+			SELECT rate_to_eur, 0 AS pref FROM exchange_rate_history
+			WHERE currency = $1 AND effective_at <= $2
+			ORDER BY effective_at DESC LIMIT 1
+		) h
+		UNION ALL
+		SELECT rate_to_eur, 1 FROM exchange_rates WHERE currency = $1
+		ORDER BY pref LIMIT 1`,
+		currency, at,
+	).Scan(&rate)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rate for %s: %w", currency, err)
+	}
+	return rate, nil
+}
+
+// StaticSource serves rates from a fixed table, for offline use and tests.
+// It has no history and returns the same rate for any time.
+type StaticSource map[string]float64
+
+func (s StaticSource) Rate(ctx context.Context, currency string, at time.Time) (float64, error) {
+	rate, ok := s[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	return rate, nil
+}
+
+// StaticRates mirrors the rates seeded by the exchange_rates migration.
+var StaticRates = StaticSource{
+	"EUR": 1.0,
+	"USD": 0.85,
+	"GBP": 1.15,
+	"NZD": 0.57,
+	"BTC": 35000,
+}
@@ -0,0 +1,92 @@
+package description
+
+import (
+    "fmt"
+    "strings"
+    "text/template"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+// format holds the locale conventions the templates cannot express
+// themselves: month names, day ordinals and the decimal separator.
+type format struct {
+    months  [12]string
+    decimal string
+    date    func(f format, t time.Time) string
+}
+
+var formats = map[string]format{
+    "en": {
+        months: [12]string{"January", "February", "March", "April", "May", "June",
+            "July", "August", "September", "October", "November", "December"},
+        decimal: ".",
+        date: func(f format, t time.Time) string {
+            // January 10th, 2022
+            return fmt.Sprintf("%s %s, %d", f.months[t.Month()-1], englishOrdinal(t.Day()), t.Year())
+        },
+    },
+    "de": {
+        months: [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni",
+            "Juli", "August", "September", "Oktober", "November", "Dezember"},
+        decimal: ",",
+        date: func(f format, t time.Time) string {
+            // 10. Januar 2022
+            return fmt.Sprintf("%d. %s %d", t.Day(), f.months[t.Month()-1], t.Year())
+        },
+    },
+    "es": {
+        months: [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio",
+            "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+        decimal: ",",
+        date: func(f format, t time.Time) string {
+            // 10 de enero de 2022
+            return fmt.Sprintf("%d de %s de %d", t.Day(), f.months[t.Month()-1], t.Year())
+        },
+    },
+}
+
+// formatFor returns the conventions for locale, falling back to English
+// for locales that only ship templates.
+func formatFor(locale string) format {
+    if f, ok := formats[locale]; ok {
+        return f
+    }
+    return formats["en"]
+}
+
+func englishOrdinal(day int) string {
+    suffix := "th"
+    switch {
+    case day%100 >= 11 && day%100 <= 13:
+    case day%10 == 1:
+        suffix = "st"
+    case day%10 == 2:
+        suffix = "nd"
+    case day%10 == 3:
+        suffix = "rd"
+    }
+    return fmt.Sprintf("%d%s", day, suffix)
+}
+
+// amount formats m in major units followed by its currency, always with
+// the currency's minor-unit decimals ("5.00 USD", "0.00100000 BTC",
+// "500 JPY"), so amounts in one currency line up.
+func (f format) amount(m money.Money) string {
+    return strings.Replace(m.Decimal(), ".", f.decimal, 1) + " " + m.Currency
+}
+
+// funcs returns the template functions for the locale. Times are always
+// rendered in UTC.
+func (f format) funcs() template.FuncMap {
+    return template.FuncMap{
+        "date": func(t time.Time) string {
+            return f.date(f, t.UTC())
+        },
+        "clock": func(t time.Time) string {
+            return t.UTC().Format("15:04") + " UTC"
+        },
+        "amount": f.amount,
+        "ordinal": englishOrdinal,
+    }
+}
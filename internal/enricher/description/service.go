@@ -2,53 +2,173 @@ package description
 
 import (
     "context"
+    "embed"
     "fmt"
+    "io/fs"
+    "os"
+    "path"
+    "sort"
+    "strings"
+    "text/template"
     "time"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
 )
 
+// DefaultLocale is used when no locale is configured.
+const DefaultLocale = "en"
+
+// The built-in templates, one <locale>.tmpl file per locale.
+//
+//go:embed templates/*.tmpl
+var builtin embed.FS
+
+// Service renders event descriptions from text/template templates. Each
+// locale file defines one template per event type, named after it; events
+// of types without a template get no description.
 type Service struct {
+    locale    string
+    templates map[string]*template.Template
+    showEUR   bool
+}
+
+// Data is what the templates are executed with.
+type Data struct {
+    PlayerID int
+    Email    string
+    Game     string
+    Type     string
+    HasWon   bool
+    At       time.Time
+
+    // Amount is the event amount in its own currency, EUR its equivalent in
+    // EUR. EUR is nil when the equivalent is disabled, unknown or the event
+    // already is in EUR.
+    Amount money.Money
+    EUR    *money.Money
 }
 
 func New() *Service {
-    return &Service{}
+    templates, err := parseDir(builtin, "templates")
+    if err != nil {
+        panic(fmt.Sprintf("description: built-in templates: %v", err))
+    }
+    return &Service{
+        locale:    DefaultLocale,
+        templates: templates,
+        showEUR:   true,
+    }
+}
+
+func (s *Service) Spec() enricher.Spec {
+    return enricher.Spec{
+        Name:     "description",
+        Timeout:  100 * time.Millisecond,
+        Uses:     []string{enricher.FieldAmountEUR, enricher.FieldPlayer, enricher.FieldGame},
+        Produces: []string{enricher.FieldDescription},
+    }
+}
+
+// SetLocale selects the locale descriptions are rendered in.
+func (s *Service) SetLocale(locale string) error {
+    if _, ok := s.templates[locale]; !ok {
+        return fmt.Errorf("no description templates for locale %q, have %s", locale, strings.Join(s.Locales(), ", "))
+    }
+    s.locale = locale
+    return nil
+}
+
+// SetShowEUR turns the EUR equivalent after non-EUR amounts on or off.
+func (s *Service) SetShowEUR(show bool) {
+    s.showEUR = show
+}
+
+// LoadTemplates reads <locale>.tmpl files from dir. They replace the
+// built-in templates of the same locale and may add new locales, which
+// are formatted with English conventions unless built in.
+func (s *Service) LoadTemplates(dir string) error {
+    templates, err := parseDir(os.DirFS(dir), ".")
+    if err != nil {
+        return fmt.Errorf("failed to load description templates from %s: %w", dir, err)
+    }
+    if len(templates) == 0 {
+        return fmt.Errorf("no *.tmpl files in %s", dir)
+    }
+    for locale, t := range templates {
+        s.templates[locale] = t
+    }
+    return nil
+}
+
+// Locales returns the locales with templates, sorted.
+func (s *Service) Locales() []string {
+    locales := make([]string, 0, len(s.templates))
+    for locale := range s.templates {
+        locales = append(locales, locale)
+    }
+    sort.Strings(locales)
+    return locales
 }
 
 func (s *Service) Enrich(ctx context.Context, event *casino.Event) error {
-    // Get game title
-    game, ok := casino.Games[event.GameID]
-    if !ok {
-        game = casino.Game{Title: fmt.Sprintf("Game %d", event.GameID)}
-    }
-
-    amount := float64(event.Amount) // Use actual amount for all currencies
-
-    switch event.Type {
-    case "bet":
-        if event.HasWon {
-            event.Description = fmt.Sprintf("Player %d won %s %.2f in %s", 
-                event.PlayerID, event.Currency, amount, game.Title)
-        } else {
-            event.Description = fmt.Sprintf("Player %d lost %s %.2f in %s",
-                event.PlayerID, event.Currency, amount, game.Title)
-        }
-    case "deposit":
-        event.Description = fmt.Sprintf("Player %d deposited %s %.2f at %s",
-            event.PlayerID, event.Currency, amount, event.CreatedAt.Format(time.RFC3339))
-    case "game_start":
-        event.Description = fmt.Sprintf("Player %d started playing %s", 
-            event.PlayerID, game.Title)
-    case "game_stop":
-        event.Description = fmt.Sprintf("Player %d stopped playing %s", 
-            event.PlayerID, game.Title)
+    t := s.templates[s.locale].Lookup(event.Type)
+    if t == nil {
+        return nil
     }
 
+    var b strings.Builder
+    if err := t.Execute(&b, s.data(event)); err != nil {
+        return fmt.Errorf("failed to render %s description: %w", event.Type, err)
+    }
+    event.Description = b.String()
     return nil
 }
 
-func (s *Service) getGameTitle(id int) string {
-    if title, ok := casino.Games[id]; ok {
-        return title.Title
+func (s *Service) data(event *casino.Event) Data {
+    d := Data{
+        PlayerID: event.PlayerID,
+        Email:    event.Player.Email,
+        Game:     gameTitle(event),
+        Type:     event.Type,
+        HasWon:   event.HasWon,
+        At:       event.CreatedAt,
+        Amount:   event.Money(),
+    }
+    if s.showEUR && event.Currency != "EUR" && !event.AmountEUR.IsZero() {
+        eur := money.EUR(event.AmountEUR.Amount)
+        d.EUR = &eur
     }
-    return fmt.Sprintf("Game %d", id)
-} 
\ No newline at end of file
+    return d
+}
+
+func gameTitle(event *casino.Event) string {
+    if title := event.GameTitle(); title != "" {
+        return title
+    }
+    return fmt.Sprintf("Game %d", event.GameID)
+}
+
+// parseDir parses every *.tmpl file in dir of fsys as the templates of the
+// locale named by the file.
+func parseDir(fsys fs.FS, dir string) (map[string]*template.Template, error) {
+    names, err := fs.Glob(fsys, path.Join(dir, "*.tmpl"))
+    if err != nil {
+        return nil, err
+    }
+
+    templates := make(map[string]*template.Template, len(names))
+    for _, name := range names {
+        locale := strings.TrimSuffix(path.Base(name), ".tmpl")
+        src, err := fs.ReadFile(fsys, name)
+        if err != nil {
+            return nil, err
+        }
+        t, err := template.New(locale).Funcs(formatFor(locale).funcs()).Parse(string(src))
+        if err != nil {
+            return nil, err
+        }
+        templates[locale] = t
+    }
+    return templates, nil
+}
@@ -2,99 +2,267 @@ package description
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
 	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
 )
 
-func TestDescriptionEnricher(t *testing.T) {
-	gameTitles := map[int]string{
-		100: "Book of Dead",
-		101: "Starburst",
-	}
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
 
-	tests := []struct {
-		name        string
-		event       casino.Event
-		wantDesc    string
-	}{
-		{
-			name: "game start with known game",
-			event: casino.Event{
-				Type:     "game_start",
-				PlayerID: 123,
-				GameID:   100,
-			},
-			wantDesc: "Player 123 started playing Book of Dead",
+// goldenEvents are rendered in every locale and compared with
+// testdata/<locale>.golden, one "name: description" line per event.
+var goldenEvents = []struct {
+	name  string
+	event casino.Event
+}{
+	{
+		name: "game_start",
+		event: casino.Event{
+			Type:      "game_start",
+			PlayerID:  10,
+			GameID:    100,
+			CreatedAt: time.Date(2022, 1, 10, 12, 34, 56, 789000000, time.UTC),
 		},
-		{
-			name: "game start with unknown game",
-			event: casino.Event{
-				Type:     "game_start",
-				PlayerID: 123,
-				GameID:   999,
-			},
-			wantDesc: "Player 123 started playing Game 999",
+	},
+	{
+		name: "game_start unknown game",
+		event: casino.Event{
+			Type:      "game_start",
+			PlayerID:  10,
+			GameID:    999,
+			CreatedAt: time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC),
 		},
-		{
-			name: "game stop",
-			event: casino.Event{
-				Type:     "game_stop",
-				PlayerID: 123,
-				GameID:   101,
-			},
-			wantDesc: "Player 123 stopped playing Starburst",
+	},
+	{
+		name: "game_stop",
+		event: casino.Event{
+			Type:      "game_stop",
+			PlayerID:  10,
+			GameID:    102,
+			CreatedAt: time.Date(2022, 3, 22, 8, 5, 0, 0, time.UTC),
 		},
-		{
-			name: "winning bet",
-			event: casino.Event{
-				Type:     "bet",
-				PlayerID: 123,
-				GameID:   100,
-				Amount:   1000, // 10.00 EUR
-				Currency: "EUR",
-				HasWon:   true,
-			},
-			wantDesc: "Player 123 won EUR 10.00 in Book of Dead",
+	},
+	{
+		name: "bet with email and EUR equivalent",
+		event: casino.Event{
+			Type:      "bet",
+			PlayerID:  11,
+			GameID:    101,
+			Amount:    500,
+			Currency:  "USD",
+			AmountEUR: money.EUR(468),
+			CreatedAt: time.Date(2022, 2, 2, 23, 45, 12, 0, time.UTC),
+			Player:    casino.Player{Email: "john@example.com"},
 		},
-		{
-			name: "losing bet",
-			event: casino.Event{
-				Type:     "bet",
-				PlayerID: 123,
-				GameID:   100,
-				Amount:   1000,
-				Currency: "EUR",
-				HasWon:   false,
-			},
-			wantDesc: "Player 123 lost EUR 10.00 in Book of Dead",
+	},
+	{
+		name: "winning bet",
+		event: casino.Event{
+			Type:      "bet",
+			PlayerID:  11,
+			GameID:    103,
+			Amount:    1050,
+			Currency:  "GBP",
+			HasWon:    true,
+			AmountEUR: money.EUR(1229),
+			CreatedAt: time.Date(2022, 12, 13, 18, 0, 0, 0, time.FixedZone("CET", 3600)),
 		},
-		{
-			name: "deposit",
-			event: casino.Event{
-				Type:      "deposit",
-				PlayerID:  123,
-				Amount:    10000, // 100.00 BTC
-				Currency:  "BTC",
-				CreatedAt: time.Date(2024, 2, 24, 10, 30, 0, 0, time.UTC),
-			},
-			wantDesc: "Player 123 deposited BTC 100.00 at 2024-02-24T10:30:00Z",
+	},
+	{
+		name: "deposit in EUR",
+		event: casino.Event{
+			Type:      "deposit",
+			PlayerID:  12,
+			Amount:    10000,
+			Currency:  "EUR",
+			AmountEUR: money.EUR(10000),
+			CreatedAt: time.Date(2022, 2, 3, 12, 12, 12, 0, time.UTC),
 		},
-	}
+	},
+	{
+		name: "deposit in BTC",
+		event: casino.Event{
+			Type:      "deposit",
+			PlayerID:  13,
+			Amount:    100000,
+			Currency:  "BTC",
+			AmountEUR: money.EUR(3500),
+			CreatedAt: time.Date(2022, 5, 21, 9, 30, 0, 0, time.UTC),
+		},
+	},
+}
 
-	svc := New(gameTitles)
+func TestDescriptionGolden(t *testing.T) {
+	svc := New()
+	for _, locale := range []string{"en", "de", "es"} {
+		t.Run(locale, func(t *testing.T) {
+			if err := svc.SetLocale(locale); err != nil {
+				t.Fatal(err)
+			}
 
-	for _, tt := range tests {
-		t.Run(tt.name, func(t *testing.T) {
-			err := svc.Enrich(context.Background(), &tt.event)
-			if err != nil {
-				t.Errorf("Enrich() error = %v", err)
-				return
+			var b strings.Builder
+			for _, g := range goldenEvents {
+				event := g.event
+				if err := svc.Enrich(context.Background(), &event); err != nil {
+					t.Fatalf("%s: Enrich() error = %v", g.name, err)
+				}
+				fmt.Fprintf(&b, "%s: %s\n", g.name, event.Description)
 			}
 
-			if tt.event.Description != tt.wantDesc {
-				t.Errorf("Description = %q, want %q", tt.event.Description, tt.wantDesc)
+			golden := filepath.Join("testdata", locale+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(b.String()), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("%v (run with -update to create it)", err)
+			}
+			if got := b.String(); got != string(want) {
+				t.Errorf("descriptions differ from %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
 			}
 		})
 	}
-} 
\ No newline at end of file
+}
+
+func TestDescriptionMatchesReadme(t *testing.T) {
+	svc := New()
+	tests := []struct {
+		event casino.Event
+		want  string
+	}{
+		{goldenEvents[0].event, `Player #10 started playing a game "Rocket Dice" on January 10th, 2022 at 12:34 UTC.`},
+		{goldenEvents[3].event, `Player #11 (john@example.com) placed a bet of 5.00 USD (4.68 EUR) on a game "It's bananas!" on February 2nd, 2022 at 23:45 UTC.`},
+		{goldenEvents[5].event, `Player #12 made a deposit of 100.00 EUR on February 3rd, 2022 at 12:12 UTC.`},
+	}
+	for _, tt := range tests {
+		if err := svc.Enrich(context.Background(), &tt.event); err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+		if tt.event.Description != tt.want {
+			t.Errorf("Description = %q, want %q", tt.event.Description, tt.want)
+		}
+	}
+}
+
+func TestDescriptionWithoutEUR(t *testing.T) {
+	svc := New()
+	svc.SetShowEUR(false)
+
+	event := goldenEvents[3].event
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if strings.Contains(event.Description, "EUR") {
+		t.Errorf("Description = %q, want no EUR equivalent", event.Description)
+	}
+}
+
+func TestLoadTemplates(t *testing.T) {
+	dir := t.TempDir()
+	custom := `{{define "deposit"}}#{{.PlayerID}} +{{amount .Amount}} ({{ordinal 2}}){{end}}`
+	if err := os.WriteFile(filepath.Join(dir, "en.tmpl"), []byte(custom), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "fr.tmpl"), []byte(`{{define "deposit"}}dépôt{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := New()
+	if err := svc.LoadTemplates(dir); err != nil {
+		t.Fatalf("LoadTemplates() error = %v", err)
+	}
+
+	event := goldenEvents[5].event
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if want := "#12 +100.00 EUR (2nd)"; event.Description != want {
+		t.Errorf("Description = %q, want %q", event.Description, want)
+	}
+
+	// en.tmpl replaced the built-in one, which had the other types.
+	event = goldenEvents[0].event
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.Description != "" {
+		t.Errorf("Description = %q, want none without a template", event.Description)
+	}
+
+	if err := svc.SetLocale("fr"); err != nil {
+		t.Errorf("SetLocale(fr) error = %v", err)
+	}
+	if err := svc.SetLocale("xx"); err == nil {
+		t.Error("SetLocale(xx) succeeded, want error")
+	}
+}
+
+func TestLoadTemplatesErrors(t *testing.T) {
+	svc := New()
+	if err := svc.LoadTemplates(t.TempDir()); err == nil {
+		t.Error("LoadTemplates(empty dir) succeeded, want error")
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "en.tmpl"), []byte(`{{define "bet"}}{{nosuchfunc}}{{end}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := svc.LoadTemplates(dir); err == nil {
+		t.Error("LoadTemplates(bad template) succeeded, want error")
+	}
+}
+
+func TestAmountFormatting(t *testing.T) {
+	tests := []struct {
+		locale string
+		m      money.Money
+		want   string
+	}{
+		{"en", money.New(500, "USD"), "5.00 USD"},
+		{"en", money.New(468, "EUR"), "4.68 EUR"},
+		{"en", money.New(460, "EUR"), "4.60 EUR"},
+		{"en", money.New(5, "EUR"), "0.05 EUR"},
+		{"en", money.New(100000, "BTC"), "0.00100000 BTC"},
+		{"en", money.New(1, "BTC"), "0.00000001 BTC"},
+		{"en", money.New(-250, "USD"), "-2.50 USD"},
+		{"en", money.New(500, "JPY"), "500 JPY"},
+		{"de", money.New(468, "EUR"), "4,68 EUR"},
+		{"de", money.New(3500, "EUR"), "35,00 EUR"},
+		{"es", money.New(123456789, "BTC"), "1,23456789 BTC"},
+	}
+	for _, tt := range tests {
+		if got := formatFor(tt.locale).amount(tt.m); got != tt.want {
+			t.Errorf("%s amount(%v) = %q, want %q", tt.locale, tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestEnglishOrdinal(t *testing.T) {
+	want := map[int]string{1: "1st", 2: "2nd", 3: "3rd", 4: "4th", 11: "11th", 12: "12th", 13: "13th", 21: "21st", 22: "22nd", 23: "23rd", 31: "31st"}
+	for day, w := range want {
+		if got := englishOrdinal(day); got != w {
+			t.Errorf("englishOrdinal(%d) = %q, want %q", day, got, w)
+		}
+	}
+}
+
+func TestDescriptionUsesEnrichedGame(t *testing.T) {
+	event := goldenEvents[0].event
+	event.GameID = 200
+	event.Game = &casino.Game{ID: 200, Title: "Gates of Olympus"}
+	if err := New().Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if !strings.Contains(event.Description, `"Gates of Olympus"`) {
+		t.Errorf("Description = %q, want the enriched game title", event.Description)
+	}
+}
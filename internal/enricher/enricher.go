@@ -0,0 +1,82 @@
+package enricher
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Event fields produced by enrichers, used to declare stage dependencies.
+const (
+	FieldAmountEUR   = "amount_eur"
+	FieldPlayer      = "player"
+	FieldGame        = "game"
+	FieldDescription = "description"
+)
+
+type Enricher interface {
+	Enrich(context.Context, *casino.Event) error
+}
+
+// Spec declares how the pipeline runs an enricher.
+type Spec struct {
+	Name string
+
+	// Required stages abort the event on failure; the others are best-effort.
+	Required bool
+
+	// Timeout bounds a single Enrich call through its context. Zero means none.
+	Timeout time.Duration
+
+	// Needs lists fields that must be produced before this stage runs.
+	Needs []string
+
+	// Uses lists fields this stage reads when present. It runs after their
+	// producers, if any, whether or not they succeed.
+	Uses []string
+
+	// Produces lists the fields this stage writes. Stages running
+	// concurrently must only write the fields they declare here.
+	Produces []string
+}
+
+// Specifier is implemented by enrichers that declare their Spec.
+// Enrichers without one are required, have no timeout and run after
+// every enricher registered before them.
+type Specifier interface {
+	Spec() Spec
+}
+
+// Status is the outcome of one stage for one event.
+type Status string
+
+const (
+	StatusOK      Status = "ok"
+	StatusFailed  Status = "failed"
+	StatusTimeout Status = "timeout"
+	StatusSkipped Status = "skipped"
+)
+
+type Outcome struct {
+	Stage    string
+	Required bool
+	Status   Status
+	Err      error
+	Duration time.Duration
+}
+
+// StageError is returned by Pipeline.Run when a required stage does not succeed.
+type StageError struct {
+	Stage string
+	Err   error
+}
+
+func (e *StageError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Stage, e.Err)
+}
+
+func (e *StageError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,124 @@
+package exchange
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"time"
+)
+
+// APIProvider reads rates from an exchangerate.host style API, which
+// returns quotes keyed by source and target currency ("EURUSD") in units
+// of the target currency per unit of source.
+type APIProvider struct {
+	client         *http.Client
+	url            string
+	apiKey         string
+	sourceCurrency string
+}
+
+type APIResponse struct {
+	Success   bool               `json:"success"`
+	Source    string             `json:"source"`
+	Timestamp int64              `json:"timestamp"`
+	Quotes    map[string]float64 `json:"quotes"`
+}
+
+func NewAPIProvider(apiURL, apiKey, sourceCurrency string) *APIProvider {
+	if sourceCurrency == "" {
+		sourceCurrency = "EUR"
+	}
+	return &APIProvider{
+		client:         &http.Client{Timeout: 10 * time.Second},
+		url:            apiURL,
+		apiKey:         apiKey,
+		sourceCurrency: sourceCurrency,
+	}
+}
+
+func (p *APIProvider) Name() string { return "api" }
+
+func (p *APIProvider) Rates(ctx context.Context) (Rates, error) {
+	return p.fetch(ctx, p.url, nil)
+}
+
+// RatesBetween calls the historical endpoint, which sits next to the live
+// one (".../live" becomes ".../historical"), once per day.
+func (p *APIProvider) RatesBetween(ctx context.Context, from, to time.Time) ([]Rates, error) {
+	u, err := url.Parse(p.url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid API URL: %w", err)
+	}
+	u.Path = path.Join(path.Dir(u.Path), "historical")
+
+	var history []Rates
+	for day := truncateDay(from); !day.After(to); day = day.AddDate(0, 0, 1) {
+		rates, err := p.fetch(ctx, u.String(), url.Values{"date": {day.Format(time.DateOnly)}})
+		if err != nil {
+			return nil, fmt.Errorf("rates for %s: %w", day.Format(time.DateOnly), err)
+		}
+		if rates.AsOf.After(day.AddDate(0, 0, 1)) {
+			rates.AsOf = day // no timestamp in the response
+		}
+		history = append(history, rates)
+	}
+	return history, nil
+}
+
+func (p *APIProvider) fetch(ctx context.Context, endpoint string, params url.Values) (Rates, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return Rates{}, fmt.Errorf("invalid API URL: %w", err)
+	}
+	q := u.Query()
+	q.Set("access_key", p.apiKey)
+	q.Set("source", p.sourceCurrency)
+	for k, v := range params {
+		q[k] = v
+	}
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return Rates{}, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return Rates{}, fmt.Errorf("failed to get rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Rates{}, fmt.Errorf("API returned %s", resp.Status)
+	}
+
+	var apiResp APIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
+		return Rates{}, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if !apiResp.Success {
+		return Rates{}, fmt.Errorf("API request failed")
+	}
+
+	rates := Rates{Values: map[string]float64{p.sourceCurrency: 1}, AsOf: time.Now()}
+	if apiResp.Timestamp > 0 {
+		rates.AsOf = time.Unix(apiResp.Timestamp, 0)
+	}
+	for key, quote := range apiResp.Quotes {
+		if quote <= 0 {
+			continue
+		}
+		// Quotes are units of currency per EUR; store EUR per unit like rate_to_eur.
+		rates.Values[strings.TrimPrefix(key, p.sourceCurrency)] = 1 / quote
+	}
+	return rates, nil
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,46 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DBProvider reads the rates last stored in the exchange_rates table. The
+// snapshot is as old as its oldest row.
+type DBProvider struct {
+	db *sql.DB
+}
+
+func NewDBProvider(db *sql.DB) *DBProvider {
+	return &DBProvider{db: db}
+}
+
+func (p *DBProvider) Name() string { return "db" }
+
+func (p *DBProvider) Rates(ctx context.Context) (Rates, error) {
+	rows, err := p.db.QueryContext(ctx, `SELECT currency, rate_to_eur, updated_at FROM exchange_rates`)
+	if err != nil {
+		return Rates{}, fmt.Errorf("failed to query rates: %w", err)
+	}
+	defer rows.Close()
+
+	rates := Rates{Values: make(map[string]float64)}
+	for rows.Next() {
+		var currency string
+		var rate float64
+		var updatedAt time.Time
+		if err := rows.Scan(&currency, &rate, &updatedAt); err != nil {
+			return Rates{}, fmt.Errorf("failed to scan rate: %w", err)
+		}
+		rates.Values[currency] = rate
+		if rates.AsOf.IsZero() || updatedAt.Before(rates.AsOf) {
+			rates.AsOf = updatedAt
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return Rates{}, fmt.Errorf("failed to query rates: %w", err)
+	}
+	return rates, nil
+}
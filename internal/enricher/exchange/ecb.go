@@ -0,0 +1,127 @@
+package exchange
+
+import (
+	"context"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ECB euro reference rate feeds: the latest day, and every day since 1999.
+const (
+	ECBDailyURL   = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-daily.xml"
+	ECBHistoryURL = "https://www.ecb.europa.eu/stats/eurofxref/eurofxref-hist.xml"
+)
+
+// ECBProvider reads the ECB reference rate XML feeds. They quote units of
+// currency per EUR for about 30 fiat currencies, once per working day.
+type ECBProvider struct {
+	client     *http.Client
+	url        string
+	historyURL string
+}
+
+type ecbEnvelope struct {
+	Days []struct {
+		Time  string `xml:"time,attr"`
+		Rates []struct {
+			Currency string `xml:"currency,attr"`
+			Rate     string `xml:"rate,attr"`
+		} `xml:"Cube"`
+	} `xml:"Cube>Cube"`
+}
+
+// The ECB publishes reference rates at around 16:00 CET.
+var ecbPublishZone = time.FixedZone("CET", 60*60)
+
+func NewECBProvider(dailyURL, historyURL string) *ECBProvider {
+	if dailyURL == "" {
+		dailyURL = ECBDailyURL
+	}
+	if historyURL == "" {
+		historyURL = ECBHistoryURL
+	}
+	return &ECBProvider{
+		client:     &http.Client{Timeout: 10 * time.Second},
+		url:        dailyURL,
+		historyURL: historyURL,
+	}
+}
+
+func (p *ECBProvider) Name() string { return "ecb" }
+
+func (p *ECBProvider) Rates(ctx context.Context) (Rates, error) {
+	days, err := p.fetch(ctx, p.url)
+	if err != nil {
+		return Rates{}, err
+	}
+	// The daily feed has one day; history feeds list newest first.
+	return days[0], nil
+}
+
+// RatesBetween reads the history feed and keeps the days in range. Weekends
+// and TARGET holidays have no entry.
+func (p *ECBProvider) RatesBetween(ctx context.Context, from, to time.Time) ([]Rates, error) {
+	days, err := p.fetch(ctx, p.historyURL)
+	if err != nil {
+		return nil, err
+	}
+
+	// ISO dates compare correctly as strings.
+	first, last := from.Format(time.DateOnly), to.Format(time.DateOnly)
+
+	var history []Rates
+	for i := len(days) - 1; i >= 0; i-- {
+		day := days[i].AsOf.In(ecbPublishZone).Format(time.DateOnly)
+		if day < first || day > last {
+			continue
+		}
+		history = append(history, days[i])
+	}
+	return history, nil
+}
+
+func (p *ECBProvider) fetch(ctx context.Context, feedURL string) ([]Rates, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, feedURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get ECB rates: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("ECB feed returned %s", resp.Status)
+	}
+
+	var env ecbEnvelope
+	if err := xml.NewDecoder(resp.Body).Decode(&env); err != nil {
+		return nil, fmt.Errorf("failed to decode ECB feed: %w", err)
+	}
+	if len(env.Days) == 0 {
+		return nil, fmt.Errorf("ECB feed has no rates")
+	}
+
+	days := make([]Rates, 0, len(env.Days))
+	for _, day := range env.Days {
+		date, err := time.ParseInLocation(time.DateOnly, day.Time, ecbPublishZone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid ECB date %q: %w", day.Time, err)
+		}
+
+		rates := Rates{Values: map[string]float64{"EUR": 1}, AsOf: date.Add(16 * time.Hour)}
+		for _, r := range day.Rates {
+			quote, err := strconv.ParseFloat(r.Rate, 64)
+			if err != nil || quote <= 0 {
+				return nil, fmt.Errorf("invalid ECB rate %q for %s on %s", r.Rate, r.Currency, day.Time)
+			}
+			rates.Values[r.Currency] = 1 / quote
+		}
+		days = append(days, rates)
+	}
+	return days, nil
+}
@@ -0,0 +1,154 @@
+package exchange
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileProvider reads static rates in EUR per unit from a CSV or JSON file,
+// given as a local path or an http(s) URL.
+//
+// CSV files have "currency,rate_to_eur" rows with an optional header line.
+// JSON files look like {"as_of": "2024-01-05T16:00:00Z", "rates": {"USD": 0.91}}.
+// Without an as_of the file's modification time (or the Last-Modified
+// header) is used to judge staleness.
+type FileProvider struct {
+	client   *http.Client
+	location string
+}
+
+type rateFile struct {
+	AsOf  time.Time          `json:"as_of"`
+	Rates map[string]float64 `json:"rates"`
+}
+
+func NewFileProvider(location string) *FileProvider {
+	return &FileProvider{
+		client:   &http.Client{Timeout: 10 * time.Second},
+		location: location,
+	}
+}
+
+func (p *FileProvider) Name() string { return "file" }
+
+func (p *FileProvider) Rates(ctx context.Context) (Rates, error) {
+	if p.location == "" {
+		return Rates{}, fmt.Errorf("no rate file configured")
+	}
+
+	data, modified, err := p.read(ctx)
+	if err != nil {
+		return Rates{}, err
+	}
+
+	var rates Rates
+	if isJSON(p.location, data) {
+		rates, err = parseJSONRates(data)
+	} else {
+		rates, err = parseCSVRates(data)
+	}
+	if err != nil {
+		return Rates{}, fmt.Errorf("failed to parse %s: %w", p.location, err)
+	}
+	if rates.AsOf.IsZero() {
+		rates.AsOf = modified
+	}
+	return rates, nil
+}
+
+func (p *FileProvider) read(ctx context.Context) ([]byte, time.Time, error) {
+	if !strings.HasPrefix(p.location, "http://") && !strings.HasPrefix(p.location, "https://") {
+		info, err := os.Stat(p.location)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to read rate file: %w", err)
+		}
+		data, err := os.ReadFile(p.location)
+		if err != nil {
+			return nil, time.Time{}, fmt.Errorf("failed to read rate file: %w", err)
+		}
+		return data, info.ModTime(), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.location, nil)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to get rate file: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, time.Time{}, fmt.Errorf("rate file returned %s", resp.Status)
+	}
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, time.Time{}, fmt.Errorf("failed to read rate file: %w", err)
+	}
+
+	modified := time.Now()
+	if lm, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		modified = lm
+	}
+	return data, modified, nil
+}
+
+func isJSON(location string, data []byte) bool {
+	switch {
+	case strings.HasSuffix(location, ".json"):
+		return true
+	case strings.HasSuffix(location, ".csv"):
+		return false
+	}
+	return bytes.HasPrefix(bytes.TrimSpace(data), []byte("{"))
+}
+
+func parseJSONRates(data []byte) (Rates, error) {
+	var f rateFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return Rates{}, err
+	}
+	for currency, rate := range f.Rates {
+		if rate <= 0 {
+			return Rates{}, fmt.Errorf("invalid rate %v for %s", rate, currency)
+		}
+	}
+	return Rates{Values: f.Rates, AsOf: f.AsOf}, nil
+}
+
+func parseCSVRates(data []byte) (Rates, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return Rates{}, err
+	}
+
+	rates := Rates{Values: make(map[string]float64)}
+	for i, rec := range records {
+		rate, err := strconv.ParseFloat(rec[1], 64)
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return Rates{}, fmt.Errorf("line %d: invalid rate %q", i+1, rec[1])
+		}
+		if rate <= 0 {
+			return Rates{}, fmt.Errorf("line %d: invalid rate %q", i+1, rec[1])
+		}
+		rates.Values[strings.ToUpper(rec[0])] = rate
+	}
+	return rates, nil
+}
@@ -0,0 +1,175 @@
+package exchange
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rates is a snapshot of exchange rates from one provider, in EUR per unit
+// of each currency like the rate_to_eur column.
+type Rates struct {
+	Values map[string]float64
+	// AsOf is when the provider's data was published, not when it was
+	// fetched; a daily reference feed is a day old by the evening.
+	AsOf time.Time
+	// Provider names the provider that served the snapshot.
+	Provider string
+}
+
+// RateProvider fetches a full set of rates from one source.
+type RateProvider interface {
+	Name() string
+	Rates(ctx context.Context) (Rates, error)
+}
+
+// ChainEntry is a provider together with how old its data may be before
+// the chain moves on to the next provider. A zero MaxAge accepts any age.
+type ChainEntry struct {
+	Provider RateProvider
+	MaxAge   time.Duration
+}
+
+// Chain queries providers in order and returns the first fresh snapshot,
+// so one provider being down or stale does not stop conversion.
+type Chain struct {
+	entries []ChainEntry
+	now     func() time.Time
+}
+
+func NewChain(entries ...ChainEntry) *Chain {
+	return &Chain{entries: entries, now: time.Now}
+}
+
+// ParseChain builds a chain from a spec such as "api:1h,ecb:48h,db", using
+// providers to resolve names. A missing max age means no staleness limit.
+func ParseChain(spec string, providers map[string]RateProvider) (*Chain, error) {
+	var entries []ChainEntry
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, age, _ := strings.Cut(part, ":")
+		p, ok := providers[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown rate provider %q", name)
+		}
+
+		entry := ChainEntry{Provider: p}
+		if age != "" {
+			d, err := time.ParseDuration(age)
+			if err != nil {
+				return nil, fmt.Errorf("invalid max age for rate provider %s: %w", name, err)
+			}
+			entry.MaxAge = d
+		}
+		entries = append(entries, entry)
+	}
+
+	if len(entries) == 0 {
+		return nil, errors.New("no rate providers configured")
+	}
+	return NewChain(entries...), nil
+}
+
+// ProviderConfig holds the settings for every provider a chain spec can name.
+type ProviderConfig struct {
+	Spec           string // e.g. "api:1h,ecb:48h,db"
+	APIURL         string
+	APIKey         string
+	SourceCurrency string
+	ECBURL         string
+	ECBHistoryURL  string
+	File           string
+}
+
+// NewChainFromConfig builds the chain named by cfg.Spec.
+func NewChainFromConfig(cfg ProviderConfig, db *sql.DB) (*Chain, error) {
+	return ParseChain(cfg.Spec, map[string]RateProvider{
+		"api":  NewAPIProvider(cfg.APIURL, cfg.APIKey, cfg.SourceCurrency),
+		"ecb":  NewECBProvider(cfg.ECBURL, cfg.ECBHistoryURL),
+		"file": NewFileProvider(cfg.File),
+		"db":   NewDBProvider(db),
+	})
+}
+
+// Providers returns the provider names in chain order.
+func (c *Chain) Providers() []string {
+	names := make([]string, len(c.entries))
+	for i, e := range c.entries {
+		names[i] = e.Provider.Name()
+	}
+	return names
+}
+
+// Rates returns the first snapshot that is non-empty and within its
+// provider's max age. If none is, the error lists why each provider failed.
+func (c *Chain) Rates(ctx context.Context) (Rates, error) {
+	var errs []error
+	for _, e := range c.entries {
+		name := e.Provider.Name()
+
+		rates, err := e.Provider.Rates(ctx)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if len(rates.Values) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no rates returned", name))
+			continue
+		}
+		if age := c.now().Sub(rates.AsOf); e.MaxAge > 0 && age > e.MaxAge {
+			errs = append(errs, fmt.Errorf("%s: rates are %s old, max age is %s", name, age.Round(time.Second), e.MaxAge))
+			continue
+		}
+
+		rates.Provider = name
+		return rates, nil
+	}
+	return Rates{}, fmt.Errorf("all rate providers failed: %w", errors.Join(errs...))
+}
+
+// HistoricalProvider is a RateProvider that can also return past rates,
+// one snapshot per published day between from and to inclusive.
+type HistoricalProvider interface {
+	RateProvider
+	RatesBetween(ctx context.Context, from, to time.Time) ([]Rates, error)
+}
+
+// RatesBetween returns past rates from the first historical provider in
+// the chain that succeeds. Max ages do not apply to history.
+func (c *Chain) RatesBetween(ctx context.Context, from, to time.Time) ([]Rates, error) {
+	var errs []error
+	for _, e := range c.entries {
+		hp, ok := e.Provider.(HistoricalProvider)
+		if !ok {
+			continue
+		}
+		name := hp.Name()
+
+		history, err := hp.RatesBetween(ctx, from, to)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		if len(history) == 0 {
+			errs = append(errs, fmt.Errorf("%s: no rates between %s and %s", name, from.Format(time.DateOnly), to.Format(time.DateOnly)))
+			continue
+		}
+
+		for i := range history {
+			history[i].Provider = name
+		}
+		return history, nil
+	}
+
+	if len(errs) == 0 {
+		return nil, fmt.Errorf("no rate provider in %v supports history", c.Providers())
+	}
+	return nil, fmt.Errorf("all historical rate providers failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,396 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const ecbFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<gesmes:subject>Reference rates</gesmes:subject>
+	<gesmes:Sender>
+		<gesmes:name>European Central Bank</gesmes:name>
+	</gesmes:Sender>
+	<Cube>
+		<Cube time='2024-01-05'>
+			<Cube currency='USD' rate='1.0921'/>
+			<Cube currency='JPY' rate='158.08'/>
+			<Cube currency='GBP' rate='0.86'/>
+		</Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func serve(t *testing.T, status int, body string) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func near(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestAPIProvider(t *testing.T) {
+	var query string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query = r.URL.RawQuery
+		w.Write([]byte(`{"success":true,"source":"EUR","timestamp":1704470400,"quotes":{"EURUSD":1.25,"EURGBP":0.8,"EURXXX":0}}`))
+	}))
+	defer srv.Close()
+
+	rates, err := NewAPIProvider(srv.URL, "secret", "EUR").Rates(context.Background())
+	if err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	if !strings.Contains(query, "access_key=secret") || !strings.Contains(query, "source=EUR") {
+		t.Errorf("query = %q, want access_key and source", query)
+	}
+	if !near(rates.Values["USD"], 0.8) || !near(rates.Values["GBP"], 1.25) || rates.Values["EUR"] != 1 {
+		t.Errorf("Rates() = %v, want EUR per unit", rates.Values)
+	}
+	if _, ok := rates.Values["XXX"]; ok {
+		t.Error("Rates() kept a zero quote")
+	}
+	if !rates.AsOf.Equal(time.Unix(1704470400, 0)) {
+		t.Errorf("AsOf = %v, want response timestamp", rates.AsOf)
+	}
+}
+
+func TestAPIProviderErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   string
+	}{
+		{name: "unsuccessful", status: http.StatusOK, body: `{"success":false}`},
+		{name: "server error", status: http.StatusBadGateway, body: ``},
+		{name: "bad json", status: http.StatusOK, body: `{`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := serve(t, tt.status, tt.body)
+			if _, err := NewAPIProvider(srv.URL, "", "EUR").Rates(context.Background()); err == nil {
+				t.Fatal("Rates() expected error")
+			}
+		})
+	}
+}
+
+func TestECBProvider(t *testing.T) {
+	srv := serve(t, http.StatusOK, ecbFeed)
+
+	rates, err := NewECBProvider(srv.URL, "").Rates(context.Background())
+	if err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	if !near(rates.Values["USD"], 1/1.0921) || !near(rates.Values["JPY"], 1/158.08) || rates.Values["EUR"] != 1 {
+		t.Errorf("Rates() = %v, want EUR per unit", rates.Values)
+	}
+	want := time.Date(2024, 1, 5, 15, 0, 0, 0, time.UTC)
+	if !rates.AsOf.Equal(want) {
+		t.Errorf("AsOf = %v, want %v", rates.AsOf, want)
+	}
+
+	if _, err := NewECBProvider(serve(t, http.StatusOK, `<gesmes:Envelope/>`).URL, "").Rates(context.Background()); err == nil {
+		t.Error("Rates() expected error for empty feed")
+	}
+}
+
+const ecbHistoryFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2024-01-08"><Cube currency="USD" rate="1.0946"/></Cube>
+		<Cube time="2024-01-05"><Cube currency="USD" rate="1.0921"/></Cube>
+		<Cube time="2024-01-04"><Cube currency="USD" rate="1.0953"/></Cube>
+		<Cube time="2024-01-03"><Cube currency="USD" rate="1.0919"/></Cube>
+	</Cube>
+</gesmes:Envelope>`
+
+func TestECBProviderHistory(t *testing.T) {
+	srv := serve(t, http.StatusOK, ecbHistoryFeed)
+
+	from := time.Date(2024, 1, 4, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 7, 0, 0, 0, 0, time.UTC)
+	history, err := NewECBProvider("", srv.URL).RatesBetween(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("RatesBetween() error = %v", err)
+	}
+
+	// Oldest first, weekend missing.
+	if len(history) != 2 {
+		t.Fatalf("RatesBetween() returned %d days, want 2", len(history))
+	}
+	if !near(history[0].Values["USD"], 1/1.0953) || !near(history[1].Values["USD"], 1/1.0921) {
+		t.Errorf("RatesBetween() = %v, %v", history[0].Values, history[1].Values)
+	}
+	if !history[0].AsOf.Before(history[1].AsOf) {
+		t.Errorf("RatesBetween() not in date order: %v, %v", history[0].AsOf, history[1].AsOf)
+	}
+}
+
+func TestAPIProviderHistory(t *testing.T) {
+	var paths, dates []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		dates = append(dates, r.URL.Query().Get("date"))
+		w.Write([]byte(`{"success":true,"source":"EUR","quotes":{"EURUSD":1.25}}`))
+	}))
+	defer srv.Close()
+
+	from := time.Date(2024, 1, 4, 9, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 1, 6, 0, 0, 0, 0, time.UTC)
+	history, err := NewAPIProvider(srv.URL+"/live", "", "EUR").RatesBetween(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("RatesBetween() error = %v", err)
+	}
+
+	if len(history) != 3 || strings.Join(dates, ",") != "2024-01-04,2024-01-05,2024-01-06" {
+		t.Fatalf("RatesBetween() requested %v, want three days", dates)
+	}
+	if paths[0] != "/historical" {
+		t.Errorf("path = %s, want /historical", paths[0])
+	}
+	if want := time.Date(2024, 1, 5, 0, 0, 0, 0, time.UTC); !history[1].AsOf.Equal(want) {
+		t.Errorf("AsOf = %v, want %v", history[1].AsOf, want)
+	}
+}
+
+func TestFileProvider(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "rates.csv")
+	os.WriteFile(csvPath, []byte("currency,rate_to_eur\nusd,0.91\nBTC,35000\n"), 0o644)
+	jsonPath := filepath.Join(dir, "rates.json")
+	os.WriteFile(jsonPath, []byte(`{"as_of":"2024-01-05T16:00:00Z","rates":{"USD":0.92}}`), 0o644)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Last-Modified", "Fri, 05 Jan 2024 12:00:00 GMT")
+		w.Write([]byte("GBP,1.17\n"))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name     string
+		location string
+		currency string
+		want     float64
+		wantAsOf time.Time
+	}{
+		{name: "csv file", location: csvPath, currency: "USD", want: 0.91},
+		{name: "csv BTC", location: csvPath, currency: "BTC", want: 35000},
+		{name: "json file", location: jsonPath, currency: "USD", want: 0.92, wantAsOf: time.Date(2024, 1, 5, 16, 0, 0, 0, time.UTC)},
+		{name: "csv over http", location: srv.URL, currency: "GBP", want: 1.17, wantAsOf: time.Date(2024, 1, 5, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rates, err := NewFileProvider(tt.location).Rates(context.Background())
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if rates.Values[tt.currency] != tt.want {
+				t.Errorf("Rates()[%s] = %v, want %v", tt.currency, rates.Values[tt.currency], tt.want)
+			}
+			if !tt.wantAsOf.IsZero() && !rates.AsOf.Equal(tt.wantAsOf) {
+				t.Errorf("AsOf = %v, want %v", rates.AsOf, tt.wantAsOf)
+			}
+			if rates.AsOf.IsZero() {
+				t.Error("AsOf is zero, want modification time")
+			}
+		})
+	}
+
+	bad := filepath.Join(dir, "bad.csv")
+	os.WriteFile(bad, []byte("USD,0.91\nGBP,abc\n"), 0o644)
+	if _, err := NewFileProvider(bad).Rates(context.Background()); err == nil {
+		t.Error("Rates() expected error for invalid rate")
+	}
+	if _, err := NewFileProvider(filepath.Join(dir, "missing.csv")).Rates(context.Background()); err == nil {
+		t.Error("Rates() expected error for missing file")
+	}
+}
+
+type stubProvider struct {
+	name  string
+	rates Rates
+	err   error
+	calls int
+}
+
+func (p *stubProvider) Name() string { return p.name }
+
+func (p *stubProvider) Rates(ctx context.Context) (Rates, error) {
+	p.calls++
+	return p.rates, p.err
+}
+
+func TestChain(t *testing.T) {
+	now := time.Date(2024, 1, 6, 12, 0, 0, 0, time.UTC)
+	fresh := Rates{Values: map[string]float64{"USD": 0.91}, AsOf: now.Add(-time.Minute)}
+	stale := Rates{Values: map[string]float64{"USD": 0.5}, AsOf: now.Add(-48 * time.Hour)}
+
+	tests := []struct {
+		name    string
+		entries func() []ChainEntry
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "first provider wins",
+			entries: func() []ChainEntry {
+				return []ChainEntry{
+					{Provider: &stubProvider{name: "api", rates: fresh}, MaxAge: time.Hour},
+					{Provider: &stubProvider{name: "db", err: errors.New("unused")}},
+				}
+			},
+			want: "api",
+		},
+		{
+			name: "falls back on error",
+			entries: func() []ChainEntry {
+				return []ChainEntry{
+					{Provider: &stubProvider{name: "api", err: errors.New("outage")}},
+					{Provider: &stubProvider{name: "ecb", rates: fresh}},
+				}
+			},
+			want: "ecb",
+		},
+		{
+			name: "falls back on stale rates",
+			entries: func() []ChainEntry {
+				return []ChainEntry{
+					{Provider: &stubProvider{name: "ecb", rates: stale}, MaxAge: 24 * time.Hour},
+					{Provider: &stubProvider{name: "file", rates: fresh}},
+				}
+			},
+			want: "file",
+		},
+		{
+			name: "no max age accepts stale rates",
+			entries: func() []ChainEntry {
+				return []ChainEntry{{Provider: &stubProvider{name: "db", rates: stale}}}
+			},
+			want: "db",
+		},
+		{
+			name: "falls back on empty rates",
+			entries: func() []ChainEntry {
+				return []ChainEntry{
+					{Provider: &stubProvider{name: "file"}},
+					{Provider: &stubProvider{name: "db", rates: fresh}},
+				}
+			},
+			want: "db",
+		},
+		{
+			name: "all fail",
+			entries: func() []ChainEntry {
+				return []ChainEntry{
+					{Provider: &stubProvider{name: "api", err: errors.New("outage")}},
+					{Provider: &stubProvider{name: "ecb", rates: stale}, MaxAge: time.Hour},
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := NewChain(tt.entries()...)
+			c.now = func() time.Time { return now }
+
+			rates, err := c.Rates(context.Background())
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("Rates() expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Rates() error = %v", err)
+			}
+			if rates.Provider != tt.want {
+				t.Errorf("Rates() provider = %s, want %s", rates.Provider, tt.want)
+			}
+		})
+	}
+}
+
+func TestChainStopsAtFirstUsableProvider(t *testing.T) {
+	second := &stubProvider{name: "db"}
+	c := NewChain(
+		ChainEntry{Provider: &stubProvider{name: "api", rates: Rates{Values: map[string]float64{"USD": 1}, AsOf: time.Now()}}},
+		ChainEntry{Provider: second},
+	)
+	if _, err := c.Rates(context.Background()); err != nil {
+		t.Fatalf("Rates() error = %v", err)
+	}
+	if second.calls != 0 {
+		t.Errorf("second provider called %d times, want 0", second.calls)
+	}
+}
+
+type historyStub struct {
+	stubProvider
+	history []Rates
+}
+
+func (p *historyStub) RatesBetween(ctx context.Context, from, to time.Time) ([]Rates, error) {
+	return p.history, p.err
+}
+
+func TestChainRatesBetween(t *testing.T) {
+	day := Rates{Values: map[string]float64{"USD": 0.91}, AsOf: time.Now()}
+
+	c := NewChain(
+		ChainEntry{Provider: &stubProvider{name: "db"}}, // no history, skipped
+		ChainEntry{Provider: &historyStub{stubProvider: stubProvider{name: "api", err: errors.New("outage")}}},
+		ChainEntry{Provider: &historyStub{stubProvider: stubProvider{name: "ecb"}, history: []Rates{day}}},
+	)
+	history, err := c.RatesBetween(context.Background(), time.Now(), time.Now())
+	if err != nil {
+		t.Fatalf("RatesBetween() error = %v", err)
+	}
+	if len(history) != 1 || history[0].Provider != "ecb" {
+		t.Errorf("RatesBetween() = %+v, want one day from ecb", history)
+	}
+
+	if _, err := NewChain(ChainEntry{Provider: &stubProvider{name: "db"}}).RatesBetween(context.Background(), time.Now(), time.Now()); err == nil {
+		t.Error("RatesBetween() expected error without historical providers")
+	}
+}
+
+func TestParseChain(t *testing.T) {
+	providers := map[string]RateProvider{
+		"api": &stubProvider{name: "api"},
+		"ecb": &stubProvider{name: "ecb"},
+		"db":  &stubProvider{name: "db"},
+	}
+
+	c, err := ParseChain("api:1h, ecb:48h,db", providers)
+	if err != nil {
+		t.Fatalf("ParseChain() error = %v", err)
+	}
+	if got := strings.Join(c.Providers(), ","); got != "api,ecb,db" {
+		t.Errorf("Providers() = %s, want api,ecb,db", got)
+	}
+	if c.entries[0].MaxAge != time.Hour || c.entries[2].MaxAge != 0 {
+		t.Errorf("entries = %+v, want max ages 1h, 48h, none", c.entries)
+	}
+
+	for _, spec := range []string{"", "nope", "api:soon"} {
+		if _, err := ParseChain(spec, providers); err == nil {
+			t.Errorf("ParseChain(%q) expected error", spec)
+		}
+	}
+}
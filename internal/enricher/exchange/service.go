@@ -1,32 +1,50 @@
 package exchange
 
 import (
+	"context"
 	"database/sql"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"net/http"
 	"os"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
 )
 
+// ErrUnsupportedCurrency means no provider had a rate for the currency on
+// the last refresh. The answer is cached for the negative cache duration.
+var ErrUnsupportedCurrency = errors.New("unsupported currency")
+
 type Service struct {
 	db            *sql.DB
-	apiKey        string
-	apiURL        string
+	chain         *Chain
 	memoryCacheDuration time.Duration
+	negativeCacheDuration time.Duration
+	minRefreshInterval time.Duration
 	sourceCurrency string
-	rates         map[string]float64
-	lastUpdate    time.Time
+	cache         map[string]cachedRate
+	unsupported   map[string]time.Time // currency -> negative cache expiry
 	mu            sync.RWMutex
+
+	refreshMu      sync.Mutex
+	inflight       *refreshCall // refresh in progress, shared by all callers
+	lastRefresh    time.Time
+	lastRefreshErr error
+	refreshingAsync atomic.Bool
+}
+
+type cachedRate struct {
+	rate      float64
+	effective time.Time // when the rate took effect at its provider
+	fetched   time.Time // when it entered the cache
 }
 
-type APIResponse struct {
-	Success bool               `json:"success"`
-	Source  string            `json:"source"`
-	Quotes  map[string]float64 `json:"quotes"`
+type refreshCall struct {
+	done chan struct{}
+	err  error
 }
 
 func New(db *sql.DB) (*Service, error) {
@@ -34,128 +52,299 @@ func New(db *sql.DB) (*Service, error) {
 	if err != nil {
 		memoryCacheDuration = time.Minute // default 1m
 	}
+	negativeCacheDuration, err := time.ParseDuration(os.Getenv("EXCHANGE_RATE_NEGATIVE_CACHE_DURATION"))
+	if err != nil {
+		negativeCacheDuration = 10 * time.Minute // default 10m
+	}
+	minRefreshInterval, err := time.ParseDuration(os.Getenv("EXCHANGE_RATE_MIN_REFRESH_INTERVAL"))
+	if err != nil {
+		minRefreshInterval = time.Minute // default 1m
+	}
 
-	log.Printf("Exchange service initialized with memory cache: %v", memoryCacheDuration)
+	log.Printf("Exchange service initialized with memory cache: %v, negative cache: %v, min refresh interval: %v",
+		memoryCacheDuration, negativeCacheDuration, minRefreshInterval)
 
+	sourceCurrency := os.Getenv("EXCHANGE_RATE_SOURCE_CURRENCY")
 	return &Service{
 		db:            db,
-		apiKey:        os.Getenv("EXCHANGE_RATE_API_KEY"),
-		apiURL:        os.Getenv("EXCHANGE_RATE_API_URL"),
+		chain: NewChain(ChainEntry{Provider: NewAPIProvider(
+			os.Getenv("EXCHANGE_RATE_API_URL"),
+			os.Getenv("EXCHANGE_RATE_API_KEY"),
+			sourceCurrency,
+		)}),
 		memoryCacheDuration: memoryCacheDuration,
-		sourceCurrency: os.Getenv("EXCHANGE_RATE_SOURCE_CURRENCY"),
-		rates:         make(map[string]float64),
+		negativeCacheDuration: negativeCacheDuration,
+		minRefreshInterval: minRefreshInterval,
+		sourceCurrency: sourceCurrency,
+		cache:         make(map[string]cachedRate),
+		unsupported:   make(map[string]time.Time),
 	}, nil
 }
 
-// RefreshRates forces an update of rates from the API
+// SetChain replaces the default API-only provider chain used to refresh rates.
+func (s *Service) SetChain(chain *Chain) {
+	s.chain = chain
+}
+
+// RefreshRates updates rates from the provider chain. Concurrent calls
+// share one refresh, and calls within the minimum refresh interval of the
+// last one return its result without contacting the providers.
 func (s *Service) RefreshRates() error {
-	log.Println("Refreshing exchange rates from API...")
-	return s.updateRates()
+	return s.refresh()
 }
 
+// GetRate returns how many EUR one unit of currency is worth.
+//
+// Cached rates are returned without blocking; once older than the memory
+// cache duration they are still served while a background refresh runs.
+// Only a currency with no rate at all waits for a refresh.
 func (s *Service) GetRate(currency string) (float64, error) {
 	if currency == s.sourceCurrency {
 		return 1.0, nil
 	}
 
-	// Try memory cache first
+	now := time.Now()
 	s.mu.RLock()
-	if time.Since(s.lastUpdate) < s.memoryCacheDuration {
-		if rate, ok := s.rates[currency]; ok {
-			s.mu.RUnlock()
-			return rate, nil
+	cached, ok := s.cache[currency]
+	negativeUntil, negative := s.unsupported[currency]
+	s.mu.RUnlock()
+
+	if ok {
+		if now.Sub(cached.fetched) < s.memoryCacheDuration {
+			metrics.ExchangeRateLookups.WithLabelValues("hit").Inc()
+		} else {
+			metrics.ExchangeRateLookups.WithLabelValues("stale").Inc()
+			s.refreshAsync()
 		}
+		return cached.rate, nil
+	}
+	if negative && now.Before(negativeUntil) {
+		metrics.ExchangeRateLookups.WithLabelValues("negative").Inc()
+		return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
 	}
-	s.mu.RUnlock()
 
 	// Try database
-	var rate float64
-	var updatedAt time.Time
-	err := s.db.QueryRow(
-		`SELECT rate_to_eur, updated_at 
-		 FROM exchange_rates 
-		 WHERE currency = $1`, 
-		currency,
-	).Scan(&rate, &updatedAt)
+	if s.db != nil {
+		var rate float64
+		var updatedAt time.Time
+		err := s.db.QueryRow(
+			`SELECT rate_to_eur, updated_at 
+			 FROM exchange_rates 
+			 WHERE currency = $1`, 
+			currency,
+		).Scan(&rate, &updatedAt)
 
-	// Log rate from database
-	log.Printf("Got rate for %s: %.10f (updated: %s)", 
-		currency, rate, updatedAt.Format(time.RFC3339))
+		if err == nil {
+			// Log rate from database
+			log.Printf("Got rate for %s: %.10f (updated: %s)", 
+				currency, rate, updatedAt.Format(time.RFC3339))
 
-	if err == nil {
-		// Store in memory cache
-		s.mu.Lock()
-		s.rates[currency] = rate
-		s.lastUpdate = time.Now()
-		s.mu.Unlock()
-		return rate, nil
+			metrics.ExchangeRateLookups.WithLabelValues("db").Inc()
+			s.mu.Lock()
+			s.cache[currency] = cachedRate{rate: rate, effective: updatedAt, fetched: now}
+			s.mu.Unlock()
+			return rate, nil
+		}
 	}
 
-	// Rate not found, try API once
-	if err := s.RefreshRates(); err != nil {
-		return 0, fmt.Errorf("no rate found for currency %s and API refresh failed: %w", currency, err)
+	// Rate not found anywhere, wait for a (shared) refresh
+	metrics.ExchangeRateLookups.WithLabelValues("miss").Inc()
+	if err := s.refresh(); err != nil {
+		return 0, fmt.Errorf("no rate found for currency %s and refresh failed: %w", currency, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cached, ok := s.cache[currency]; ok {
+		return cached.rate, nil
+	}
+	s.unsupported[currency] = time.Now().Add(s.negativeCacheDuration)
+	return 0, fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+}
+
+// GetRateAt returns how many EUR one unit of currency was worth at the
+// given time, so late and replayed events convert at their own rate.
+// Times at or after the current rate took effect use GetRate and its cache.
+func (s *Service) GetRateAt(ctx context.Context, currency string, at time.Time) (float64, error) {
+	if currency == s.sourceCurrency || at.IsZero() {
+		return s.GetRate(currency)
 	}
 
 	s.mu.RLock()
-	rate, ok := s.rates[currency]
+	cached, ok := s.cache[currency]
 	s.mu.RUnlock()
-	if !ok {
-		return 0, fmt.Errorf("no rate found for currency %s even after API refresh", currency)
+	if (ok && !at.Before(cached.effective)) || s.db == nil {
+		return s.GetRate(currency)
 	}
 
+	var rate float64
+	err := s.db.QueryRowContext(ctx,
+		`SELECT rate_to_eur
+		 FROM exchange_rate_history
+		 WHERE currency = $1 AND effective_at <= $2
+		 ORDER BY effective_at DESC
+		 LIMIT 1`,
+		currency, at,
+	).Scan(&rate)
+	if err == sql.ErrNoRows {
+		log.Printf("No rate history for %s at %s, using current rate", currency, at.Format(time.RFC3339))
+		return s.GetRate(currency)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to query rate history for %s: %w", currency, err)
+	}
 	return rate, nil
 }
 
+// Backfill stores the chain's historical rates for every day between from
+// and to in exchange_rate_history and returns the number of rows written.
+func (s *Service) Backfill(ctx context.Context, from, to time.Time) (int, error) {
+	if s.db == nil {
+		return 0, errors.New("no database to store rate history")
+	}
+	history, err := s.chain.RatesBetween(ctx, from, to)
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	n := 0
+	for _, rates := range history {
+		if err := insertHistory(tx, rates); err != nil {
+			return 0, err
+		}
+		n += len(rates.Values)
+	}
+	return n, tx.Commit()
+}
+
+func insertHistory(tx *sql.Tx, rates Rates) error {
+	for currency, rate := range rates.Values {
+		_, err := tx.Exec(
+			`INSERT INTO exchange_rate_history (currency, rate_to_eur, effective_at, provider)
+			 VALUES ($1, $2, $3, $4)
+			 ON CONFLICT (currency, effective_at)
+			 DO UPDATE SET rate_to_eur = $2, provider = $4`,
+			currency, rate, rates.AsOf, rates.Provider,
+		)
+		if err != nil {
+			return fmt.Errorf("failed to store %s rate history: %w", currency, err)
+		}
+	}
+	return nil
+}
+
+// refresh runs updateRates at most once at a time and at most once per
+// minimum refresh interval. Callers arriving during a refresh wait for it
+// and share its result.
+func (s *Service) refresh() error {
+	s.refreshMu.Lock()
+	if call := s.inflight; call != nil {
+		s.refreshMu.Unlock()
+		metrics.ExchangeRateRefreshes.WithLabelValues("coalesced").Inc()
+		<-call.done
+		return call.err
+	}
+	if !s.lastRefresh.IsZero() && time.Since(s.lastRefresh) < s.minRefreshInterval {
+		err := s.lastRefreshErr
+		s.refreshMu.Unlock()
+		metrics.ExchangeRateRefreshes.WithLabelValues("throttled").Inc()
+		if err != nil {
+			return fmt.Errorf("refresh throttled, last refresh failed: %w", err)
+		}
+		return nil
+	}
+	call := &refreshCall{done: make(chan struct{})}
+	s.inflight = call
+	s.lastRefresh = time.Now()
+	s.refreshMu.Unlock()
+
+	start := time.Now()
+	call.err = s.updateRates()
+	metrics.ExchangeRateRefreshDuration.Observe(time.Since(start).Seconds())
+	if call.err != nil {
+		metrics.ExchangeRateRefreshes.WithLabelValues("error").Inc()
+	} else {
+		metrics.ExchangeRateRefreshes.WithLabelValues("success").Inc()
+	}
+
+	s.refreshMu.Lock()
+	s.inflight = nil
+	s.lastRefreshErr = call.err
+	s.refreshMu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+// refreshAsync starts a background refresh unless one is already running.
+func (s *Service) refreshAsync() {
+	if !s.refreshingAsync.CompareAndSwap(false, true) {
+		return
+	}
+	go func() {
+		defer s.refreshingAsync.Store(false)
+		if err := s.refresh(); err != nil {
+			log.Printf("Background exchange rate refresh failed: %v", err)
+		}
+	}()
+}
+
+// updateRates fetches rates from the chain and stores them. The cache lock
+// is only held to swap in the new rates, never during network or database
+// calls.
 func (s *Service) updateRates() error {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	url := fmt.Sprintf("%s?access_key=%s&source=%s", s.apiURL, s.apiKey, s.sourceCurrency)
-	log.Printf("Fetching rates from: %s", url)
-	resp, err := http.Get(url)
+	log.Printf("Refreshing exchange rates from %v...", s.chain.Providers())
+	rates, err := s.chain.Rates(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to get rates: %w", err)
+		return err
 	}
-	defer resp.Body.Close()
+	log.Printf("Got %d rates from %s (as of %s)",
+		len(rates.Values), rates.Provider, rates.AsOf.Format(time.RFC3339))
 
-	var apiResp APIResponse
-	if err := json.NewDecoder(resp.Body).Decode(&apiResp); err != nil {
-		return fmt.Errorf("failed to decode response: %w", err)
+	now := time.Now()
+	s.mu.Lock()
+	for currency, rate := range rates.Values {
+		s.cache[currency] = cachedRate{rate: rate, effective: rates.AsOf, fetched: now}
+		delete(s.unsupported, currency)
 	}
+	s.mu.Unlock()
 
-	if !apiResp.Success {
-		return fmt.Errorf("API request failed")
+	// Rates read back from the table are already stored.
+	if s.db == nil || rates.Provider == "db" {
+		return nil
 	}
 
-	tx, err := s.db.Begin()
+	tx, err := s.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
 	defer tx.Rollback()
 
-	// Update database and memory cache
-	for key, value := range apiResp.Quotes {
-		currency := strings.TrimPrefix(key, s.sourceCurrency)
-		s.rates[currency] = value
-
+	for currency, rate := range rates.Values {
 		_, err = tx.Exec(
 			`INSERT INTO exchange_rates (currency, rate_to_eur, updated_at) 
-			 VALUES ($1, $2, NOW())
+			 VALUES ($1, $2, $3)
 			 ON CONFLICT (currency) 
-			 DO UPDATE SET rate_to_eur = $2, updated_at = NOW()`,
-			currency, value,
+			 DO UPDATE SET rate_to_eur = $2, updated_at = $3`,
+			currency, rate, rates.AsOf,
 		)
 		if err != nil {
 			return err
 		}
 	}
-
-	if err := tx.Commit(); err != nil {
+	if err := insertHistory(tx, rates); err != nil {
 		return err
 	}
 
-	s.lastUpdate = time.Now()
-	return nil
+	return tx.Commit()
 }
 
 func (s *Service) GetRateFromDB(currency string) (float64, error) {
@@ -0,0 +1,171 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// countingProvider serves fixed rates, counting calls and optionally
+// blocking until released.
+type countingProvider struct {
+	values  map[string]float64
+	err     error
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (p *countingProvider) Name() string { return "api" }
+
+func (p *countingProvider) Rates(ctx context.Context) (Rates, error) {
+	p.calls.Add(1)
+	if p.release != nil {
+		<-p.release
+	}
+	if p.err != nil {
+		return Rates{}, p.err
+	}
+	values := make(map[string]float64, len(p.values))
+	for c, r := range p.values {
+		values[c] = r
+	}
+	return Rates{Values: values, AsOf: time.Now()}, nil
+}
+
+func newTestService(p RateProvider) *Service {
+	return &Service{
+		chain:                 NewChain(ChainEntry{Provider: p}),
+		memoryCacheDuration:   time.Minute,
+		negativeCacheDuration: time.Minute,
+		sourceCurrency:        "EUR",
+		cache:                 make(map[string]cachedRate),
+		unsupported:           make(map[string]time.Time),
+	}
+}
+
+func TestGetRateCoalescesRefreshes(t *testing.T) {
+	p := &countingProvider{values: map[string]float64{"USD": 0.91}, release: make(chan struct{})}
+	s := newTestService(p)
+
+	const callers = 50
+	var wg sync.WaitGroup
+	errs := make(chan error, callers)
+	for i := 0; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			rate, err := s.GetRate("USD")
+			if err == nil && rate != 0.91 {
+				err = errors.New("wrong rate")
+			}
+			errs <- err
+		}()
+	}
+
+	// Let the callers pile up behind the first refresh.
+	time.Sleep(50 * time.Millisecond)
+	close(p.release)
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatalf("GetRate() error = %v", err)
+		}
+	}
+	if n := p.calls.Load(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+func TestGetRateServesStaleWhileRefreshing(t *testing.T) {
+	p := &countingProvider{values: map[string]float64{"USD": 0.95}, release: make(chan struct{})}
+	s := newTestService(p)
+	s.cache["USD"] = cachedRate{rate: 0.91, fetched: time.Now().Add(-time.Hour)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 10; i++ {
+			if rate, err := s.GetRate("USD"); err != nil || rate != 0.91 {
+				t.Errorf("GetRate() = %v, %v, want stale 0.91", rate, err)
+			}
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("GetRate() blocked on the background refresh")
+	}
+
+	close(p.release)
+	deadline := time.Now().Add(time.Second)
+	for {
+		if rate, _ := s.GetRate("USD"); rate == 0.95 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background refresh did not update the rate")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if n := p.calls.Load(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+}
+
+func TestGetRateNegativeCache(t *testing.T) {
+	p := &countingProvider{values: map[string]float64{"USD": 0.91}}
+	s := newTestService(p)
+
+	for i := 0; i < 3; i++ {
+		if _, err := s.GetRate("XYZ"); !errors.Is(err, ErrUnsupportedCurrency) {
+			t.Fatalf("GetRate() error = %v, want ErrUnsupportedCurrency", err)
+		}
+	}
+	if n := p.calls.Load(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+
+	// Expired entries are retried; a currency that appears clears them.
+	s.unsupported["XYZ"] = time.Now().Add(-time.Second)
+	p.values["XYZ"] = 2
+	if rate, err := s.GetRate("XYZ"); err != nil || rate != 2 {
+		t.Errorf("GetRate() = %v, %v, want 2", rate, err)
+	}
+	if _, ok := s.unsupported["XYZ"]; ok {
+		t.Error("negative cache entry kept after the currency appeared")
+	}
+}
+
+func TestRefreshMinInterval(t *testing.T) {
+	p := &countingProvider{err: errors.New("outage")}
+	s := newTestService(p)
+	s.minRefreshInterval = time.Hour
+
+	if err := s.RefreshRates(); err == nil {
+		t.Fatal("RefreshRates() expected error")
+	}
+	// Within the interval the last error is returned without a new call,
+	// and a failed refresh does not mark the currency unsupported.
+	if _, err := s.GetRate("USD"); err == nil || errors.Is(err, ErrUnsupportedCurrency) {
+		t.Errorf("GetRate() error = %v, want throttled refresh error", err)
+	}
+	if n := p.calls.Load(); n != 1 {
+		t.Errorf("provider called %d times, want 1", n)
+	}
+
+	s.lastRefresh = time.Now().Add(-2 * time.Hour)
+	p.err = nil
+	p.values = map[string]float64{"USD": 0.91}
+	if err := s.RefreshRates(); err != nil {
+		t.Fatalf("RefreshRates() error = %v", err)
+	}
+	if n := p.calls.Load(); n != 2 {
+		t.Errorf("provider called %d times, want 2", n)
+	}
+}
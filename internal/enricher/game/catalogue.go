@@ -0,0 +1,157 @@
+package game
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "sort"
+    "sync"
+    "time"
+    "github.com/lib/pq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// NotifyChannel is the channel the games trigger notifies on.
+const NotifyChannel = "games_changed"
+
+// DefaultReloadInterval is how often the catalogue is re-read even without
+// notifications.
+const DefaultReloadInterval = time.Minute
+
+var ErrInvalidGame = errors.New("invalid game")
+
+// Catalogue is the in-process copy of the game catalogue. It starts out
+// with the built-in casino.Games and is replaced by the store's contents on
+// every successful Reload.
+type Catalogue struct {
+    store Store
+
+    mu    sync.RWMutex
+    games map[int]casino.Game
+}
+
+func NewCatalogue(store Store) *Catalogue {
+    games := make(map[int]casino.Game, len(casino.Games))
+    for id, g := range casino.Games {
+        games[id] = g
+    }
+    return &Catalogue{store: store, games: games}
+}
+
+func (c *Catalogue) Get(id int) (casino.Game, bool) {
+    c.mu.RLock()
+    defer c.mu.RUnlock()
+    g, ok := c.games[id]
+    return g, ok
+}
+
+// List returns all games ordered by id.
+func (c *Catalogue) List() []casino.Game {
+    c.mu.RLock()
+    games := make([]casino.Game, 0, len(c.games))
+    for _, g := range c.games {
+        games = append(games, g)
+    }
+    c.mu.RUnlock()
+
+    sort.Slice(games, func(i, j int) bool { return games[i].ID < games[j].ID })
+    return games
+}
+
+// Reload replaces the catalogue with the store's contents.
+func (c *Catalogue) Reload(ctx context.Context) error {
+    list, err := c.store.List(ctx)
+    if err != nil {
+        metrics.GameCatalogueReloads.WithLabelValues("error").Inc()
+        return err
+    }
+
+    games := make(map[int]casino.Game, len(list))
+    for _, g := range list {
+        games[g.ID] = g
+    }
+
+    c.mu.Lock()
+    c.games = games
+    c.mu.Unlock()
+
+    metrics.GameCatalogueReloads.WithLabelValues("success").Inc()
+    metrics.GameCatalogueSize.Set(float64(len(games)))
+    return nil
+}
+
+// Save validates and stores g, then updates the catalogue without waiting
+// for the change notification.
+func (c *Catalogue) Save(ctx context.Context, g casino.Game) error {
+    if err := validate(g); err != nil {
+        return err
+    }
+    if err := c.store.Save(ctx, g); err != nil {
+        return err
+    }
+
+    c.mu.Lock()
+    c.games[g.ID] = g
+    size := len(c.games)
+    c.mu.Unlock()
+    metrics.GameCatalogueSize.Set(float64(size))
+    return nil
+}
+
+func validate(g casino.Game) error {
+    switch {
+    case g.ID <= 0:
+        return fmt.Errorf("%w: id must be positive", ErrInvalidGame)
+    case g.Title == "":
+        return fmt.Errorf("%w: title is required", ErrInvalidGame)
+    case g.RTP < 0 || g.RTP > 100:
+        return fmt.Errorf("%w: rtp %.2f is not a percentage", ErrInvalidGame, g.RTP)
+    }
+    switch g.Volatility {
+    case "", "low", "medium", "high":
+    default:
+        return fmt.Errorf("%w: volatility %q, want low, medium or high", ErrInvalidGame, g.Volatility)
+    }
+    return nil
+}
+
+// Watch keeps the catalogue in sync until ctx is done: it reloads every
+// interval and, when dbURL is set, whenever the games_changed notification
+// arrives, so changes made by other instances or directly in the database
+// show up promptly.
+func (c *Catalogue) Watch(ctx context.Context, dbURL string, interval time.Duration) {
+    if interval <= 0 {
+        interval = DefaultReloadInterval
+    }
+
+    var notify <-chan *pq.Notification
+    if dbURL != "" {
+        listener := pq.NewListener(dbURL, time.Second, 30*time.Second, nil)
+        if err := listener.Listen(NotifyChannel); err != nil {
+            log.Printf("Game catalogue not listening on %s, reloading every %s: %v", NotifyChannel, interval, err)
+            listener.Close()
+        } else {
+            defer listener.Close()
+            notify = listener.Notify
+        }
+    }
+
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-notify:
+            // A nil notification after a reconnect may hide missed changes,
+            // so it triggers a reload too.
+        case <-ticker.C:
+        }
+        if err := c.Reload(ctx); err != nil {
+            log.Printf("Failed to reload game catalogue: %v", err)
+        }
+    }
+}
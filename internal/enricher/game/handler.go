@@ -0,0 +1,142 @@
+package game
+
+import (
+    "crypto/subtle"
+    "encoding/json"
+    "errors"
+    "log"
+    "net/http"
+    "strconv"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Handler serves the admin API for the catalogue:
+//
+//    GET  /games       list all games
+//    GET  /games/{id}  one game
+//    POST /games       add a game; the body must include its id
+//    PUT  /games/{id}  add or update a game; omitted fields keep their value
+//
+// Writes require "Authorization: Bearer <token>". Without a token they are
+// refused, so the catalogue is read-only until one is configured. Setting
+// "active" to false keeps the game listed here but stops it being attached
+// to events.
+func Handler(c *Catalogue, token string) http.Handler {
+    h := &handler{catalogue: c, token: token}
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /games", h.list)
+    mux.HandleFunc("GET /games/{id}", h.get)
+    mux.HandleFunc("POST /games", h.authorized(h.create))
+    mux.HandleFunc("PUT /games/{id}", h.authorized(h.update))
+    return mux
+}
+
+type handler struct {
+    catalogue *Catalogue
+    token     string
+}
+
+func (h *handler) authorized(next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if h.token == "" {
+            writeError(w, http.StatusForbidden, "admin API disabled: no admin token configured")
+            return
+        }
+        want := []byte("Bearer " + h.token)
+        if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), want) != 1 {
+            writeError(w, http.StatusUnauthorized, "missing or invalid admin token")
+            return
+        }
+        next(w, r)
+    }
+}
+
+func (h *handler) list(w http.ResponseWriter, r *http.Request) {
+    writeJSON(w, http.StatusOK, h.catalogue.List())
+}
+
+func (h *handler) get(w http.ResponseWriter, r *http.Request) {
+    id, ok := pathID(w, r)
+    if !ok {
+        return
+    }
+    game, found := h.catalogue.Get(id)
+    if !found {
+        writeError(w, http.StatusNotFound, "game not found")
+        return
+    }
+    writeJSON(w, http.StatusOK, game)
+}
+
+func (h *handler) create(w http.ResponseWriter, r *http.Request) {
+    game := casino.Game{Active: true}
+    if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid game: "+err.Error())
+        return
+    }
+    if _, exists := h.catalogue.Get(game.ID); exists {
+        writeError(w, http.StatusConflict, "game already exists, use PUT /games/{id}")
+        return
+    }
+    h.save(w, r, game, http.StatusCreated)
+}
+
+func (h *handler) update(w http.ResponseWriter, r *http.Request) {
+    id, ok := pathID(w, r)
+    if !ok {
+        return
+    }
+
+    game, exists := h.catalogue.Get(id)
+    if !exists {
+        game = casino.Game{Active: true}
+    }
+    if err := json.NewDecoder(r.Body).Decode(&game); err != nil {
+        writeError(w, http.StatusBadRequest, "invalid game: "+err.Error())
+        return
+    }
+    if game.ID != 0 && game.ID != id {
+        writeError(w, http.StatusBadRequest, "id in body does not match the URL")
+        return
+    }
+    game.ID = id
+
+    status := http.StatusOK
+    if !exists {
+        status = http.StatusCreated
+    }
+    h.save(w, r, game, status)
+}
+
+func (h *handler) save(w http.ResponseWriter, r *http.Request, game casino.Game, status int) {
+    if err := h.catalogue.Save(r.Context(), game); err != nil {
+        if errors.Is(err, ErrInvalidGame) {
+            writeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        log.Printf("Failed to save game %d: %v", game.ID, err)
+        writeError(w, http.StatusInternalServerError, "failed to save game")
+        return
+    }
+    log.Printf("Game %d saved: %q", game.ID, game.Title)
+    writeJSON(w, status, game)
+}
+
+func pathID(w http.ResponseWriter, r *http.Request) (int, bool) {
+    id, err := strconv.Atoi(r.PathValue("id"))
+    if err != nil || id <= 0 {
+        writeError(w, http.StatusBadRequest, "invalid game id")
+        return 0, false
+    }
+    return id, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+    writeJSON(w, status, map[string]string{"error": msg})
+}
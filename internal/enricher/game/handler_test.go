@@ -0,0 +1,90 @@
+package game
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func TestHandler(t *testing.T) {
+	store := newMemStore()
+	catalogue := NewCatalogue(store)
+	h := Handler(catalogue, "secret")
+
+	do := func(method, path, body string, auth bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if auth {
+			req.Header.Set("Authorization", "Bearer secret")
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		body       string
+		auth       bool
+		wantStatus int
+	}{
+		{"list", "GET", "/games", "", false, http.StatusOK},
+		{"get built-in", "GET", "/games/100", "", false, http.StatusOK},
+		{"get unknown", "GET", "/games/999", "", false, http.StatusNotFound},
+		{"get bad id", "GET", "/games/abc", "", false, http.StatusBadRequest},
+		{"create without token", "POST", "/games", `{"id":200,"title":"Gates of Olympus"}`, false, http.StatusUnauthorized},
+		{"create", "POST", "/games", `{"id":200,"title":"Gates of Olympus","rtp":96.5}`, true, http.StatusCreated},
+		{"create existing", "POST", "/games", `{"id":200,"title":"Again"}`, true, http.StatusConflict},
+		{"create invalid", "POST", "/games", `{"id":201}`, true, http.StatusBadRequest},
+		{"update partial", "PUT", "/games/200", `{"volatility":"high"}`, true, http.StatusOK},
+		{"update id mismatch", "PUT", "/games/200", `{"id":201}`, true, http.StatusBadRequest},
+		{"update creates", "PUT", "/games/202", `{"title":"Sweet Bonanza"}`, true, http.StatusCreated},
+		{"update bad json", "PUT", "/games/202", `{`, true, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		rec := do(tt.method, tt.path, tt.body, tt.auth)
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: %s %s = %d, want %d (%s)", tt.name, tt.method, tt.path, rec.Code, tt.wantStatus, rec.Body)
+		}
+	}
+
+	g, ok := catalogue.Get(200)
+	if !ok || g.Title != "Gates of Olympus" || g.RTP != 96.5 || g.Volatility != "high" || !g.Active {
+		t.Errorf("game 200 = %+v, want partial update to keep the other fields", g)
+	}
+	if stored := store.games[202]; stored.Title != "Sweet Bonanza" || !stored.Active {
+		t.Errorf("stored game 202 = %+v, want new active game", stored)
+	}
+
+	rec := do("GET", "/games/202", "", false)
+	var got casino.Game
+	if err := json.NewDecoder(rec.Body).Decode(&got); err != nil || got.ID != 202 {
+		t.Errorf("GET /games/202 = %+v, %v", got, err)
+	}
+}
+
+func TestHandlerWithoutToken(t *testing.T) {
+	h := Handler(NewCatalogue(newMemStore()), "")
+	for _, auth := range []string{"", "Bearer "} {
+		req := httptest.NewRequest("PUT", "/games/300", strings.NewReader(`{"title":"Open"}`))
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if rec.Code != http.StatusForbidden {
+			t.Errorf("PUT with Authorization %q and no configured token = %d, want %d", auth, rec.Code, http.StatusForbidden)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/games", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("GET without configured token = %d, want %d", rec.Code, http.StatusOK)
+	}
+}
@@ -0,0 +1,46 @@
+package game
+
+import (
+    "context"
+    "log"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+)
+
+// Service attaches the catalogue entry of the event's game. Inactive games
+// are not attached: their events are enriched as if the game were missing
+// from the catalogue.
+type Service struct {
+    catalogue *Catalogue
+}
+
+func New(catalogue *Catalogue) *Service {
+    return &Service{catalogue: catalogue}
+}
+
+func (s *Service) Spec() enricher.Spec {
+    return enricher.Spec{
+        Name:     "game",
+        Timeout:  100 * time.Millisecond,
+        Produces: []string{enricher.FieldGame},
+    }
+}
+
+func (s *Service) Enrich(ctx context.Context, event *casino.Event) error {
+    // Deposits have no game
+    if event.GameID == 0 {
+        return nil
+    }
+
+    game, ok := s.catalogue.Get(event.GameID)
+    if !ok {
+        log.Printf("No game data found for ID: %d", event.GameID)
+        return nil
+    }
+    if !game.Active {
+        return nil
+    }
+    event.Game = &game
+    return nil
+}
@@ -0,0 +1,143 @@
+package game
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// memStore is an in-memory Store.
+type memStore struct {
+	mu    sync.Mutex
+	games map[int]casino.Game
+	err   error
+}
+
+func newMemStore(games ...casino.Game) *memStore {
+	s := &memStore{games: make(map[int]casino.Game)}
+	for _, g := range games {
+		s.games[g.ID] = g
+	}
+	return s
+}
+
+func (s *memStore) List(ctx context.Context) ([]casino.Game, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return nil, s.err
+	}
+	var games []casino.Game
+	for _, g := range s.games {
+		games = append(games, g)
+	}
+	return games, nil
+}
+
+func (s *memStore) Save(ctx context.Context, g casino.Game) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.err != nil {
+		return s.err
+	}
+	s.games[g.ID] = g
+	return nil
+}
+
+func TestGameEnricher(t *testing.T) {
+	store := newMemStore(casino.Game{ID: 200, Title: "Gates of Olympus", Provider: "Pragmatic Play", RTP: 96.5, Volatility: "high", Active: true})
+	catalogue := NewCatalogue(store)
+
+	// Before the first reload the built-in games are served.
+	svc := New(catalogue)
+	event := casino.Event{Type: "game_start", GameID: 100}
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.Game == nil || event.Game.Title != "Rocket Dice" {
+		t.Fatalf("Game = %+v, want built-in Rocket Dice", event.Game)
+	}
+
+	if err := catalogue.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	event = casino.Event{Type: "bet", GameID: 200}
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.Game == nil || event.Game.Provider != "Pragmatic Play" || event.Game.RTP != 96.5 {
+		t.Errorf("Game = %+v, want Gates of Olympus from the store", event.Game)
+	}
+	if got := event.GameTitle(); got != "Gates of Olympus" {
+		t.Errorf("GameTitle() = %q", got)
+	}
+
+	// The store replaced the built-in games.
+	for _, id := range []int{100, 0} {
+		event = casino.Event{Type: "deposit", GameID: id}
+		if err := svc.Enrich(context.Background(), &event); err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+		if event.Game != nil {
+			t.Errorf("GameID %d: Game = %+v, want nil", id, event.Game)
+		}
+	}
+}
+
+func TestGameEnricherSkipsInactiveGames(t *testing.T) {
+	store := newMemStore(casino.Game{ID: 200, Title: "Gates of Olympus", Active: false})
+	catalogue := NewCatalogue(store)
+	if err := catalogue.Reload(context.Background()); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	event := casino.Event{Type: "bet", GameID: 200}
+	if err := New(catalogue).Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.Game != nil {
+		t.Errorf("Game = %+v, want nil for an inactive game", event.Game)
+	}
+}
+
+func TestCatalogueReloadErrorKeepsGames(t *testing.T) {
+	store := newMemStore(casino.Game{ID: 200, Title: "Gates of Olympus", Active: true})
+	catalogue := NewCatalogue(store)
+	if err := catalogue.Reload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	store.err = errors.New("connection refused")
+	if err := catalogue.Reload(context.Background()); err == nil {
+		t.Fatal("Reload() succeeded, want error")
+	}
+	if _, ok := catalogue.Get(200); !ok {
+		t.Error("failed reload dropped the catalogue")
+	}
+}
+
+func TestCatalogueSaveValidates(t *testing.T) {
+	catalogue := NewCatalogue(newMemStore())
+	invalid := []casino.Game{
+		{ID: 0, Title: "No id"},
+		{ID: 1},
+		{ID: 1, Title: "Bad RTP", RTP: 101},
+		{ID: 1, Title: "Bad volatility", Volatility: "extreme"},
+	}
+	for _, g := range invalid {
+		if err := catalogue.Save(context.Background(), g); !errors.Is(err, ErrInvalidGame) {
+			t.Errorf("Save(%+v) error = %v, want ErrInvalidGame", g, err)
+		}
+	}
+
+	if err := catalogue.Save(context.Background(), casino.Game{ID: 300, Title: "New", Active: true}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if g, ok := catalogue.Get(300); !ok || g.Title != "New" {
+		t.Errorf("Get(300) = %+v, %v; want the saved game without a reload", g, ok)
+	}
+}
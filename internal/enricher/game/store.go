@@ -0,0 +1,78 @@
+package game
+
+import (
+    "context"
+    "database/sql"
+    "fmt"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Store persists the game catalogue.
+type Store interface {
+    List(ctx context.Context) ([]casino.Game, error)
+    Save(ctx context.Context, game casino.Game) error
+}
+
+// PostgresStore keeps the catalogue in the games table (migration 00006).
+type PostgresStore struct {
+    db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+    return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) List(ctx context.Context) ([]casino.Game, error) {
+    rows, err := s.db.QueryContext(ctx,
+        `SELECT id, title, provider, category, rtp, volatility, active
+         FROM games
+         ORDER BY id`)
+    if err != nil {
+        return nil, fmt.Errorf("failed to query games: %w", err)
+    }
+    defer rows.Close()
+
+    var games []casino.Game
+    for rows.Next() {
+        var g casino.Game
+        var provider, category, volatility sql.NullString
+        var rtp sql.NullFloat64
+        if err := rows.Scan(&g.ID, &g.Title, &provider, &category, &rtp, &volatility, &g.Active); err != nil {
+            return nil, fmt.Errorf("failed to scan game: %w", err)
+        }
+        g.Provider = provider.String
+        g.Category = category.String
+        g.RTP = rtp.Float64
+        g.Volatility = volatility.String
+        games = append(games, g)
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to query games: %w", err)
+    }
+    return games, nil
+}
+
+func (s *PostgresStore) Save(ctx context.Context, g casino.Game) error {
+    _, err := s.db.ExecContext(ctx,
+        `INSERT INTO games (id, title, provider, category, rtp, volatility, active)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)
+         ON CONFLICT (id) DO UPDATE
+         SET title = EXCLUDED.title,
+             provider = EXCLUDED.provider,
+             category = EXCLUDED.category,
+             rtp = EXCLUDED.rtp,
+             volatility = EXCLUDED.volatility,
+             active = EXCLUDED.active,
+             updated_at = now()`,
+        g.ID, g.Title, nullString(g.Provider), nullString(g.Category),
+        sql.NullFloat64{Float64: g.RTP, Valid: g.RTP != 0}, nullString(g.Volatility), g.Active,
+    )
+    if err != nil {
+        return fmt.Errorf("failed to save game %d: %w", g.ID, err)
+    }
+    return nil
+}
+
+func nullString(s string) sql.NullString {
+    return sql.NullString{String: s, Valid: s != ""}
+}
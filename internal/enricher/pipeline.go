@@ -0,0 +1,220 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+var errDependencyFailed = errors.New("dependency not produced")
+
+type stage struct {
+	spec     Spec
+	enricher Enricher
+	deps     []int // indices of stages that must succeed first
+	uses     []int // indices of stages that must finish first
+}
+
+// Pipeline runs enrichers in dependency order. Stages whose dependencies
+// are satisfied at the same time form a level and run concurrently.
+type Pipeline struct {
+	stages []stage
+	levels [][]int
+}
+
+// NewPipeline builds a pipeline from enrichers in registration order. It
+// fails if a stage needs a field nobody produces, two stages produce the
+// same field, or dependencies form a cycle.
+func NewPipeline(enrichers ...Enricher) (*Pipeline, error) {
+	p := &Pipeline{stages: make([]stage, len(enrichers))}
+	producers := make(map[string]int)
+
+	for i, e := range enrichers {
+		spec, declared := specOf(e)
+		p.stages[i] = stage{spec: spec, enricher: e}
+
+		if !declared {
+			// Undeclared enrichers keep the old positional behaviour.
+			for j := 0; j < i; j++ {
+				p.stages[i].deps = append(p.stages[i].deps, j)
+			}
+		}
+
+		for _, f := range spec.Produces {
+			if j, ok := producers[f]; ok {
+				return nil, fmt.Errorf("field %q produced by both %s and %s", f, p.stages[j].spec.Name, spec.Name)
+			}
+			producers[f] = i
+		}
+	}
+
+	for i := range p.stages {
+		for _, f := range p.stages[i].spec.Needs {
+			j, ok := producers[f]
+			if !ok {
+				return nil, fmt.Errorf("stage %s needs field %q which no stage produces", p.stages[i].spec.Name, f)
+			}
+			p.stages[i].deps = append(p.stages[i].deps, j)
+		}
+		for _, f := range p.stages[i].spec.Uses {
+			// Optional fields nobody produces are simply absent.
+			if j, ok := producers[f]; ok {
+				p.stages[i].uses = append(p.stages[i].uses, j)
+			}
+		}
+	}
+
+	levels, err := p.sort()
+	if err != nil {
+		return nil, err
+	}
+	p.levels = levels
+	return p, nil
+}
+
+func specOf(e Enricher) (Spec, bool) {
+	if s, ok := e.(Specifier); ok {
+		spec := s.Spec()
+		if spec.Name == "" {
+			spec.Name = fmt.Sprintf("%T", e)
+		}
+		return spec, true
+	}
+	return Spec{Name: fmt.Sprintf("%T", e), Required: true}, false
+}
+
+// sort groups stages into levels with Kahn's algorithm.
+func (p *Pipeline) sort() ([][]int, error) {
+	pending := make([]int, len(p.stages))
+	dependents := make([][]int, len(p.stages))
+	for i, s := range p.stages {
+		pending[i] = len(s.deps) + len(s.uses)
+		for _, d := range s.deps {
+			dependents[d] = append(dependents[d], i)
+		}
+		for _, d := range s.uses {
+			dependents[d] = append(dependents[d], i)
+		}
+	}
+
+	var levels [][]int
+	var ready []int
+	for i, n := range pending {
+		if n == 0 {
+			ready = append(ready, i)
+		}
+	}
+
+	sorted := 0
+	for len(ready) > 0 {
+		levels = append(levels, ready)
+		sorted += len(ready)
+
+		var next []int
+		for _, i := range ready {
+			for _, d := range dependents[i] {
+				pending[d]--
+				if pending[d] == 0 {
+					next = append(next, d)
+				}
+			}
+		}
+		ready = next
+	}
+
+	if sorted != len(p.stages) {
+		return nil, errors.New("enricher dependencies form a cycle")
+	}
+	return levels, nil
+}
+
+// Stages returns the stage specs in registration order.
+func (p *Pipeline) Stages() []Spec {
+	specs := make([]Spec, len(p.stages))
+	for i, s := range p.stages {
+		specs[i] = s.spec
+	}
+	return specs
+}
+
+// Run enriches event and returns one outcome per stage in registration
+// order. The error is a *StageError for the first required stage that
+// failed, timed out or was skipped; later stages are then skipped.
+func (p *Pipeline) Run(ctx context.Context, event *casino.Event) ([]Outcome, error) {
+	outcomes := make([]Outcome, len(p.stages))
+	for i, s := range p.stages {
+		outcomes[i] = Outcome{Stage: s.spec.Name, Required: s.spec.Required, Status: StatusSkipped}
+	}
+
+	var failed *StageError
+	for _, level := range p.levels {
+		if failed != nil {
+			break
+		}
+
+		var wg sync.WaitGroup
+		for _, i := range level {
+			if !p.depsOK(i, outcomes) {
+				outcomes[i].Err = errDependencyFailed
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				outcomes[i] = p.runStage(ctx, i, event)
+			}(i)
+		}
+		wg.Wait()
+
+		for _, i := range level {
+			if o := outcomes[i]; o.Required && o.Status != StatusOK && failed == nil {
+				failed = &StageError{Stage: o.Stage, Err: o.Err}
+			}
+		}
+	}
+
+	if failed != nil {
+		return outcomes, failed
+	}
+	return outcomes, nil
+}
+
+func (p *Pipeline) depsOK(i int, outcomes []Outcome) bool {
+	for _, d := range p.stages[i].deps {
+		if outcomes[d].Status != StatusOK {
+			return false
+		}
+	}
+	return true
+}
+
+func (p *Pipeline) runStage(ctx context.Context, i int, event *casino.Event) Outcome {
+	s := p.stages[i]
+	o := Outcome{Stage: s.spec.Name, Required: s.spec.Required}
+
+	if s.spec.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.spec.Timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+	err := s.enricher.Enrich(ctx, event)
+	o.Duration = time.Since(start)
+
+	switch {
+	case err == nil:
+		o.Status = StatusOK
+	case errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded):
+		o.Status = StatusTimeout
+		o.Err = err
+	default:
+		o.Status = StatusFailed
+		o.Err = err
+	}
+	return o
+}
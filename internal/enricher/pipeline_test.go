@@ -0,0 +1,253 @@
+package enricher
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// stageFunc is a test enricher with a configurable Spec.
+type stageFunc struct {
+	spec Spec
+	fn   func(context.Context, *casino.Event) error
+}
+
+func (s *stageFunc) Spec() Spec { return s.spec }
+
+func (s *stageFunc) Enrich(ctx context.Context, e *casino.Event) error {
+	if s.fn == nil {
+		return nil
+	}
+	return s.fn(ctx, e)
+}
+
+// plain has no Spec and must keep positional behaviour.
+type plain struct {
+	fn func(context.Context, *casino.Event) error
+}
+
+func (p *plain) Enrich(ctx context.Context, e *casino.Event) error { return p.fn(ctx, e) }
+
+func statuses(outcomes []Outcome) map[string]Status {
+	m := make(map[string]Status)
+	for _, o := range outcomes {
+		m[o.Stage] = o.Status
+	}
+	return m
+}
+
+func TestPipelineDependencyOrder(t *testing.T) {
+	var mu sync.Mutex
+	var order []string
+	record := func(name string) func(context.Context, *casino.Event) error {
+		return func(context.Context, *casino.Event) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	// Registered in reverse; dependencies must still be honoured.
+	p, err := NewPipeline(
+		&stageFunc{spec: Spec{Name: "description", Needs: []string{FieldAmountEUR, FieldPlayer}, Produces: []string{FieldDescription}}, fn: record("description")},
+		&stageFunc{spec: Spec{Name: "player", Produces: []string{FieldPlayer}}, fn: record("player")},
+		&stageFunc{spec: Spec{Name: "currency", Produces: []string{FieldAmountEUR}}, fn: record("currency")},
+	)
+	if err != nil {
+		t.Fatalf("NewPipeline() error = %v", err)
+	}
+
+	outcomes, err := p.Run(context.Background(), &casino.Event{})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(order) != 3 || order[2] != "description" {
+		t.Fatalf("Run order = %v, want description last", order)
+	}
+	for stage, status := range statuses(outcomes) {
+		if status != StatusOK {
+			t.Errorf("Stage %s status = %s, want ok", stage, status)
+		}
+	}
+}
+
+func TestPipelineRunsIndependentStagesConcurrently(t *testing.T) {
+	started := make(chan struct{}, 2)
+	both := func(ctx context.Context, e *casino.Event) error {
+		started <- struct{}{}
+		// Each stage waits for the other to start; run sequentially, the
+		// first one would time out.
+		for len(started) < 2 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			time.Sleep(time.Millisecond)
+		}
+		return nil
+	}
+
+	p, err := NewPipeline(
+		&stageFunc{spec: Spec{Name: "a", Required: true, Timeout: 500 * time.Millisecond}, fn: both},
+		&stageFunc{spec: Spec{Name: "b", Required: true, Timeout: 500 * time.Millisecond}, fn: both},
+	)
+	if err != nil {
+		t.Fatalf("NewPipeline() error = %v", err)
+	}
+
+	if _, err := p.Run(context.Background(), &casino.Event{}); err != nil {
+		t.Fatalf("Run() error = %v, independent stages did not overlap", err)
+	}
+}
+
+func TestPipelineFailures(t *testing.T) {
+	boom := errors.New("boom")
+	fail := func(context.Context, *casino.Event) error { return boom }
+	slow := func(ctx context.Context, e *casino.Event) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	tests := []struct {
+		name      string
+		stages    []Enricher
+		wantErr   string // failing stage, empty for none
+		wantState map[string]Status
+	}{
+		{
+			name: "best-effort failure continues",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "player", Produces: []string{FieldPlayer}}, fn: fail},
+				&stageFunc{spec: Spec{Name: "currency", Required: true, Produces: []string{FieldAmountEUR}}},
+			},
+			wantState: map[string]Status{"player": StatusFailed, "currency": StatusOK},
+		},
+		{
+			name: "required failure skips dependents",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "currency", Required: true, Produces: []string{FieldAmountEUR}}, fn: fail},
+				&stageFunc{spec: Spec{Name: "description", Needs: []string{FieldAmountEUR}}},
+			},
+			wantErr:   "currency",
+			wantState: map[string]Status{"currency": StatusFailed, "description": StatusSkipped},
+		},
+		{
+			name: "required stage skipped by best-effort dependency",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "player", Produces: []string{FieldPlayer}}, fn: fail},
+				&stageFunc{spec: Spec{Name: "description", Required: true, Needs: []string{FieldPlayer}}},
+			},
+			wantErr:   "description",
+			wantState: map[string]Status{"player": StatusFailed, "description": StatusSkipped},
+		},
+		{
+			name: "used field failure still runs stage",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "player", Produces: []string{FieldPlayer}}, fn: fail},
+				&stageFunc{spec: Spec{Name: "description", Required: true, Uses: []string{FieldPlayer, "unproduced"}}},
+			},
+			wantState: map[string]Status{"player": StatusFailed, "description": StatusOK},
+		},
+		{
+			name: "timeout",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "player", Required: true, Timeout: 10 * time.Millisecond}, fn: slow},
+			},
+			wantErr:   "player",
+			wantState: map[string]Status{"player": StatusTimeout},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := NewPipeline(tt.stages...)
+			if err != nil {
+				t.Fatalf("NewPipeline() error = %v", err)
+			}
+
+			outcomes, err := p.Run(context.Background(), &casino.Event{})
+			if tt.wantErr == "" && err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if tt.wantErr != "" {
+				var se *StageError
+				if !errors.As(err, &se) || se.Stage != tt.wantErr {
+					t.Fatalf("Run() error = %v, want StageError for %s", err, tt.wantErr)
+				}
+			}
+
+			got := statuses(outcomes)
+			for stage, want := range tt.wantState {
+				if got[stage] != want {
+					t.Errorf("Stage %s status = %s, want %s", stage, got[stage], want)
+				}
+			}
+		})
+	}
+}
+
+func TestPipelineUndeclaredEnrichersRunInOrder(t *testing.T) {
+	var order []int
+	mk := func(i int) *plain {
+		return &plain{fn: func(context.Context, *casino.Event) error {
+			order = append(order, i) // no lock needed: stages must be sequential
+			return nil
+		}}
+	}
+
+	p, err := NewPipeline(mk(1), mk(2), mk(3))
+	if err != nil {
+		t.Fatalf("NewPipeline() error = %v", err)
+	}
+	if _, err := p.Run(context.Background(), &casino.Event{}); err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(order) != 3 || order[0] != 1 || order[1] != 2 || order[2] != 3 {
+		t.Fatalf("Run order = %v, want [1 2 3]", order)
+	}
+
+	specs := p.Stages()
+	if !specs[0].Required || specs[0].Name != "*enricher.plain" {
+		t.Errorf("Default spec = %+v, want required stage named after its type", specs[0])
+	}
+}
+
+func TestNewPipelineErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		stages []Enricher
+	}{
+		{
+			name: "missing producer",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "description", Needs: []string{FieldPlayer}}},
+			},
+		},
+		{
+			name: "duplicate producer",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "a", Produces: []string{FieldPlayer}}},
+				&stageFunc{spec: Spec{Name: "b", Produces: []string{FieldPlayer}}},
+			},
+		},
+		{
+			name: "cycle",
+			stages: []Enricher{
+				&stageFunc{spec: Spec{Name: "a", Needs: []string{"y"}, Produces: []string{"x"}}},
+				&stageFunc{spec: Spec{Name: "b", Needs: []string{"x"}, Produces: []string{"y"}}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewPipeline(tt.stages...); err == nil {
+				t.Fatal("NewPipeline() expected error")
+			}
+		})
+	}
+}
@@ -3,11 +3,43 @@ package player
 import (
     "context"
     "database/sql"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
 )
 
+// playerColumns are the players columns read into a casino.Player, in the
+// order scanPlayer expects them.
+const playerColumns = `email, last_signed_in_at, country, preferred_currency, vip_tier, registered_at, self_excluded, account_status`
+
+// scanPlayer scans a row selected with playerColumns, after any leading
+// columns given in lead (such as the id in batched queries).
+func scanPlayer(row Scanner, lead ...interface{}) (casino.Player, error) {
+    var p casino.Player
+    var signedIn, registered sql.NullTime
+    var country, currency, tier, status sql.NullString
+    var selfExcluded sql.NullBool
+
+    dest := append(lead, &p.Email, &signedIn, &country, &currency, &tier, &registered, &selfExcluded, &status)
+    if err := row.Scan(dest...); err != nil {
+        return casino.Player{}, err
+    }
+
+    p.LastSignedInAt = signedIn.Time
+    p.Country = country.String
+    p.PreferredCurrency = currency.String
+    p.VIPTier = tier.String
+    if registered.Valid {
+        t := registered.Time
+        p.RegisteredAt = &t
+    }
+    p.SelfExcluded = selfExcluded.Bool
+    p.AccountStatus = status.String
+    return p, nil
+}
+
 // DB interface allows us to mock the database for testing
 type DB interface {
     QueryRowContext(ctx context.Context, query string, args ...interface{}) Scanner
+    QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error)
     Close() error
 }
 
@@ -16,6 +48,14 @@ type Scanner interface {
     Scan(dest ...interface{}) error
 }
 
+// Rows interface matches the parts of sql.Rows used by batched lookups
+type Rows interface {
+    Scanner
+    Next() bool
+    Err() error
+    Close() error
+}
+
 // sqlDB adapts sql.DB to our DB interface
 type sqlDB struct {
     *sql.DB
@@ -25,6 +65,10 @@ func (db *sqlDB) QueryRowContext(ctx context.Context, query string, args ...inte
     return db.DB.QueryRowContext(ctx, query, args...)
 }
 
+func (db *sqlDB) QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+    return db.DB.QueryContext(ctx, query, args...)
+}
+
 func (db *sqlDB) Close() error {
     return db.DB.Close()
-} 
\ No newline at end of file
+}
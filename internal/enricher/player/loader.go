@@ -0,0 +1,153 @@
+package player
+
+import (
+    "context"
+    "fmt"
+    "sync"
+    "time"
+    "github.com/lib/pq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// Batching defaults: flush after 5ms or 50 distinct players, whichever
+// comes first. Batches are further capped by the number of concurrent
+// lookups, see SetBatching.
+const (
+    DefaultBatchSize = 50
+    DefaultBatchWait = 5 * time.Millisecond
+)
+
+// loader collects player IDs requested within a short window and resolves
+// them with one query. It never caches: every batch reads the database
+// fresh, and a player requested again after a flush is queried again.
+type loader struct {
+    db       DB
+    maxSize  int
+    workers  int
+    maxWait  time.Duration
+    timeout  time.Duration
+
+    mu      sync.Mutex
+    pending *batch
+}
+
+type batch struct {
+    opened  time.Time
+    waiters map[int][]chan<- loadResult
+    waiting int
+    ids     []int64
+    timer   *time.Timer
+    flushed bool
+}
+
+type loadResult struct {
+    player casino.Player
+    found  bool
+    err    error
+}
+
+func newLoader(db DB, maxSize, workers int, maxWait, timeout time.Duration) *loader {
+    if maxSize > workers {
+        maxSize = workers
+    }
+    return &loader{db: db, maxSize: maxSize, workers: workers, maxWait: maxWait, timeout: timeout}
+}
+
+// load returns the player with the given id, waiting for the batch it
+// joins to be flushed. found is false if the player does not exist. The
+// batch is flushed as soon as it is full or every worker is waiting on it,
+// as no further lookup can join it then.
+func (l *loader) load(ctx context.Context, id int) (casino.Player, bool, error) {
+    ch := make(chan loadResult, 1)
+
+    l.mu.Lock()
+    b := l.pending
+    if b == nil {
+        b = &batch{opened: time.Now(), waiters: make(map[int][]chan<- loadResult)}
+        b.timer = time.AfterFunc(l.maxWait, func() { l.flush(b) })
+        l.pending = b
+    }
+    if _, ok := b.waiters[id]; !ok {
+        b.ids = append(b.ids, int64(id))
+    }
+    b.waiters[id] = append(b.waiters[id], ch)
+    b.waiting++
+    full := len(b.ids) >= l.maxSize || b.waiting >= l.workers
+    l.mu.Unlock()
+
+    if full {
+        go l.flush(b)
+    }
+
+    select {
+    case r := <-ch:
+        return r.player, r.found, r.err
+    case <-ctx.Done():
+        // The batch still runs for the other waiters.
+        return casino.Player{}, false, ctx.Err()
+    }
+}
+
+// flush detaches b, queries all its ids at once and hands each waiter its
+// row. It runs once per batch, from whichever of the timer or the size
+// limit fires first.
+func (l *loader) flush(b *batch) {
+    l.mu.Lock()
+    if b.flushed {
+        l.mu.Unlock()
+        return
+    }
+    b.flushed = true
+    b.timer.Stop()
+    if l.pending == b {
+        l.pending = nil
+    }
+    l.mu.Unlock()
+
+    metrics.PlayerBatchSize.Observe(float64(len(b.ids)))
+    metrics.PlayerBatchWait.Observe(time.Since(b.opened).Seconds())
+
+    // The batch is shared, so one caller's cancellation must not fail the
+    // others; it gets its own timeout instead.
+    ctx, cancel := context.WithTimeout(context.Background(), l.timeout)
+    defer cancel()
+
+    start := time.Now()
+    players, err := l.query(ctx, b.ids)
+    metrics.PlayerBatchQueryDuration.Observe(time.Since(start).Seconds())
+
+    for id, waiters := range b.waiters {
+        p, found := players[id]
+        for _, ch := range waiters {
+            ch <- loadResult{player: p, found: found, err: err}
+        }
+    }
+}
+
+func (l *loader) query(ctx context.Context, ids []int64) (map[int]casino.Player, error) {
+    rows, err := l.db.QueryContext(ctx,
+        `SELECT id, `+playerColumns+`
+         FROM players 
+         WHERE id = ANY($1)`,
+        pq.Int64Array(ids),
+    )
+    if err != nil {
+        return nil, fmt.Errorf("failed to query player data: %w", err)
+    }
+    defer rows.Close()
+
+    players := make(map[int]casino.Player, len(ids))
+    for rows.Next() {
+        var id int
+        p, err := scanPlayer(rows, &id)
+        if err != nil {
+            return nil, fmt.Errorf("failed to scan player data: %w", err)
+        }
+        players[id] = p
+    }
+    if err := rows.Err(); err != nil {
+        return nil, fmt.Errorf("failed to query player data: %w", err)
+    }
+    return players, nil
+}
@@ -0,0 +1,174 @@
+package player
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// batchDB answers ANY($1) queries from a fixed player table and records
+// the ids of every query.
+type batchDB struct {
+	mu      sync.Mutex
+	players map[int]string
+	err     error
+	queries [][]int64
+}
+
+func (b *batchDB) mock() *mockDB {
+	return &mockDB{
+		queryRowsFunc: func(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+			ids := []int64(args[0].(pq.Int64Array))
+
+			b.mu.Lock()
+			defer b.mu.Unlock()
+			b.queries = append(b.queries, ids)
+			if b.err != nil {
+				return nil, b.err
+			}
+			rows := &mockRows{}
+			for _, id := range ids {
+				if email, ok := b.players[int(id)]; ok {
+					rows.players = append(rows.players, mockPlayer{id: int(id), email: email})
+				}
+			}
+			return rows, nil
+		},
+	}
+}
+
+func (b *batchDB) queryCount() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.queries)
+}
+
+// enrichAll enriches one event per id concurrently.
+func enrichAll(t *testing.T, svc *Service, ids ...int) ([]casino.Event, []error) {
+	t.Helper()
+	events := make([]casino.Event, len(ids))
+	errs := make([]error, len(ids))
+	var wg sync.WaitGroup
+	for i, id := range ids {
+		events[i].PlayerID = id
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs[i] = svc.Enrich(context.Background(), &events[i])
+		}(i)
+	}
+	wg.Wait()
+	return events, errs
+}
+
+func TestLoaderBatchesConcurrentLookups(t *testing.T) {
+	db := &batchDB{players: map[int]string{1: "one@example.com", 2: "two@example.com", 3: "three@example.com"}}
+	svc := &Service{db: db.mock()}
+	svc.SetBatching(50, 20*time.Millisecond, 8)
+
+	events, errs := enrichAll(t, svc, 1, 2, 3, 2, 404)
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("Enrich(%d) error = %v", events[i].PlayerID, err)
+		}
+	}
+
+	if n := db.queryCount(); n != 1 {
+		t.Fatalf("queries = %d, want 1", n)
+	}
+	if ids := db.queries[0]; len(ids) != 4 {
+		t.Errorf("batch ids = %v, want 4 distinct ids", ids)
+	}
+	want := map[int]string{1: "one@example.com", 2: "two@example.com", 3: "three@example.com", 404: ""}
+	for _, e := range events {
+		if e.Player.Email != want[e.PlayerID] {
+			t.Errorf("player %d email = %q, want %q", e.PlayerID, e.Player.Email, want[e.PlayerID])
+		}
+	}
+}
+
+func TestLoaderFlushesFullBatchWithoutWaiting(t *testing.T) {
+	db := &batchDB{players: map[int]string{1: "a", 2: "b"}}
+	svc := &Service{db: db.mock()}
+	svc.SetBatching(2, time.Hour, 8)
+
+	start := time.Now()
+	_, errs := enrichAll(t, svc, 1, 2)
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("full batch waited %v for the timer", elapsed)
+	}
+}
+
+func TestLoaderFlushesWhenEveryWorkerWaits(t *testing.T) {
+	db := &batchDB{players: map[int]string{1: "a", 2: "b", 3: "c"}}
+	svc := &Service{db: db.mock()}
+	svc.SetBatching(DefaultBatchSize, time.Hour, 3)
+
+	// Three workers can never fill a batch of 50: once all of them wait,
+	// the batch is flushed instead of waiting for the timer.
+	start := time.Now()
+	_, errs := enrichAll(t, svc, 1, 2, 2)
+	for _, err := range errs {
+		if err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("batch waited %v for the timer with every worker waiting", elapsed)
+	}
+	if n := db.queryCount(); n != 1 {
+		t.Errorf("queries = %d, want 1", n)
+	}
+}
+
+func TestLoaderDoesNotCache(t *testing.T) {
+	db := &batchDB{players: map[int]string{1: "old@example.com"}}
+	svc := &Service{db: db.mock()}
+	svc.SetBatching(10, time.Millisecond, 8)
+
+	enrichAll(t, svc, 1)
+	db.mu.Lock()
+	db.players[1] = "new@example.com"
+	db.mu.Unlock()
+	events, _ := enrichAll(t, svc, 1)
+
+	if n := db.queryCount(); n != 2 {
+		t.Errorf("queries = %d, want 2", n)
+	}
+	if events[0].Player.Email != "new@example.com" {
+		t.Errorf("email = %q, want the updated row", events[0].Player.Email)
+	}
+}
+
+func TestLoaderErrors(t *testing.T) {
+	db := &batchDB{err: errors.New("database connection lost")}
+	svc := &Service{db: db.mock()}
+	svc.SetBatching(10, 5*time.Millisecond, 8)
+
+	_, errs := enrichAll(t, svc, 1, 2)
+	for _, err := range errs {
+		if err == nil {
+			t.Error("Enrich() expected error from failed batch")
+		}
+	}
+
+	// A cancelled caller returns at once; the batch still serves others.
+	db.err = nil
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	event := casino.Event{PlayerID: 1}
+	if err := svc.Enrich(ctx, &event); !errors.Is(err, context.Canceled) {
+		t.Errorf("Enrich() error = %v, want context.Canceled", err)
+	}
+}
@@ -2,18 +2,24 @@ package player
 
 import (
 	"context"
+	"database/sql"
 	"time"
 )
 
 // mockDB implements DB interface for testing
 type mockDB struct {
-	queryFunc func(ctx context.Context, query string, args ...interface{}) Scanner
+	queryFunc     func(ctx context.Context, query string, args ...interface{}) Scanner
+	queryRowsFunc func(ctx context.Context, query string, args ...interface{}) (Rows, error)
 }
 
 func (m *mockDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) Scanner {
 	return m.queryFunc(ctx, query, args...)
 }
 
+func (m *mockDB) QueryContext(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+	return m.queryRowsFunc(ctx, query, args...)
+}
+
 func (m *mockDB) Close() error {
 	return nil
 }
@@ -21,6 +27,7 @@ func (m *mockDB) Close() error {
 type mockRow struct {
 	email    string
 	signedIn time.Time
+	profile  mockProfile
 	err      error
 }
 
@@ -29,8 +36,87 @@ func (m *mockRow) Scan(dest ...interface{}) error {
 		return m.err
 	}
 	emailPtr := dest[0].(*string)
-	timePtr := dest[1].(*time.Time)
 	*emailPtr = m.email
-	*timePtr = m.signedIn
+	setTime(dest[1], m.signedIn)
+	m.profile.scan(dest[2:])
 	return nil
-} 
\ No newline at end of file
+}
+
+// setTime scans t into a *time.Time or *sql.NullTime destination.
+func setTime(dest interface{}, t time.Time) {
+	switch d := dest.(type) {
+	case *time.Time:
+		*d = t
+	case *sql.NullTime:
+		*d = sql.NullTime{Time: t, Valid: !t.IsZero()}
+	}
+}
+
+// mockProfile holds the profile columns that follow email and
+// last_signed_in_at in playerColumns.
+type mockProfile struct {
+	country      string
+	currency     string
+	vipTier      string
+	registered   time.Time
+	selfExcluded bool
+	status       string
+}
+
+func (p mockProfile) scan(dest []interface{}) {
+	if len(dest) < 6 {
+		return
+	}
+	setString(dest[0], p.country)
+	setString(dest[1], p.currency)
+	setString(dest[2], p.vipTier)
+	setTime(dest[3], p.registered)
+	switch d := dest[4].(type) {
+	case *bool:
+		*d = p.selfExcluded
+	case *sql.NullBool:
+		*d = sql.NullBool{Bool: p.selfExcluded, Valid: true}
+	}
+	setString(dest[5], p.status)
+}
+
+// setString scans s into a *string or *sql.NullString destination, with
+// the empty string standing for NULL.
+func setString(dest interface{}, s string) {
+	switch d := dest.(type) {
+	case *string:
+		*d = s
+	case *sql.NullString:
+		*d = sql.NullString{String: s, Valid: s != ""}
+	}
+}
+
+type mockPlayer struct {
+	id       int
+	email    string
+	signedIn time.Time
+	profile  mockProfile
+}
+
+// mockRows iterates over players like sql.Rows.
+type mockRows struct {
+	players []mockPlayer
+	pos     int
+}
+
+func (m *mockRows) Next() bool {
+	m.pos++
+	return m.pos <= len(m.players)
+}
+
+func (m *mockRows) Scan(dest ...interface{}) error {
+	p := m.players[m.pos-1]
+	*dest[0].(*int) = p.id
+	*dest[1].(*string) = p.email
+	setTime(dest[2], p.signedIn)
+	p.profile.scan(dest[3:])
+	return nil
+}
+
+func (m *mockRows) Err() error   { return nil }
+func (m *mockRows) Close() error { return nil }
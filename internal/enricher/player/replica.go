@@ -0,0 +1,217 @@
+package player
+
+import (
+    "context"
+    "database/sql"
+    "encoding/json"
+    "fmt"
+    "log"
+    "sync"
+    "time"
+    "github.com/lib/pq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// NotifyChannel is the channel the players trigger notifies on.
+const NotifyChannel = "players_changed"
+
+// How often an idle listener pings the server to detect dead connections.
+const replicaPingInterval = 90 * time.Second
+
+// replica is an in-memory copy of the players table kept current by
+// LISTEN/NOTIFY. It is not a cache: there is no TTL, every change
+// notification re-reads the row, and whenever the listener connection is
+// down the replica reports itself not ready so lookups go to the database.
+type replica struct {
+    db DB
+
+    mu        sync.RWMutex
+    players   map[int]casino.Player
+    ready     bool // loaded and in sync with notifications
+    connected bool
+}
+
+type playerChange struct {
+    Op string `json:"op"` // INSERT, UPDATE, DELETE or TRUNCATE
+    ID int    `json:"id"`
+}
+
+func newReplica(db DB) *replica {
+    return &replica{db: db, players: make(map[int]casino.Player)}
+}
+
+// get returns the player with the given id. ok is false while the replica
+// is not ready, in which case the caller must query the database.
+func (r *replica) get(id int) (p casino.Player, found, ok bool) {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    if !r.ready {
+        return casino.Player{}, false, false
+    }
+    p, found = r.players[id]
+    return p, found, true
+}
+
+func (r *replica) setReady(ready bool) {
+    r.mu.Lock()
+    r.ready = ready
+    r.mu.Unlock()
+    if ready {
+        metrics.PlayerReplicaReady.Set(1)
+    } else {
+        metrics.PlayerReplicaReady.Set(0)
+    }
+}
+
+func (r *replica) isReady() bool {
+    r.mu.RLock()
+    defer r.mu.RUnlock()
+    return r.ready
+}
+
+// reload replaces the replica with a full read of the players table.
+func (r *replica) reload(ctx context.Context) error {
+    rows, err := r.db.QueryContext(ctx, `SELECT id, `+playerColumns+` FROM players`)
+    if err != nil {
+        return fmt.Errorf("failed to load players: %w", err)
+    }
+    defer rows.Close()
+
+    players := make(map[int]casino.Player)
+    for rows.Next() {
+        var id int
+        p, err := scanPlayer(rows, &id)
+        if err != nil {
+            return fmt.Errorf("failed to scan player: %w", err)
+        }
+        players[id] = p
+    }
+    if err := rows.Err(); err != nil {
+        return fmt.Errorf("failed to load players: %w", err)
+    }
+
+    r.mu.Lock()
+    r.players = players
+    r.ready = r.connected
+    ready := r.ready
+    r.mu.Unlock()
+
+    metrics.PlayerReplicaSize.Set(float64(len(players)))
+    if ready {
+        metrics.PlayerReplicaReady.Set(1)
+    }
+    log.Printf("Player replica loaded %d players", len(players))
+    return nil
+}
+
+// apply handles one change notification by re-reading the named row.
+func (r *replica) apply(ctx context.Context, payload string) error {
+    var change playerChange
+    if err := json.Unmarshal([]byte(payload), &change); err != nil {
+        return fmt.Errorf("invalid player notification %q: %w", payload, err)
+    }
+    metrics.PlayerReplicaNotifications.WithLabelValues(change.Op).Inc()
+
+    switch change.Op {
+    case "TRUNCATE":
+        r.mu.Lock()
+        r.players = make(map[int]casino.Player)
+        r.mu.Unlock()
+        metrics.PlayerReplicaSize.Set(0)
+        return nil
+    case "DELETE":
+        r.remove(change.ID)
+        return nil
+    }
+
+    p, err := scanPlayer(r.db.QueryRowContext(ctx,
+        `SELECT `+playerColumns+`
+         FROM players
+         WHERE id = $1`,
+        change.ID,
+    ))
+    if err == sql.ErrNoRows {
+        // Deleted again before we read it; the DELETE notification follows.
+        r.remove(change.ID)
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to read changed player %d: %w", change.ID, err)
+    }
+
+    r.mu.Lock()
+    r.players[change.ID] = p
+    size := len(r.players)
+    r.mu.Unlock()
+    metrics.PlayerReplicaSize.Set(float64(size))
+    return nil
+}
+
+func (r *replica) remove(id int) {
+    r.mu.Lock()
+    delete(r.players, id)
+    size := len(r.players)
+    r.mu.Unlock()
+    metrics.PlayerReplicaSize.Set(float64(size))
+}
+
+// listenerEvent tracks the listener connection. Lookups fall back to the
+// database from the moment it drops until a reload after reconnecting.
+func (r *replica) listenerEvent(ev pq.ListenerEventType, err error) {
+    switch ev {
+    case pq.ListenerEventConnected, pq.ListenerEventReconnected:
+        r.mu.Lock()
+        r.connected = true
+        r.mu.Unlock()
+    case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+        if err != nil {
+            log.Printf("Player replica listener disconnected, using direct queries: %v", err)
+        }
+        r.mu.Lock()
+        r.connected = false
+        r.mu.Unlock()
+        r.setReady(false)
+    }
+}
+
+// run applies notifications until ctx is done. A nil notification means
+// the listener reconnected and changes may have been missed, so the whole
+// table is reloaded; so is a replica that fell out of sync after an error.
+func (r *replica) run(ctx context.Context, notify <-chan *pq.Notification, ping func() error) {
+    ticker := time.NewTicker(replicaPingInterval)
+    defer ticker.Stop()
+
+    for {
+        select {
+        case <-ctx.Done():
+            return
+
+        case n := <-notify:
+            if n == nil {
+                r.resync(ctx)
+                continue
+            }
+            if err := r.apply(ctx, n.Extra); err != nil {
+                log.Printf("Player replica out of sync, using direct queries: %v", err)
+                r.setReady(false)
+                r.resync(ctx)
+            }
+
+        case <-ticker.C:
+            if err := ping(); err != nil {
+                log.Printf("Player replica listener ping failed: %v", err)
+            }
+            if !r.isReady() {
+                r.resync(ctx)
+            }
+        }
+    }
+}
+
+func (r *replica) resync(ctx context.Context) {
+    if err := r.reload(ctx); err != nil {
+        log.Printf("Player replica reload failed, using direct queries: %v", err)
+        r.setReady(false)
+    }
+}
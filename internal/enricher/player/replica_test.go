@@ -0,0 +1,201 @@
+package player
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lib/pq"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// tableDB is a players table behind the DB interface, counting queries.
+type tableDB struct {
+	mu      sync.Mutex
+	players map[int]string
+	err     error
+	queries int
+}
+
+func (d *tableDB) set(id int, email string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if email == "" {
+		delete(d.players, id)
+		return
+	}
+	d.players[id] = email
+}
+
+func (d *tableDB) count() int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.queries
+}
+
+func (d *tableDB) mock() *mockDB {
+	return &mockDB{
+		queryFunc: func(ctx context.Context, query string, args ...interface{}) Scanner {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.queries++
+			if d.err != nil {
+				return &mockRow{err: d.err}
+			}
+			email, ok := d.players[args[0].(int)]
+			if !ok {
+				return &mockRow{err: sql.ErrNoRows}
+			}
+			return &mockRow{email: email}
+		},
+		queryRowsFunc: func(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+			d.mu.Lock()
+			defer d.mu.Unlock()
+			d.queries++
+			if d.err != nil {
+				return nil, d.err
+			}
+			rows := &mockRows{}
+			for id, email := range d.players {
+				rows.players = append(rows.players, mockPlayer{id: id, email: email})
+			}
+			return rows, nil
+		},
+	}
+}
+
+func connectedReplica(t *testing.T, db *tableDB) *replica {
+	t.Helper()
+	r := newReplica(db.mock())
+	r.listenerEvent(pq.ListenerEventConnected, nil)
+	if err := r.reload(context.Background()); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	return r
+}
+
+func TestReplicaServesLookupsWithoutQueries(t *testing.T) {
+	db := &tableDB{players: map[int]string{10: "john@example.com"}}
+	svc := &Service{db: db.mock(), replica: connectedReplica(t, db)}
+	before := db.count()
+
+	for _, id := range []int{10, 10, 99} {
+		event := casino.Event{PlayerID: id}
+		if err := svc.Enrich(context.Background(), &event); err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+		if id == 10 && event.Player.Email != "john@example.com" {
+			t.Errorf("Player.Email = %q, want john@example.com", event.Player.Email)
+		}
+		if id == 99 && event.Player.Email != "" {
+			t.Errorf("Player.Email = %q for missing player", event.Player.Email)
+		}
+	}
+	if n := db.count() - before; n != 0 {
+		t.Errorf("queries = %d, want 0", n)
+	}
+}
+
+func TestReplicaAppliesNotifications(t *testing.T) {
+	db := &tableDB{players: map[int]string{10: "john@example.com", 11: "jane@example.com"}}
+	r := connectedReplica(t, db)
+	ctx := context.Background()
+
+	email := func(id int) string {
+		p, _, ok := r.get(id)
+		if !ok {
+			t.Fatal("replica not ready")
+		}
+		return p.Email
+	}
+
+	db.set(10, "john@new.example.com")
+	db.set(12, "bob@example.com")
+	for _, payload := range []string{`{"op":"UPDATE","id":10}`, `{"op":"INSERT","id":12}`} {
+		if err := r.apply(ctx, payload); err != nil {
+			t.Fatalf("apply(%s) error = %v", payload, err)
+		}
+	}
+	if email(10) != "john@new.example.com" || email(12) != "bob@example.com" {
+		t.Errorf("replica = %v, want updated and inserted rows", r.players)
+	}
+
+	// Notifications are only hints; a row gone by the time it is read is removed.
+	db.set(11, "")
+	if err := r.apply(ctx, `{"op":"UPDATE","id":11}`); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if _, found, _ := r.get(11); found {
+		t.Error("player 11 still in replica after its row disappeared")
+	}
+
+	if err := r.apply(ctx, `{"op":"DELETE","id":12}`); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if _, found, _ := r.get(12); found {
+		t.Error("player 12 still in replica after DELETE")
+	}
+
+	if err := r.apply(ctx, `{"op":"TRUNCATE"}`); err != nil {
+		t.Fatalf("apply() error = %v", err)
+	}
+	if len(r.players) != 0 {
+		t.Errorf("replica has %d players after TRUNCATE", len(r.players))
+	}
+
+	if err := r.apply(ctx, `not json`); err == nil {
+		t.Error("apply() expected error for invalid payload")
+	}
+}
+
+func TestReplicaFallsBackWhenDisconnected(t *testing.T) {
+	db := &tableDB{players: map[int]string{10: "john@example.com"}}
+	r := connectedReplica(t, db)
+	svc := &Service{db: db.mock(), replica: r}
+
+	r.listenerEvent(pq.ListenerEventDisconnected, errors.New("connection reset"))
+	db.set(10, "changed@example.com") // missed while disconnected
+
+	event := casino.Event{PlayerID: 10}
+	if err := svc.Enrich(context.Background(), &event); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if event.Player.Email != "changed@example.com" {
+		t.Errorf("Player.Email = %q, want the direct query result", event.Player.Email)
+	}
+
+	// A reconnect is signalled by a nil notification and reloads the table.
+	notify := make(chan *pq.Notification)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go r.run(ctx, notify, func() error { return nil })
+
+	r.listenerEvent(pq.ListenerEventReconnected, nil)
+	notify <- nil
+
+	deadline := time.Now().Add(time.Second)
+	for !r.isReady() {
+		if time.Now().After(deadline) {
+			t.Fatal("replica not ready after reconnect")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	if p, _, _ := r.get(10); p.Email != "changed@example.com" {
+		t.Errorf("replica email = %q, want the change missed while disconnected", p.Email)
+	}
+}
+
+func TestReplicaNotReadyUntilConnected(t *testing.T) {
+	db := &tableDB{players: map[int]string{10: "john@example.com"}}
+	r := newReplica(db.mock())
+	if err := r.reload(context.Background()); err != nil {
+		t.Fatalf("reload() error = %v", err)
+	}
+	if _, _, ok := r.get(10); ok {
+		t.Error("replica ready without a listener connection")
+	}
+}
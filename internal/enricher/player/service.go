@@ -5,14 +5,20 @@ import (
     "database/sql"
     "fmt"
     "log"
-    _ "github.com/lib/pq"
+    "time"
+    "github.com/lib/pq"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
-    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/exchange"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
 )
 
+// Timeout for the player lookup of a single event.
+const enrichTimeout = 2 * time.Second
+
 type Service struct {
-    db *sql.DB
-    exchange *exchange.Service
+    db      DB
+    loader  *loader
+    replica *replica
 }
 
 func New(dbURL string) (*Service, error) {
@@ -26,42 +32,93 @@ func New(dbURL string) (*Service, error) {
         return nil, fmt.Errorf("failed to ping database: %w", err)
     }
 
-    exchange, err := exchange.New(db)
-    if err != nil {
-        return nil, fmt.Errorf("failed to create exchange service: %w", err)
-    }
-
     return &Service{
-        db: db,
-        exchange: exchange,
+        db: &sqlDB{DB: db},
     }, nil
 }
 
+func (s *Service) Spec() enricher.Spec {
+    return enricher.Spec{
+        Name:     "player",
+        Timeout:  enrichTimeout,
+        Produces: []string{enricher.FieldPlayer},
+    }
+}
+
+// SetBatching makes lookups from concurrent events share one query: IDs
+// requested within maxWait, up to maxSize distinct players, are resolved
+// together. workers is the number of lookups that can run at once, such as
+// the subscriber's worker pool size: a batch never holds more, and is
+// flushed without waiting once all of them have joined it. A maxSize or
+// workers of 1 or less turns batching off.
+func (s *Service) SetBatching(maxSize int, maxWait time.Duration, workers int) {
+    if maxSize <= 1 || workers <= 1 {
+        s.loader = nil
+        return
+    }
+    s.loader = newLoader(s.db, maxSize, workers, maxWait, enrichTimeout)
+}
+
+// EnableReplica serves lookups from an in-memory copy of the players table
+// kept current by the players_changed notifications (see migration
+// 00004). While the listener connection is down or the copy is reloading,
+// lookups fall back to direct queries. The listener stops with ctx.
+func (s *Service) EnableReplica(ctx context.Context, dbURL string) error {
+    r := newReplica(s.db)
+    listener := pq.NewListener(dbURL, time.Second, 30*time.Second, r.listenerEvent)
+    if err := listener.Listen(NotifyChannel); err != nil {
+        listener.Close()
+        return fmt.Errorf("failed to listen on %s: %w", NotifyChannel, err)
+    }
+
+    // Notifications arriving during the load queue up and are applied
+    // after it, re-reading their rows, so none are lost.
+    if err := r.reload(ctx); err != nil {
+        log.Printf("Player replica initial load failed, using direct queries: %v", err)
+    }
+
+    go func() {
+        r.run(ctx, listener.Notify, listener.Ping)
+        listener.Close()
+    }()
+
+    s.replica = r
+    return nil
+}
+
 func (s *Service) Enrich(ctx context.Context, event *casino.Event) error {
-    // First convert amount to EUR regardless of player data
-    if event.Currency != "EUR" {
-        rate, err := s.exchange.GetRate(event.Currency)
-        if err != nil {
-            return fmt.Errorf("failed to get rate: %w", err)
+    if s.replica != nil {
+        if player, found, ok := s.replica.get(event.PlayerID); ok {
+            metrics.PlayerLookups.WithLabelValues("replica").Inc()
+            if !found {
+                log.Printf("No player data found for ID: %d", event.PlayerID)
+                return nil
+            }
+            event.Player = player
+            return nil
         }
+    }
+    metrics.PlayerLookups.WithLabelValues("db").Inc()
 
-        // Simple conversion for all currencies
-        event.AmountEUR = float64(event.Amount) / rate
-
-        log.Printf("Converting %d %s to EUR: amount=%.2f, rate=%.10f", 
-            event.Amount, event.Currency, event.AmountEUR, rate)
-    } else {
-        event.AmountEUR = float64(event.Amount)
+    if s.loader != nil {
+        player, found, err := s.loader.load(ctx, event.PlayerID)
+        if err != nil {
+            return err
+        }
+        if !found {
+            log.Printf("No player data found for ID: %d", event.PlayerID)
+            return nil
+        }
+        event.Player = player
+        return nil
     }
 
-    // Then try to get player data
-    var player casino.Player
-    err := s.db.QueryRowContext(ctx, 
-        `SELECT email, last_signed_in_at 
+    player, err := scanPlayer(s.db.QueryRowContext(ctx, 
+        `SELECT `+playerColumns+`
          FROM players 
          WHERE id = $1`, 
         event.PlayerID,
-    ).Scan(&player.Email, &player.LastSignedInAt)
+    ))
 
     if err == sql.ErrNoRows {
         log.Printf("No player data found for ID: %d", event.PlayerID)
@@ -80,10 +137,11 @@ func (s *Service) Close() error {
     return s.db.Close()
 }
 
+// DB returns the underlying connection pool, shared with the exchange
+// service and health checks. It is nil when a mock DB is used.
 func (s *Service) DB() *sql.DB {
-    return s.db
+    if db, ok := s.db.(*sqlDB); ok {
+        return db.DB
+    }
+    return nil
 }
-
-func (s *Service) GetExchangeService() *exchange.Service {
-    return s.exchange
-} 
\ No newline at end of file
@@ -96,6 +96,68 @@ func TestPlayerEnricher(t *testing.T) {
     }
 }
 
+func TestPlayerEnricherProfile(t *testing.T) {
+    registered := time.Date(2023, 3, 14, 9, 0, 0, 0, time.UTC)
+    profile := mockProfile{
+        country:    "DE",
+        currency:   "EUR",
+        vipTier:    "gold",
+        registered: registered,
+        status:     "active",
+    }
+    mock := &mockDB{
+        queryFunc: func(ctx context.Context, query string, args ...interface{}) Scanner {
+            return &mockRow{email: "test@example.com", profile: profile}
+        },
+        queryRowsFunc: func(ctx context.Context, query string, args ...interface{}) (Rows, error) {
+            return &mockRows{players: []mockPlayer{{id: 7, email: "test@example.com", profile: profile}}}, nil
+        },
+    }
+
+    for _, batched := range []bool{false, true} {
+        svc := &Service{db: mock}
+        if batched {
+            svc.SetBatching(DefaultBatchSize, time.Millisecond, 4)
+        }
+
+        event := casino.Event{PlayerID: 7}
+        if err := svc.Enrich(context.Background(), &event); err != nil {
+            t.Fatalf("batched=%v: Enrich() error = %v", batched, err)
+        }
+
+        p := event.Player
+        if p.Country != "DE" || p.PreferredCurrency != "EUR" || p.VIPTier != "gold" || p.AccountStatus != "active" {
+            t.Errorf("batched=%v: profile = %+v", batched, p)
+        }
+        if p.RegisteredAt == nil || !p.RegisteredAt.Equal(registered) {
+            t.Errorf("batched=%v: RegisteredAt = %v, want %v", batched, p.RegisteredAt, registered)
+        }
+        if p.SelfExcluded {
+            t.Errorf("batched=%v: SelfExcluded = true, want false", batched)
+        }
+    }
+}
+
+func TestPlayerEnricherNullProfile(t *testing.T) {
+    mock := &mockDB{
+        queryFunc: func(ctx context.Context, query string, args ...interface{}) Scanner {
+            return &mockRow{email: "test@example.com"}
+        },
+    }
+    svc := &Service{db: mock}
+
+    event := casino.Event{PlayerID: 7}
+    if err := svc.Enrich(context.Background(), &event); err != nil {
+        t.Fatalf("Enrich() error = %v", err)
+    }
+    if event.Player.RegisteredAt != nil {
+        t.Errorf("RegisteredAt = %v, want nil for NULL", event.Player.RegisteredAt)
+    }
+    if event.Player.Country != "" || event.Player.VIPTier != "" {
+        t.Errorf("profile = %+v, want empty for NULL columns", event.Player)
+    }
+}
+
 // Integration test with real database
 func TestPlayerEnricherIntegration(t *testing.T) {
     if testing.Short() {
@@ -0,0 +1,124 @@
+// Package stack builds the configured enrichers, so that the subscriber and
+// the replay command's dry run enrich events the same way.
+package stack
+
+import (
+    "context"
+    "fmt"
+    "log"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/currency"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/description"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/exchange"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/game"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/player"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+// Stack holds the enrichers and the services behind them that callers
+// wire up further, such as the game admin API.
+type Stack struct {
+    Player   *player.Service
+    Exchange *exchange.Service
+    Games    *game.Catalogue
+
+    // GameReload is how often Games.Watch reloads without notifications.
+    GameReload time.Duration
+
+    // Enrichers in pipeline order: currency, player, game, description.
+    Enrichers []enricher.Enricher
+}
+
+// New connects to the database and builds the enrichers from cfg.
+func New(cfg *config.Config) (*Stack, error) {
+    playerEnricher, err := player.New(cfg.GetDBURL())
+    if err != nil {
+        return nil, fmt.Errorf("failed to create player enricher: %w", err)
+    }
+    s := &Stack{Player: playerEnricher}
+    if err := s.build(cfg); err != nil {
+        s.Close()
+        return nil, err
+    }
+    return s, nil
+}
+
+func (s *Stack) build(cfg *config.Config) error {
+    batchWait, err := time.ParseDuration(cfg.PlayerBatchWait)
+    if err != nil {
+        return fmt.Errorf("invalid PLAYER_BATCH_WAIT %q: %w", cfg.PlayerBatchWait, err)
+    }
+    s.Player.SetBatching(cfg.PlayerBatchSize, batchWait, cfg.SubscriberWorkers)
+    db := s.Player.DB()
+
+    s.Exchange, err = exchange.New(db)
+    if err != nil {
+        return fmt.Errorf("failed to create exchange service: %w", err)
+    }
+    chain, err := exchange.NewChainFromConfig(ExchangeProviders(cfg), db)
+    if err != nil {
+        return fmt.Errorf("invalid EXCHANGE_RATE_PROVIDERS: %w", err)
+    }
+    s.Exchange.SetChain(chain)
+
+    var rates currency.RateSource
+    switch cfg.CurrencyRateSource {
+    case "api":
+        rates = currency.NewExchangeSource(s.Exchange)
+    case "db":
+        rates = currency.NewDBSource(db)
+    case "static":
+        rates = currency.StaticRates
+    default:
+        return fmt.Errorf("unknown CURRENCY_RATE_SOURCE %q, want api, db or static", cfg.CurrencyRateSource)
+    }
+    currencyEnricher := currency.New(rates)
+    rounding, err := money.ParseRoundingMode(cfg.CurrencyRounding)
+    if err != nil {
+        return fmt.Errorf("invalid CURRENCY_ROUNDING: %w", err)
+    }
+    currencyEnricher.SetRounding(rounding)
+
+    s.Games = game.NewCatalogue(game.NewPostgresStore(db))
+    if err := s.Games.Reload(context.Background()); err != nil {
+        log.Printf("Failed to load game catalogue, using built-in games: %v", err)
+    }
+    s.GameReload, err = time.ParseDuration(cfg.GameReloadInterval)
+    if err != nil {
+        return fmt.Errorf("invalid GAME_RELOAD_INTERVAL %q: %w", cfg.GameReloadInterval, err)
+    }
+    gameEnricher := game.New(s.Games)
+
+    descriptionEnricher := description.New()
+    if cfg.DescriptionTemplatesDir != "" {
+        if err := descriptionEnricher.LoadTemplates(cfg.DescriptionTemplatesDir); err != nil {
+            return fmt.Errorf("invalid DESCRIPTION_TEMPLATES_DIR: %w", err)
+        }
+    }
+    if err := descriptionEnricher.SetLocale(cfg.DescriptionLocale); err != nil {
+        return fmt.Errorf("invalid DESCRIPTION_LOCALE: %w", err)
+    }
+    descriptionEnricher.SetShowEUR(cfg.DescriptionShowEUR)
+
+    s.Enrichers = []enricher.Enricher{currencyEnricher, s.Player, gameEnricher, descriptionEnricher}
+    return nil
+}
+
+// ExchangeProviders returns the EXCHANGE_RATE_* provider settings of cfg.
+func ExchangeProviders(cfg *config.Config) exchange.ProviderConfig {
+    return exchange.ProviderConfig{
+        Spec:           cfg.ExchangeRateProviders,
+        APIURL:         cfg.ExchangeRateAPIURL,
+        APIKey:         cfg.ExchangeRateAPIKey,
+        SourceCurrency: cfg.ExchangeRateSourceCurrency,
+        ECBURL:         cfg.ExchangeRateECBURL,
+        ECBHistoryURL:  cfg.ExchangeRateECBHistoryURL,
+        File:           cfg.ExchangeRateFile,
+    }
+}
+
+func (s *Stack) Close() {
+    s.Player.Close()
+}
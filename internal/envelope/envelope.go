@@ -0,0 +1,117 @@
+// Package envelope versions raw casino events on the wire. Each event is
+// wrapped with its schema version, a unique event id, the producing source
+// and the time it was produced.
+package envelope
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Version is the schema version written by this code. Version 0 stands for
+// bare, unwrapped events as published before envelopes existed.
+const Version = 1
+
+// Envelope reasons, alongside the casino.Reason* event reasons.
+const (
+	ReasonUnsupportedVersion = "unsupported_version"
+	ReasonMissingEventID     = "missing_event_id"
+	ReasonMissingSource      = "missing_source"
+)
+
+type Envelope struct {
+	SchemaVersion int          `json:"schema_version"`
+	EventID       string       `json:"event_id"`
+	Source        string       `json:"source"`
+	ProducedAt    time.Time    `json:"produced_at"`
+	Event         casino.Event `json:"event"`
+}
+
+// New wraps event for publishing by source with a fresh event id.
+func New(source string, event casino.Event) Envelope {
+	return Envelope{
+		SchemaVersion: Version,
+		EventID:       NewEventID(),
+		Source:        source,
+		ProducedAt:    time.Now().UTC(),
+		Event:         event,
+	}
+}
+
+// NewEventID returns a random 128-bit id in hex.
+func NewEventID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		panic(fmt.Sprintf("envelope: failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b[:])
+}
+
+func (e Envelope) Marshal() ([]byte, error) {
+	return json.Marshal(e)
+}
+
+// Decode parses a wrapped or a bare (version 0) event. Syntax errors are
+// returned as is; a well-formed envelope with an unsupported version or
+// missing metadata yields a *casino.ValidationError. The event itself is
+// not validated.
+func Decode(data []byte) (Envelope, error) {
+	var probe struct {
+		SchemaVersion *int `json:"schema_version"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Envelope{}, err
+	}
+
+	if probe.SchemaVersion == nil {
+		var event casino.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return Envelope{}, err
+		}
+		return Envelope{Event: event}, nil
+	}
+
+	if err := checkVersion(*probe.SchemaVersion); err != nil {
+		return Envelope{}, err
+	}
+
+	var env Envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return Envelope{}, err
+	}
+	if err := env.Check(); err != nil {
+		return Envelope{}, err
+	}
+	return env, nil
+}
+
+// Check returns a *casino.ValidationError if e has an unsupported version
+// or is missing metadata. Decode runs it; decoders of other wire formats
+// must run it too.
+func (e Envelope) Check() error {
+	if err := checkVersion(e.SchemaVersion); err != nil {
+		return err
+	}
+	switch {
+	case e.EventID == "":
+		return &casino.ValidationError{Reason: ReasonMissingEventID, Message: "envelope has no event_id"}
+	case e.Source == "":
+		return &casino.ValidationError{Reason: ReasonMissingSource, Message: "envelope has no source"}
+	}
+	return nil
+}
+
+func checkVersion(v int) error {
+	if v < 1 || v > Version {
+		return &casino.ValidationError{
+			Reason:  ReasonUnsupportedVersion,
+			Message: fmt.Sprintf("schema_version %d, this subscriber supports 1 to %d", v, Version),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package envelope
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func TestRoundTrip(t *testing.T) {
+	event := casino.Event{ID: 1, PlayerID: 10, GameID: 100, Type: "game_start", CreatedAt: time.Date(2022, 1, 10, 12, 0, 0, 0, time.UTC)}
+	env := New("test", event)
+	if env.SchemaVersion != Version || len(env.EventID) != 32 || env.ProducedAt.IsZero() {
+		t.Fatalf("New() = %+v", env)
+	}
+	if other := New("test", event); other.EventID == env.EventID {
+		t.Error("two envelopes share an event id")
+	}
+
+	data, err := env.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Decode(data)
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.EventID != env.EventID || got.Source != "test" || !got.Event.CreatedAt.Equal(event.CreatedAt) || got.Event.ID != 1 {
+		t.Errorf("Decode() = %+v, want %+v", got, env)
+	}
+}
+
+func TestDecodeBareEvent(t *testing.T) {
+	got, err := Decode([]byte(`{"id":7,"player_id":10,"type":"deposit","amount":100,"currency":"EUR"}`))
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if got.SchemaVersion != 0 || got.Event.ID != 7 || got.Event.Amount != 100 {
+		t.Errorf("Decode() = %+v, want bare event as version 0", got)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		reason string // empty for syntax errors
+	}{
+		{"syntax", `{not json`, ""},
+		{"future version", `{"schema_version":2,"event_id":"a","source":"s","event":{}}`, ReasonUnsupportedVersion},
+		{"zero version", `{"schema_version":0,"event_id":"a","source":"s","event":{}}`, ReasonUnsupportedVersion},
+		{"no event id", `{"schema_version":1,"source":"s","event":{}}`, ReasonMissingEventID},
+		{"no source", `{"schema_version":1,"event_id":"a","event":{}}`, ReasonMissingSource},
+		{"bad event", `{"schema_version":1,"event_id":"a","source":"s","event":{"id":"x"}}`, ""},
+	}
+	for _, tt := range tests {
+		_, err := Decode([]byte(tt.data))
+		if err == nil {
+			t.Errorf("%s: Decode() succeeded, want error", tt.name)
+			continue
+		}
+		var verr *casino.ValidationError
+		isValidation := errors.As(err, &verr)
+		if tt.reason == "" && isValidation {
+			t.Errorf("%s: Decode() = %v, want a syntax error", tt.name, err)
+		}
+		if tt.reason != "" && (!isValidation || verr.Reason != tt.reason) {
+			t.Errorf("%s: Decode() = %v, want reason %s", tt.name, err, tt.reason)
+		}
+	}
+}
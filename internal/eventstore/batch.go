@@ -0,0 +1,105 @@
+package eventstore
+
+import (
+	"context"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// batcher queues events from any number of writers and hands them to write
+// in batches from a single goroutine, so batches fill up across writers and
+// are committed in the order events were queued. Writers do not wait: each
+// event's done is called once its batch is committed or has failed.
+type batcher struct {
+	write   func(context.Context, []Record) error
+	maxSize int
+	maxWait time.Duration
+	timeout time.Duration
+
+	queue   chan queued
+	stopped chan struct{}
+}
+
+type queued struct {
+	record Record
+	done   func(error)
+}
+
+func newBatcher(write func(context.Context, []Record) error, maxSize int, maxWait, timeout time.Duration) *batcher {
+	if maxSize < 1 {
+		maxSize = 1
+	}
+	b := &batcher{
+		write:   write,
+		maxSize: maxSize,
+		maxWait: maxWait,
+		timeout: timeout,
+		queue:   make(chan queued, maxSize),
+		stopped: make(chan struct{}),
+	}
+	go b.run()
+	return b
+}
+
+// add queues r, blocking only while a full batch is already waiting. done
+// is called from the batcher's goroutine with the result of the write.
+func (b *batcher) add(r Record, done func(error)) {
+	b.queue <- queued{record: r, done: done}
+}
+
+// close writes the events still queued and stops the batcher. add must not
+// be called afterwards.
+func (b *batcher) close() {
+	close(b.queue)
+	<-b.stopped
+}
+
+// run collects each batch from its first event until it is full or maxWait
+// has passed, then writes it.
+func (b *batcher) run() {
+	defer close(b.stopped)
+	for first := range b.queue {
+		batch := []queued{first}
+		timer := time.NewTimer(b.maxWait)
+	collect:
+		for len(batch) < b.maxSize {
+			select {
+			case q, ok := <-b.queue:
+				if !ok {
+					break collect
+				}
+				batch = append(batch, q)
+			case <-timer.C:
+				break collect
+			}
+		}
+		timer.Stop()
+		b.flush(batch)
+	}
+}
+
+// flush writes batch and reports the result to every event in it.
+func (b *batcher) flush(batch []queued) {
+	records := make([]Record, len(batch))
+	for i, q := range batch {
+		records[i] = q.record
+	}
+	metrics.EventStoreBatchSize.Observe(float64(len(records)))
+
+	ctx, cancel := context.WithTimeout(context.Background(), b.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := b.write(ctx, records)
+	metrics.EventStoreWriteDuration.Observe(time.Since(start).Seconds())
+	if err != nil {
+		metrics.EventStoreWrites.WithLabelValues("error").Add(float64(len(records)))
+	} else {
+		metrics.EventStoreWrites.WithLabelValues("ok").Add(float64(len(records)))
+	}
+
+	for _, q := range batch {
+		q.done(err)
+	}
+}
@@ -0,0 +1,159 @@
+package eventstore
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func TestBatcherGroupsConcurrentWrites(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]Record
+	b := newBatcher(func(ctx context.Context, records []Record) error {
+		mu.Lock()
+		batches = append(batches, records)
+		mu.Unlock()
+		return nil
+	}, 100, 20*time.Millisecond, time.Second)
+	defer b.close()
+
+	var wg sync.WaitGroup
+	for id := 1; id <= 10; id++ {
+		wg.Add(1)
+		go func(id int) {
+			b.add(record(id), func(err error) {
+				if err != nil {
+					t.Errorf("add(%d) error = %v", id, err)
+				}
+				wg.Done()
+			})
+		}(id)
+	}
+	wg.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	total := 0
+	for _, batch := range batches {
+		total += len(batch)
+	}
+	if total != 10 {
+		t.Errorf("Wrote %d events, want 10", total)
+	}
+	if len(batches) >= 10 {
+		t.Errorf("Wrote %d batches for 10 concurrent events, want them grouped", len(batches))
+	}
+}
+
+func TestBatcherDoesNotBlockWriters(t *testing.T) {
+	release := make(chan struct{})
+	b := newBatcher(func(ctx context.Context, records []Record) error {
+		<-release
+		return nil
+	}, 4, time.Hour, time.Second)
+
+	// A batch being written and a full one queued behind it: adding
+	// returns at once while the first write is still running.
+	var order []int
+	var wg sync.WaitGroup
+	added := make(chan struct{})
+	go func() {
+		for id := 1; id <= 8; id++ {
+			wg.Add(1)
+			b.add(record(id), func(err error) {
+				order = append(order, id)
+				wg.Done()
+			})
+		}
+		close(added)
+	}()
+
+	select {
+	case <-added:
+	case <-time.After(time.Second):
+		t.Fatal("add blocked while a batch was being written")
+	}
+	close(release)
+	wg.Wait()
+	b.close()
+
+	for i, id := range order {
+		if id != i+1 {
+			t.Fatalf("Completed in order %v, want the order events were added", order)
+		}
+	}
+}
+
+func TestBatcherFlushesWhenFull(t *testing.T) {
+	written := make(chan int, 10)
+	b := newBatcher(func(ctx context.Context, records []Record) error {
+		written <- len(records)
+		return nil
+	}, 2, time.Hour, time.Second)
+	defer b.close()
+
+	for id := 1; id <= 2; id++ {
+		b.add(record(id), func(error) {})
+	}
+
+	select {
+	case n := <-written:
+		if n != 2 {
+			t.Errorf("Batch of %d events, want 2", n)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Full batch was not flushed before the wait expired")
+	}
+}
+
+func TestBatcherReturnsWriteError(t *testing.T) {
+	want := errors.New("copy failed")
+	b := newBatcher(func(ctx context.Context, records []Record) error {
+		return want
+	}, 10, time.Millisecond, time.Second)
+	defer b.close()
+
+	result := make(chan error, 1)
+	b.add(record(1), func(err error) { result <- err })
+	if err := <-result; !errors.Is(err, want) {
+		t.Errorf("add() error = %v, want %v", err, want)
+	}
+}
+
+func TestBatcherCloseWritesQueued(t *testing.T) {
+	var written int
+	b := newBatcher(func(ctx context.Context, records []Record) error {
+		written += len(records)
+		return nil
+	}, 10, time.Hour, time.Second)
+
+	b.add(record(1), func(error) {})
+	b.add(record(2), func(error) {})
+	b.close()
+
+	if written != 2 {
+		t.Errorf("Wrote %d events on close, want 2", written)
+	}
+}
+
+// record returns a record for the event with id.
+func record(id int) Record {
+	return Record{Key: "id:" + strconv.Itoa(id), Event: casino.Event{ID: id}}
+}
+
+func TestDays(t *testing.T) {
+	events := []casino.Event{
+		{CreatedAt: time.Date(2024, 2, 24, 23, 59, 0, 0, time.UTC)},
+		{CreatedAt: time.Date(2024, 2, 25, 0, 30, 0, 0, time.FixedZone("CET", 3600))}, // still the 24th in UTC
+		{CreatedAt: time.Date(2024, 2, 25, 1, 0, 0, 0, time.UTC)},
+	}
+	got := days(events)
+	if len(got) != 2 || got[0] != "2024-02-24" || got[1] != "2024-02-25" {
+		t.Errorf("days() = %v, want [2024-02-24 2024-02-25]", got)
+	}
+}
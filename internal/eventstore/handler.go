@@ -0,0 +1,108 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Querier is implemented by Store.
+type Querier interface {
+	Query(ctx context.Context, f Filter) (Page, error)
+}
+
+// Handler serves GET /events. Query parameters, all optional:
+//
+//	player_id, game_id  exact match
+//	type, currency      exact match
+//	from, to            RFC 3339 times; from is inclusive, to exclusive
+//	limit               page size, 100 by default and at most 1000
+//	cursor              next_cursor of the previous page
+func Handler(q Querier) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		f, err := parseFilter(r)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		page, err := q.Query(r.Context(), f)
+		if errors.Is(err, ErrInvalidCursor) {
+			writeError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		if err != nil {
+			log.Printf("Failed to query events: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to query events")
+			return
+		}
+		writeJSON(w, http.StatusOK, page)
+	})
+	return mux
+}
+
+func parseFilter(r *http.Request) (Filter, error) {
+	v := r.URL.Query()
+	f := Filter{
+		Type:     v.Get("type"),
+		Currency: v.Get("currency"),
+		Cursor:   v.Get("cursor"),
+	}
+
+	ints := []struct {
+		name string
+		dst  *int
+	}{
+		{"player_id", &f.PlayerID},
+		{"game_id", &f.GameID},
+		{"limit", &f.Limit},
+	}
+	for _, p := range ints {
+		s := v.Get(p.name)
+		if s == "" {
+			continue
+		}
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= 0 {
+			return Filter{}, errors.New("invalid " + p.name + ": want a positive integer")
+		}
+		*p.dst = n
+	}
+
+	times := []struct {
+		name string
+		dst  *time.Time
+	}{
+		{"from", &f.From},
+		{"to", &f.To},
+	}
+	for _, p := range times {
+		s := v.Get(p.name)
+		if s == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			return Filter{}, errors.New("invalid " + p.name + ": want an RFC 3339 time")
+		}
+		*p.dst = t
+	}
+	if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+		return Filter{}, errors.New("from must be before to")
+	}
+	return f, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
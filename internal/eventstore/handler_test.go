@@ -0,0 +1,76 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+type fakeQuerier struct {
+	got  Filter
+	page Page
+	err  error
+}
+
+func (f *fakeQuerier) Query(ctx context.Context, filter Filter) (Page, error) {
+	f.got = filter
+	return f.page, f.err
+}
+
+func TestHandlerFilters(t *testing.T) {
+	q := &fakeQuerier{page: Page{Events: []casino.Event{{ID: 1}}, NextCursor: "abc"}}
+	h := Handler(q)
+
+	req := httptest.NewRequest("GET", "/events?player_id=10&game_id=100&type=bet&currency=USD&from=2024-02-24T00:00:00Z&to=2024-02-25T00:00:00%2B01:00&limit=20&cursor=abc", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", rec.Code, rec.Body)
+	}
+	want := Filter{
+		PlayerID: 10,
+		GameID:   100,
+		Type:     "bet",
+		Currency: "USD",
+		From:     time.Date(2024, 2, 24, 0, 0, 0, 0, time.UTC),
+		To:       time.Date(2024, 2, 24, 23, 0, 0, 0, time.UTC),
+		Limit:    20,
+		Cursor:   "abc",
+	}
+	if q.got.PlayerID != want.PlayerID || q.got.GameID != want.GameID || q.got.Type != want.Type ||
+		q.got.Currency != want.Currency || !q.got.From.Equal(want.From) || !q.got.To.Equal(want.To) ||
+		q.got.Limit != want.Limit || q.got.Cursor != want.Cursor {
+		t.Errorf("filter = %+v, want %+v", q.got, want)
+	}
+
+	var page Page
+	if err := json.NewDecoder(rec.Body).Decode(&page); err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Events) != 1 || page.NextCursor != "abc" {
+		t.Errorf("page = %+v", page)
+	}
+}
+
+func TestHandlerBadRequests(t *testing.T) {
+	h := Handler(&fakeQuerier{err: ErrInvalidCursor})
+	for _, query := range []string{
+		"player_id=abc",
+		"limit=0",
+		"from=yesterday",
+		"from=2024-02-25T00:00:00Z&to=2024-02-24T00:00:00Z",
+		"cursor=zzz",
+	} {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest("GET", "/events?"+query, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("GET /events?%s status = %d, want %d", query, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package eventstore
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+const (
+	DefaultLimit = 100
+	MaxLimit     = 1000
+)
+
+// ErrInvalidCursor is returned for cursors not produced by Query.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Filter selects stored events. Zero fields match everything; From is
+// inclusive and To exclusive.
+type Filter struct {
+	PlayerID int
+	GameID   int
+	Type     string
+	Currency string
+	From     time.Time
+	To       time.Time
+
+	// Limit is the page size, DefaultLimit if zero and at most MaxLimit.
+	Limit int
+	// Cursor continues after the page that returned it.
+	Cursor string
+}
+
+// Page is one page of events, oldest first. NextCursor is empty on the
+// last page.
+type Page struct {
+	Events     []casino.Event `json:"events"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+// cursor is the position of the last event of a page. Events are ordered
+// by created_at and then seq, the insertion order.
+type cursor struct {
+	createdAt time.Time
+	seq       int64
+}
+
+func (c cursor) String() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf("%d:%d", c.createdAt.UnixNano(), c.seq)))
+}
+
+func parseCursor(s string) (cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	at, seq, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return cursor{}, ErrInvalidCursor
+	}
+	nanos, err := strconv.ParseInt(at, 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(seq, 10, 64)
+	if err != nil {
+		return cursor{}, ErrInvalidCursor
+	}
+	return cursor{createdAt: time.Unix(0, nanos).UTC(), seq: n}, nil
+}
+
+// Query returns the page of events matching f.
+func (s *Store) Query(ctx context.Context, f Filter) (Page, error) {
+	query, args, limit, err := buildQuery(f)
+	if err != nil {
+		return Page{}, err
+	}
+
+	rows, err := s.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return Page{}, fmt.Errorf("failed to query events: %w", err)
+	}
+	defer rows.Close()
+
+	page := Page{Events: []casino.Event{}}
+	var last cursor
+	for rows.Next() {
+		if len(page.Events) == limit {
+			// One row more than the limit: there is another page.
+			page.NextCursor = last.String()
+			break
+		}
+		var data []byte
+		if err := rows.Scan(&last.seq, &last.createdAt, &data); err != nil {
+			return Page{}, fmt.Errorf("failed to scan event: %w", err)
+		}
+		var event casino.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return Page{}, fmt.Errorf("failed to decode stored event %d: %w", last.seq, err)
+		}
+		page.Events = append(page.Events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return Page{}, fmt.Errorf("failed to query events: %w", err)
+	}
+	return page, nil
+}
+
+// buildQuery returns the SQL for f and its arguments. It selects one row
+// more than the page size to tell whether another page follows.
+func buildQuery(f Filter) (query string, args []interface{}, limit int, err error) {
+	limit = f.Limit
+	switch {
+	case limit <= 0:
+		limit = DefaultLimit
+	case limit > MaxLimit:
+		limit = MaxLimit
+	}
+
+	var where []string
+	add := func(cond string, v ...interface{}) {
+		n := make([]interface{}, len(v))
+		for i := range v {
+			args = append(args, v[i])
+			n[i] = len(args)
+		}
+		where = append(where, fmt.Sprintf(cond, n...))
+	}
+
+	if f.PlayerID != 0 {
+		add("player_id = $%d", f.PlayerID)
+	}
+	if f.GameID != 0 {
+		add("game_id = $%d", f.GameID)
+	}
+	if f.Type != "" {
+		add("type = $%d", f.Type)
+	}
+	if f.Currency != "" {
+		add("currency = $%d", f.Currency)
+	}
+	if !f.From.IsZero() {
+		add("created_at >= $%d", f.From.UTC())
+	}
+	if !f.To.IsZero() {
+		add("created_at < $%d", f.To.UTC())
+	}
+	if f.Cursor != "" {
+		c, err := parseCursor(f.Cursor)
+		if err != nil {
+			return "", nil, 0, err
+		}
+		add("(created_at, seq) > ($%d, $%d)", c.createdAt, c.seq)
+	}
+
+	var b strings.Builder
+	b.WriteString("SELECT seq, created_at, event FROM events")
+	if len(where) > 0 {
+		b.WriteString(" WHERE ")
+		b.WriteString(strings.Join(where, " AND "))
+	}
+	args = append(args, limit+1)
+	fmt.Fprintf(&b, " ORDER BY created_at, seq LIMIT $%d", len(args))
+	return b.String(), args, limit, nil
+}
@@ -0,0 +1,75 @@
+package eventstore
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestBuildQuery(t *testing.T) {
+	from := time.Date(2024, 2, 24, 0, 0, 0, 0, time.UTC)
+	to := from.Add(24 * time.Hour)
+	c := cursor{createdAt: from.Add(time.Hour), seq: 42}
+
+	query, args, limit, err := buildQuery(Filter{
+		PlayerID: 10,
+		Type:     "bet",
+		Currency: "USD",
+		From:     from,
+		To:       to,
+		Limit:    50,
+		Cursor:   c.String(),
+	})
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+
+	wantQuery := "SELECT seq, created_at, event FROM events" +
+		" WHERE player_id = $1 AND type = $2 AND currency = $3 AND created_at >= $4 AND created_at < $5 AND (created_at, seq) > ($6, $7)" +
+		" ORDER BY created_at, seq LIMIT $8"
+	if query != wantQuery {
+		t.Errorf("query =\n%s\nwant\n%s", query, wantQuery)
+	}
+	wantArgs := []interface{}{10, "bet", "USD", from, to, c.createdAt, int64(42), 51}
+	if !reflect.DeepEqual(args, wantArgs) {
+		t.Errorf("args = %v, want %v", args, wantArgs)
+	}
+	if limit != 50 {
+		t.Errorf("limit = %d, want 50", limit)
+	}
+}
+
+func TestBuildQueryDefaults(t *testing.T) {
+	query, args, limit, err := buildQuery(Filter{})
+	if err != nil {
+		t.Fatalf("buildQuery() error = %v", err)
+	}
+	if want := "SELECT seq, created_at, event FROM events ORDER BY created_at, seq LIMIT $1"; query != want {
+		t.Errorf("query = %s, want %s", query, want)
+	}
+	if limit != DefaultLimit || !reflect.DeepEqual(args, []interface{}{DefaultLimit + 1}) {
+		t.Errorf("limit, args = %d, %v, want %d", limit, args, DefaultLimit)
+	}
+
+	if _, _, limit, _ := buildQuery(Filter{Limit: MaxLimit * 2}); limit != MaxLimit {
+		t.Errorf("limit = %d, want it capped at %d", limit, MaxLimit)
+	}
+}
+
+func TestCursor(t *testing.T) {
+	c := cursor{createdAt: time.Date(2024, 2, 24, 10, 48, 10, 123456000, time.UTC), seq: 7}
+	got, err := parseCursor(c.String())
+	if err != nil {
+		t.Fatalf("parseCursor() error = %v", err)
+	}
+	if !got.createdAt.Equal(c.createdAt) || got.seq != c.seq {
+		t.Errorf("parseCursor(%s) = %+v, want %+v", c, got, c)
+	}
+
+	for _, bad := range []string{"not base64!", "bm9jb2xvbg", "YTpi"} {
+		if _, _, _, err := buildQuery(Filter{Cursor: bad}); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("buildQuery(cursor %q) error = %v, want %v", bad, err, ErrInvalidCursor)
+		}
+	}
+}
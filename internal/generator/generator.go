@@ -7,6 +7,18 @@ import (
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
 )
 
+// Generator produces casino events until the context is done.
+type Generator interface {
+    Generate(ctx context.Context) <-chan casino.Event
+}
+
+// GeneratorFunc adapts a plain function such as Generate to the Generator interface.
+type GeneratorFunc func(ctx context.Context) <-chan casino.Event
+
+func (f GeneratorFunc) Generate(ctx context.Context) <-chan casino.Event {
+    return f(ctx)
+}
+
 func Generate(ctx context.Context) <-chan casino.Event {
     eventCh := make(chan casino.Event)
     var id int
@@ -29,19 +41,28 @@ func Generate(ctx context.Context) <-chan casino.Event {
     return eventCh
 }
 
+// generate returns a random event that passes casino.Event.Validate: only
+// bets and deposits carry an amount, only bets may be won, and deposits
+// have no game.
 func generate(id int) casino.Event {
-    amount, currency := randomAmountCurrency()
-
-    return casino.Event{
+    event := casino.Event{
         ID:        id,
         PlayerID:  10 + rand.Intn(10),
         GameID:    100 + rand.Intn(10),
         Type:      randomType(),
-        Amount:    amount,
-        Currency:  currency,
-        HasWon:    randomHasWon(),
         CreatedAt: time.Now(),
     }
+
+    switch event.Type {
+    case "bet":
+        event.Amount, event.Currency = randomAmountCurrency()
+        event.HasWon = randomHasWon()
+    case "deposit":
+        event.Amount, event.Currency = randomAmountCurrency()
+        event.GameID = 0
+    }
+
+    return event
 }
 
 func randomType() string {
@@ -53,9 +74,9 @@ func randomAmountCurrency() (amount int, currency string) {
 
     switch currency {
     case "BTC":
-        amount = rand.Intn(1e5)
+        amount = 1 + rand.Intn(1e5)
     default:
-        amount = rand.Intn(2000)
+        amount = 1 + rand.Intn(2000)
     }
 
     return
@@ -0,0 +1,371 @@
+package generator
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "math/rand"
+    "os"
+    "path/filepath"
+    "slices"
+    "strings"
+    "time"
+    "gopkg.in/yaml.v3"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+// Scenario configures the session simulator. Omitted fields take the
+// defaults of DefaultScenario. Amounts are in major units of the currency
+// (2.5 is 2.50 EUR) and converted to minor units when events are generated.
+type Scenario struct {
+    // Seed makes runs reproducible; 0 picks a random seed.
+    Seed int64 `json:"seed" yaml:"seed"`
+    // Start is the created_at of the simulated clock, now by default.
+    Start time.Time `json:"start" yaml:"start"`
+    // Realtime paces events by the simulated clock instead of emitting them
+    // as fast as they are consumed.
+    Realtime bool `json:"realtime" yaml:"realtime"`
+
+    Players Population `json:"players" yaml:"players"`
+    // Games played, picked uniformly.
+    Games []int `json:"games" yaml:"games"`
+    // Currencies maps each player currency to its weight in the mix.
+    Currencies map[string]float64 `json:"currencies" yaml:"currencies"`
+    // WinRate is the probability that a bet is won.
+    WinRate *float64 `json:"win_rate" yaml:"win_rate"`
+
+    Session     Session      `json:"session" yaml:"session"`
+    BetSize     Distribution `json:"bet_size" yaml:"bet_size"`
+    DepositSize Distribution `json:"deposit_size" yaml:"deposit_size"`
+    Bursts      []Burst      `json:"bursts" yaml:"bursts"`
+}
+
+// Population is the simulated players, with ids FirstID to
+// FirstID+Count-1. Players missing from the players table are enriched
+// without profiles.
+type Population struct {
+    Count   int `json:"count" yaml:"count"`
+    FirstID int `json:"first_id" yaml:"first_id"`
+}
+
+// Session shapes what a player does between logging in and out.
+type Session struct {
+    // DepositProbability is the chance of a deposit right after login.
+    DepositProbability *float64 `json:"deposit_probability" yaml:"deposit_probability"`
+    // Games is how many games are played per login, Bets how many bets
+    // per game.
+    Games Range `json:"games" yaml:"games"`
+    Bets  Range `json:"bets" yaml:"bets"`
+    // ThinkTime is the mean gap between a player's actions, IdleTime the
+    // mean time between logging out and in again. Gaps are exponential.
+    ThinkTime Duration `json:"think_time" yaml:"think_time"`
+    IdleTime  Duration `json:"idle_time" yaml:"idle_time"`
+}
+
+// Range is an inclusive range of counts, picked uniformly.
+type Range struct {
+    Min int `json:"min" yaml:"min"`
+    Max int `json:"max" yaml:"max"`
+}
+
+// Distribution kinds.
+const (
+    Fixed     = "fixed"
+    Uniform   = "uniform"
+    LogNormal = "lognormal"
+)
+
+// Distribution draws amounts in major units. Fixed uses Value, uniform
+// draws between Min and Max, and lognormal draws around Median with spread
+// Sigma, clamped to Min and Max if set. Currencies overrides the
+// distribution for single currencies, e.g. BTC.
+type Distribution struct {
+    Kind       string                  `json:"distribution" yaml:"distribution"`
+    Value      float64                 `json:"value" yaml:"value"`
+    Min        float64                 `json:"min" yaml:"min"`
+    Max        float64                 `json:"max" yaml:"max"`
+    Median     float64                 `json:"median" yaml:"median"`
+    Sigma      float64                 `json:"sigma" yaml:"sigma"`
+    Currencies map[string]Distribution `json:"currencies" yaml:"currencies"`
+}
+
+// Burst speeds up every player by Factor for the last Duration of each
+// Every, counted from the start of the simulation.
+type Burst struct {
+    Every    Duration `json:"every" yaml:"every"`
+    Duration Duration `json:"duration" yaml:"duration"`
+    Factor   float64  `json:"factor" yaml:"factor"`
+}
+
+// Duration is a time.Duration written as a string such as "1m30s".
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+    var s string
+    if err := json.Unmarshal(data, &s); err != nil {
+        return fmt.Errorf("duration must be a string such as \"1m\": %w", err)
+    }
+    return d.parse(s)
+}
+
+func (d *Duration) UnmarshalYAML(node *yaml.Node) error {
+    var s string
+    if err := node.Decode(&s); err != nil {
+        return err
+    }
+    return d.parse(s)
+}
+
+func (d *Duration) parse(s string) error {
+    v, err := time.ParseDuration(s)
+    if err != nil {
+        return err
+    }
+    *d = Duration(v)
+    return nil
+}
+
+// DefaultScenario returns the defaults: the ten sample players and games,
+// an even currency mix, a 5% win rate, one to three games of up to twenty
+// bets per session, and no bursts.
+func DefaultScenario() Scenario {
+    winRate, depositProbability := 0.05, 0.5
+    currencies := make(map[string]float64, len(casino.Currencies))
+    for _, c := range casino.Currencies {
+        currencies[c] = 1
+    }
+    return Scenario{
+        Players:    Population{Count: 10, FirstID: 10},
+        Games:      []int{100, 101, 102, 103, 104, 105, 106, 107, 108, 109},
+        Currencies: currencies,
+        WinRate:    &winRate,
+        Session: Session{
+            DepositProbability: &depositProbability,
+            Games:              Range{Min: 1, Max: 3},
+            Bets:               Range{Min: 1, Max: 20},
+            ThinkTime:          Duration(2 * time.Second),
+            IdleTime:           Duration(time.Minute),
+        },
+        BetSize: Distribution{
+            Kind: LogNormal, Median: 2, Sigma: 1, Min: 0.1, Max: 1000,
+            Currencies: map[string]Distribution{
+                "BTC": {Kind: LogNormal, Median: 0.00005, Sigma: 1, Min: 0.000001, Max: 0.05},
+            },
+        },
+        DepositSize: Distribution{
+            Kind: LogNormal, Median: 50, Sigma: 0.8, Min: 10, Max: 5000,
+            Currencies: map[string]Distribution{
+                "BTC": {Kind: LogNormal, Median: 0.001, Sigma: 0.8, Min: 0.0001, Max: 0.1},
+            },
+        },
+    }
+}
+
+// LoadScenario reads a scenario from a .yaml, .yml or .json file.
+func LoadScenario(path string) (Scenario, error) {
+    data, err := os.ReadFile(path)
+    if err != nil {
+        return Scenario{}, fmt.Errorf("failed to read scenario: %w", err)
+    }
+    s, err := ParseScenario(data, strings.TrimPrefix(filepath.Ext(path), "."))
+    if err != nil {
+        return Scenario{}, fmt.Errorf("invalid scenario %s: %w", path, err)
+    }
+    return s, nil
+}
+
+// ParseScenario decodes a scenario in format "yaml", "yml" or "json",
+// fills in the defaults and validates it. Unknown fields are errors.
+func ParseScenario(data []byte, format string) (Scenario, error) {
+    s := DefaultScenario()
+    // Fields present in the file replace the defaults entirely, so that an
+    // explicit bet_size or currency mix is not merged with the default one.
+    var decoded Scenario
+    switch strings.ToLower(format) {
+    case "yaml", "yml":
+        dec := yaml.NewDecoder(bytes.NewReader(data))
+        dec.KnownFields(true)
+        if err := dec.Decode(&decoded); err != nil && !errors.Is(err, io.EOF) {
+            return Scenario{}, err
+        }
+    case "json":
+        dec := json.NewDecoder(bytes.NewReader(data))
+        dec.DisallowUnknownFields()
+        if err := dec.Decode(&decoded); err != nil {
+            return Scenario{}, err
+        }
+    default:
+        return Scenario{}, fmt.Errorf("unknown scenario format %q, want yaml or json", format)
+    }
+    s.merge(decoded)
+    if err := s.Validate(); err != nil {
+        return Scenario{}, err
+    }
+    return s, nil
+}
+
+// merge replaces the fields of s that are set in o.
+func (s *Scenario) merge(o Scenario) {
+    s.Seed, s.Start, s.Realtime = o.Seed, o.Start, o.Realtime
+    if o.Players.Count != 0 {
+        s.Players.Count = o.Players.Count
+    }
+    if o.Players.FirstID != 0 {
+        s.Players.FirstID = o.Players.FirstID
+    }
+    if o.Games != nil {
+        s.Games = o.Games
+    }
+    if o.Currencies != nil {
+        s.Currencies = o.Currencies
+    }
+    if o.WinRate != nil {
+        s.WinRate = o.WinRate
+    }
+    if o.Session.DepositProbability != nil {
+        s.Session.DepositProbability = o.Session.DepositProbability
+    }
+    if o.Session.Games != (Range{}) {
+        s.Session.Games = o.Session.Games
+    }
+    if o.Session.Bets != (Range{}) {
+        s.Session.Bets = o.Session.Bets
+    }
+    if o.Session.ThinkTime != 0 {
+        s.Session.ThinkTime = o.Session.ThinkTime
+    }
+    if o.Session.IdleTime != 0 {
+        s.Session.IdleTime = o.Session.IdleTime
+    }
+    if o.BetSize.Kind != "" {
+        s.BetSize = o.BetSize
+    }
+    if o.DepositSize.Kind != "" {
+        s.DepositSize = o.DepositSize
+    }
+    s.Bursts = o.Bursts
+}
+
+// Validate reports the first setting that cannot be simulated.
+func (s Scenario) Validate() error {
+    if s.Players.Count <= 0 || s.Players.FirstID <= 0 {
+        return errors.New("players: count and first_id must be positive")
+    }
+    if len(s.Games) == 0 {
+        return errors.New("games: at least one game is required")
+    }
+    for _, id := range s.Games {
+        if id <= 0 {
+            return fmt.Errorf("games: id %d is not positive", id)
+        }
+    }
+    var total float64
+    for c, w := range s.Currencies {
+        if !slices.Contains(casino.Currencies, c) {
+            return fmt.Errorf("currencies: %q is not one of %v", c, casino.Currencies)
+        }
+        if w < 0 {
+            return fmt.Errorf("currencies: weight of %s is negative", c)
+        }
+        total += w
+    }
+    if total == 0 {
+        return errors.New("currencies: at least one currency needs a positive weight")
+    }
+    if p := *s.WinRate; p < 0 || p > 1 {
+        return fmt.Errorf("win_rate: %v is not between 0 and 1", p)
+    }
+    if p := *s.Session.DepositProbability; p < 0 || p > 1 {
+        return fmt.Errorf("session.deposit_probability: %v is not between 0 and 1", p)
+    }
+    if r := s.Session.Games; r.Min < 1 || r.Max < r.Min {
+        return fmt.Errorf("session.games: want 1 <= min <= max, got %d and %d", r.Min, r.Max)
+    }
+    if r := s.Session.Bets; r.Min < 0 || r.Max < r.Min {
+        return fmt.Errorf("session.bets: want 0 <= min <= max, got %d and %d", r.Min, r.Max)
+    }
+    if s.Session.ThinkTime < 0 || s.Session.IdleTime < 0 {
+        return errors.New("session: think_time and idle_time must not be negative")
+    }
+    if err := s.BetSize.validate(); err != nil {
+        return fmt.Errorf("bet_size: %w", err)
+    }
+    if err := s.DepositSize.validate(); err != nil {
+        return fmt.Errorf("deposit_size: %w", err)
+    }
+    for i, b := range s.Bursts {
+        if b.Every <= 0 || b.Duration <= 0 || b.Duration > b.Every || b.Factor <= 0 {
+            return fmt.Errorf("bursts[%d]: want 0 < duration <= every and a positive factor", i)
+        }
+    }
+    return nil
+}
+
+func (d Distribution) validate() error {
+    switch d.Kind {
+    case Fixed:
+        if d.Value <= 0 {
+            return errors.New("fixed value must be positive")
+        }
+    case Uniform:
+        if d.Min <= 0 || d.Max < d.Min {
+            return errors.New("uniform needs 0 < min <= max")
+        }
+    case LogNormal:
+        if d.Median <= 0 || d.Sigma < 0 {
+            return errors.New("lognormal needs a positive median and a non-negative sigma")
+        }
+        if d.Min < 0 || (d.Max != 0 && d.Max < d.Min) {
+            return errors.New("lognormal bounds need 0 <= min <= max")
+        }
+    default:
+        return fmt.Errorf("unknown distribution %q, want %s, %s or %s", d.Kind, Fixed, Uniform, LogNormal)
+    }
+    for c, o := range d.Currencies {
+        if !slices.Contains(casino.Currencies, c) {
+            return fmt.Errorf("currencies: %q is not one of %v", c, casino.Currencies)
+        }
+        if len(o.Currencies) > 0 {
+            return fmt.Errorf("currencies.%s: overrides cannot be nested", c)
+        }
+        if err := o.validate(); err != nil {
+            return fmt.Errorf("currencies.%s: %w", c, err)
+        }
+    }
+    return nil
+}
+
+// sample draws an amount in minor units of currency, at least 1.
+func (d Distribution) sample(rng *rand.Rand, currency string) int {
+    if o, ok := d.Currencies[currency]; ok {
+        d = o
+    }
+    var major float64
+    switch d.Kind {
+    case Fixed:
+        major = d.Value
+    case Uniform:
+        major = d.Min + rng.Float64()*(d.Max-d.Min)
+    case LogNormal:
+        major = d.Median * math.Exp(d.Sigma*rng.NormFloat64())
+        if major < d.Min {
+            major = d.Min
+        }
+        if d.Max > 0 && major > d.Max {
+            major = d.Max
+        }
+    }
+    exp, err := money.Exponent(currency)
+    if err != nil {
+        exp = 2
+    }
+    minor := int(math.Round(major * math.Pow10(exp)))
+    if minor < 1 {
+        minor = 1
+    }
+    return minor
+}
@@ -0,0 +1,114 @@
+package generator
+
+import (
+    "strings"
+    "testing"
+    "time"
+)
+
+func TestParseScenarioYAML(t *testing.T) {
+    data := `
+seed: 42
+players:
+  count: 100
+currencies:
+  EUR: 3
+  BTC: 1
+win_rate: 0
+session:
+  bets: {min: 2, max: 5}
+  think_time: 500ms
+bet_size:
+  distribution: uniform
+  min: 1
+  max: 10
+bursts:
+  - every: 10m
+    duration: 1m
+    factor: 5
+`
+    s, err := ParseScenario([]byte(data), "yaml")
+    if err != nil {
+        t.Fatalf("ParseScenario() error = %v", err)
+    }
+
+    if s.Seed != 42 || s.Players.Count != 100 || s.Players.FirstID != 10 {
+        t.Errorf("seed and players = %d, %+v", s.Seed, s.Players)
+    }
+    if len(s.Currencies) != 2 || s.Currencies["EUR"] != 3 {
+        t.Errorf("currencies = %v, want EUR and BTC only", s.Currencies)
+    }
+    if *s.WinRate != 0 {
+        t.Errorf("win rate = %v, want an explicit 0", *s.WinRate)
+    }
+    if s.Session.Bets != (Range{2, 5}) || s.Session.Games != (Range{1, 3}) {
+        t.Errorf("session ranges = %+v, %+v", s.Session.Bets, s.Session.Games)
+    }
+    if time.Duration(s.Session.ThinkTime) != 500*time.Millisecond || time.Duration(s.Session.IdleTime) != time.Minute {
+        t.Errorf("session times = %v, %v", s.Session.ThinkTime, s.Session.IdleTime)
+    }
+    if s.BetSize.Kind != Uniform || s.BetSize.Currencies != nil {
+        t.Errorf("bet size = %+v, want the file's distribution without default overrides", s.BetSize)
+    }
+    if s.DepositSize.Kind != LogNormal {
+        t.Errorf("deposit size = %+v, want the default", s.DepositSize)
+    }
+    if len(s.Bursts) != 1 || time.Duration(s.Bursts[0].Every) != 10*time.Minute {
+        t.Errorf("bursts = %+v", s.Bursts)
+    }
+}
+
+func TestParseScenarioJSON(t *testing.T) {
+    s, err := ParseScenario([]byte(`{"seed": 7, "games": [100], "session": {"idle_time": "5m"}}`), "json")
+    if err != nil {
+        t.Fatalf("ParseScenario() error = %v", err)
+    }
+    if s.Seed != 7 || len(s.Games) != 1 || time.Duration(s.Session.IdleTime) != 5*time.Minute {
+        t.Errorf("scenario = %+v", s)
+    }
+}
+
+func TestParseScenarioErrors(t *testing.T) {
+    tests := []struct {
+        name   string
+        data   string
+        format string
+        want   string
+    }{
+        {"unknown field", "plyers: {count: 3}", "yaml", "plyers"},
+        {"unknown currency", "currencies: {XYZ: 1}", "yaml", "XYZ"},
+        {"no weight", "currencies: {EUR: 0}", "yaml", "positive weight"},
+        {"win rate", "win_rate: 1.5", "yaml", "win_rate"},
+        {"bet range", "session: {bets: {min: 5, max: 2}}", "yaml", "session.bets"},
+        {"distribution", "bet_size: {distribution: normal}", "yaml", "unknown distribution"},
+        {"override", "bet_size: {distribution: fixed, value: 1, currencies: {BTC: {distribution: fixed}}}", "yaml", "currencies.BTC"},
+        {"burst", "bursts: [{every: 1m, duration: 2m, factor: 2}]", "yaml", "bursts[0]"},
+        {"duration", `{"session": {"think_time": 5}}`, "json", "duration"},
+        {"format", "seed: 1", "toml", "format"},
+    }
+
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            _, err := ParseScenario([]byte(tt.data), tt.format)
+            if err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("ParseScenario() error = %v, want it to mention %q", err, tt.want)
+            }
+        })
+    }
+}
+
+func TestDefaultScenarioIsValid(t *testing.T) {
+    if err := DefaultScenario().Validate(); err != nil {
+        t.Errorf("Validate() error = %v", err)
+    }
+}
+
+func TestLoadScenarioExample(t *testing.T) {
+    s, err := LoadScenario("../../scenarios/sessions.yaml")
+    if err != nil {
+        t.Fatalf("LoadScenario() error = %v", err)
+    }
+    if s.Seed != 42 || !s.Realtime || len(s.Bursts) != 1 {
+        t.Errorf("scenario = %+v", s)
+    }
+}
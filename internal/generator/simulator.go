@@ -0,0 +1,265 @@
+package generator
+
+import (
+    "container/heap"
+    "context"
+    "log"
+    "math/rand"
+    "sort"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Simulator generates events from player sessions instead of independent
+// random events. Each player moves through
+//
+//  logged out → login → [deposit] → game_start → bet… → game_stop → …
+//
+// playing Session.Games games per login before logging out again. There is
+// no login event type, so logging in only starts the session. Players act
+// on a simulated clock, one at a time in created_at order, so sessions of
+// different players interleave but a player's own events are always in
+// order and every bet belongs to a started game.
+type Simulator struct {
+    scenario Scenario
+}
+
+func NewSimulator(s Scenario) *Simulator {
+    return &Simulator{scenario: s}
+}
+
+// Generate runs the simulation from the start. With the same seed and start
+// time every call yields the same events.
+func (g *Simulator) Generate(ctx context.Context) <-chan casino.Event {
+    eventCh := make(chan casino.Event)
+    sc := g.scenario
+    if sc.Seed == 0 {
+        sc.Seed = time.Now().UnixNano()
+        log.Printf("Simulating with random seed %d", sc.Seed)
+    }
+    if sc.Start.IsZero() {
+        sc.Start = time.Now()
+    }
+    sim := newSimulation(sc)
+    began := time.Now()
+
+    go func() {
+        defer close(eventCh)
+
+        for {
+            event := sim.next()
+            if sc.Realtime {
+                wait := time.NewTimer(time.Until(began.Add(event.CreatedAt.Sub(sc.Start))))
+                select {
+                case <-ctx.Done():
+                    wait.Stop()
+                    return
+                case <-wait.C:
+                }
+            }
+
+            select {
+            case <-ctx.Done():
+                return
+            case eventCh <- event:
+            }
+        }
+    }()
+
+    return eventCh
+}
+
+type state int
+
+const (
+    loggedOut state = iota
+    depositing
+    starting
+    betting
+    stopping
+)
+
+type player struct {
+    id       int
+    currency string
+    state    state
+    at       time.Time // time of the next action
+    game     int
+    games    int // games left in this session
+    bets     int // bets left in this game
+    index    int
+}
+
+// simulation is the state of one run. It is not safe for concurrent use.
+type simulation struct {
+    sc      Scenario
+    rng     *rand.Rand
+    players playerQueue
+    id      int
+
+    currencies []string
+    weights    []float64 // cumulative weights of currencies
+}
+
+func newSimulation(sc Scenario) *simulation {
+    s := &simulation{sc: sc, rng: rand.New(rand.NewSource(sc.Seed))}
+
+    // Sorted, so that the mix does not depend on map order.
+    for c := range sc.Currencies {
+        s.currencies = append(s.currencies, c)
+    }
+    sort.Strings(s.currencies)
+    var total float64
+    for _, c := range s.currencies {
+        total += sc.Currencies[c]
+        s.weights = append(s.weights, total)
+    }
+
+    for i := 0; i < sc.Players.Count; i++ {
+        p := &player{
+            id:       sc.Players.FirstID + i,
+            currency: s.currency(),
+            state:    loggedOut,
+        }
+        // Stagger the first logins over one idle time.
+        p.at = sc.Start.Add(time.Duration(s.rng.Float64() * float64(sc.Session.IdleTime)))
+        heap.Push(&s.players, p)
+    }
+    return s
+}
+
+// next advances the earliest player until it produces an event.
+func (s *simulation) next() casino.Event {
+    for {
+        p := s.players[0]
+        event, ok := s.act(p)
+        heap.Fix(&s.players, p.index)
+        if ok {
+            return event
+        }
+    }
+}
+
+// act performs p's next action and schedules the one after it.
+func (s *simulation) act(p *player) (casino.Event, bool) {
+    now := p.at
+    event := casino.Event{PlayerID: p.id, CreatedAt: now}
+    gap := s.sc.Session.ThinkTime
+
+    switch p.state {
+    case loggedOut:
+        p.games = s.between(s.sc.Session.Games)
+        p.state = starting
+        if s.rng.Float64() < *s.sc.Session.DepositProbability {
+            p.state = depositing
+        }
+        p.at = now.Add(s.gap(now, gap))
+        return casino.Event{}, false
+
+    case depositing:
+        event.Type = "deposit"
+        event.Currency = p.currency
+        event.Amount = s.sc.DepositSize.sample(s.rng, p.currency)
+        p.state = starting
+
+    case starting:
+        p.game = s.sc.Games[s.rng.Intn(len(s.sc.Games))]
+        p.bets = s.between(s.sc.Session.Bets)
+        event.Type = "game_start"
+        event.GameID = p.game
+        p.state = betting
+        if p.bets == 0 {
+            p.state = stopping
+        }
+
+    case betting:
+        event.Type = "bet"
+        event.GameID = p.game
+        event.Currency = p.currency
+        event.Amount = s.sc.BetSize.sample(s.rng, p.currency)
+        event.HasWon = s.rng.Float64() < *s.sc.WinRate
+        p.bets--
+        if p.bets == 0 {
+            p.state = stopping
+        }
+
+    case stopping:
+        event.Type = "game_stop"
+        event.GameID = p.game
+        p.games--
+        p.state = starting
+        if p.games == 0 {
+            p.state = loggedOut
+            gap = s.sc.Session.IdleTime
+        }
+    }
+
+    s.id++
+    event.ID = s.id
+    p.at = now.Add(s.gap(now, gap))
+    return event, true
+}
+
+// gap draws an exponential gap with the given mean, shortened during
+// bursts. Gaps are at least a millisecond so that time always moves on.
+func (s *simulation) gap(now time.Time, mean Duration) time.Duration {
+    d := s.rng.ExpFloat64() * float64(mean)
+    elapsed := now.Sub(s.sc.Start)
+    for _, b := range s.sc.Bursts {
+        if elapsed%time.Duration(b.Every) >= time.Duration(b.Every-b.Duration) {
+            d /= b.Factor
+        }
+    }
+    if d < float64(time.Millisecond) {
+        return time.Millisecond
+    }
+    return time.Duration(d)
+}
+
+func (s *simulation) between(r Range) int {
+    return r.Min + s.rng.Intn(r.Max-r.Min+1)
+}
+
+func (s *simulation) currency() string {
+    x := s.rng.Float64() * s.weights[len(s.weights)-1]
+    i := sort.SearchFloat64s(s.weights, x)
+    if i == len(s.currencies) {
+        i--
+    }
+    // Skip zero-weight currencies that share a cumulative weight.
+    for s.sc.Currencies[s.currencies[i]] == 0 {
+        i++
+    }
+    return s.currencies[i]
+}
+
+// playerQueue orders players by their next action, then by id.
+type playerQueue []*player
+
+func (q playerQueue) Len() int { return len(q) }
+
+func (q playerQueue) Less(i, j int) bool {
+    if q[i].at.Equal(q[j].at) {
+        return q[i].id < q[j].id
+    }
+    return q[i].at.Before(q[j].at)
+}
+
+func (q playerQueue) Swap(i, j int) {
+    q[i], q[j] = q[j], q[i]
+    q[i].index = i
+    q[j].index = j
+}
+
+func (q *playerQueue) Push(x interface{}) {
+    p := x.(*player)
+    p.index = len(*q)
+    *q = append(*q, p)
+}
+
+func (q *playerQueue) Pop() interface{} {
+    old := *q
+    p := old[len(old)-1]
+    *q = old[:len(old)-1]
+    return p
+}
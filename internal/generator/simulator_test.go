@@ -0,0 +1,154 @@
+package generator
+
+import (
+    "context"
+    "math/rand"
+    "reflect"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func testScenario() Scenario {
+    s := DefaultScenario()
+    s.Seed = 1
+    s.Start = time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+    return s
+}
+
+func take(t *testing.T, g Generator, n int) []casino.Event {
+    t.Helper()
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    events := make([]casino.Event, 0, n)
+    for event := range g.Generate(ctx) {
+        events = append(events, event)
+        if len(events) == n {
+            break
+        }
+    }
+    return events
+}
+
+func TestSimulatorSessions(t *testing.T) {
+    events := take(t, NewSimulator(testScenario()), 5000)
+
+    playing := make(map[int]int) // player → game in progress
+    deposited := make(map[int]bool)
+    var last time.Time
+    for i, e := range events {
+        if err := e.Validate(); err != nil {
+            t.Fatalf("event %d is invalid: %v", i, err)
+        }
+        if e.ID != i+1 {
+            t.Fatalf("event %d has id %d", i, e.ID)
+        }
+        if e.CreatedAt.Before(last) {
+            t.Fatalf("event %d created at %v, before %v", e.ID, e.CreatedAt, last)
+        }
+        last = e.CreatedAt
+
+        game, inGame := playing[e.PlayerID]
+        switch e.Type {
+        case "deposit":
+            if inGame {
+                t.Fatalf("event %d: player %d deposited during game %d", e.ID, e.PlayerID, game)
+            }
+            deposited[e.PlayerID] = true
+        case "game_start":
+            if inGame {
+                t.Fatalf("event %d: player %d started a game during game %d", e.ID, e.PlayerID, game)
+            }
+            playing[e.PlayerID] = e.GameID
+        case "bet", "game_stop":
+            if !inGame || game != e.GameID {
+                t.Fatalf("event %d: player %d %s on game %d outside its session", e.ID, e.PlayerID, e.Type, e.GameID)
+            }
+            if e.Type == "game_stop" {
+                delete(playing, e.PlayerID)
+            }
+        }
+    }
+
+    if len(deposited) == 0 {
+        t.Error("no player deposited")
+    }
+}
+
+func TestSimulatorReproducible(t *testing.T) {
+    first := take(t, NewSimulator(testScenario()), 500)
+    second := take(t, NewSimulator(testScenario()), 500)
+    if !reflect.DeepEqual(first, second) {
+        t.Error("runs with the same seed differ")
+    }
+
+    other := testScenario()
+    other.Seed = 2
+    if reflect.DeepEqual(first, take(t, NewSimulator(other), 500)) {
+        t.Error("runs with different seeds are equal")
+    }
+}
+
+func TestSimulatorScenario(t *testing.T) {
+    s := testScenario()
+    s.Players = Population{Count: 3, FirstID: 1000}
+    s.Currencies = map[string]float64{"GBP": 1, "EUR": 0}
+    winRate := 1.0
+    s.WinRate = &winRate
+    s.BetSize = Distribution{Kind: Fixed, Value: 2.5}
+
+    for _, e := range take(t, NewSimulator(s), 1000) {
+        if e.PlayerID < 1000 || e.PlayerID > 1002 {
+            t.Fatalf("event %d has player %d outside the population", e.ID, e.PlayerID)
+        }
+        if e.Currency != "" && e.Currency != "GBP" {
+            t.Fatalf("event %d has currency %s", e.ID, e.Currency)
+        }
+        if e.Type == "bet" && (e.Amount != 250 || !e.HasWon) {
+            t.Fatalf("bet %d = %d won %t, want 250 and won", e.ID, e.Amount, e.HasWon)
+        }
+    }
+}
+
+func TestSimulatorBursts(t *testing.T) {
+    s := testScenario()
+    s.Bursts = []Burst{{Every: Duration(10 * time.Minute), Duration: Duration(5 * time.Minute), Factor: 10}}
+
+    inBurst := func(at time.Time) bool {
+        return at.Sub(s.Start)%(10*time.Minute) >= 5*time.Minute
+    }
+    var quiet, burst int
+    for _, e := range take(t, NewSimulator(s), 20000) {
+        if inBurst(e.CreatedAt) {
+            burst++
+        } else {
+            quiet++
+        }
+    }
+    if burst < 3*quiet {
+        t.Errorf("%d events in bursts and %d outside, want bursts to be much busier", burst, quiet)
+    }
+}
+
+func TestDistributionSample(t *testing.T) {
+    rng := testRand()
+    d := Distribution{Kind: LogNormal, Median: 2, Sigma: 1, Min: 1, Max: 5}
+    for i := 0; i < 1000; i++ {
+        if n := d.sample(rng, "EUR"); n < 100 || n > 500 {
+            t.Fatalf("sample = %d, want between 100 and 500", n)
+        }
+    }
+
+    d.Currencies = map[string]Distribution{"BTC": {Kind: Fixed, Value: 0.0001}}
+    if n := d.sample(rng, "BTC"); n != 10000 {
+        t.Errorf("BTC sample = %d, want 10000 satoshi", n)
+    }
+    if n := (Distribution{Kind: Fixed, Value: 0.0001}).sample(rng, "EUR"); n != 1 {
+        t.Errorf("tiny sample = %d, want at least 1", n)
+    }
+}
+
+func testRand() *rand.Rand {
+    return rand.New(rand.NewSource(1))
+}
@@ -0,0 +1,136 @@
+package loadtest
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/nats-io/nats.go"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+)
+
+// Latency summarises end-to-end latencies in milliseconds.
+type Latency struct {
+	Mean float64 `json:"mean_ms"`
+	P50  float64 `json:"p50_ms"`
+	P95  float64 `json:"p95_ms"`
+	P99  float64 `json:"p99_ms"`
+	Max  float64 `json:"max_ms"`
+}
+
+// sample is one enriched event: when it was sent and when it arrived.
+type sample struct {
+	sent     time.Time
+	received time.Time
+}
+
+// Recorder collects the latency of enriched events carrying
+// stream.SentAtHeader. All samples are kept, so percentiles are exact.
+type Recorder struct {
+	mu      sync.Mutex
+	samples []sample
+	ignored int
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+// Subscribe records every enriched event published on nc until ctx is done.
+func (r *Recorder) Subscribe(ctx context.Context, nc *nats.Conn) error {
+	sub, err := nc.Subscribe(stream.EnrichedSubject, func(msg *nats.Msg) {
+		r.Record(msg.Header.Get(stream.SentAtHeader), time.Now())
+	})
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		sub.Unsubscribe()
+	}()
+	return nil
+}
+
+// Record adds an event received at received with the given sent-at
+// header. Events without a valid header, such as those of other
+// publishers, are ignored.
+func (r *Recorder) Record(sentAt string, received time.Time) {
+	sent, err := time.Parse(time.RFC3339Nano, sentAt)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err != nil {
+		r.ignored++
+		return
+	}
+	r.samples = append(r.samples, sample{sent: sent, received: received})
+}
+
+// Count returns how many events have been recorded.
+func (r *Recorder) Count() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.samples)
+}
+
+// Ignored returns how many enriched events had no sent-at header.
+func (r *Recorder) Ignored() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ignored
+}
+
+// between returns the latencies of events sent in [from, to), and when the
+// first and last of them arrived.
+func (r *Recorder) between(from, to time.Time) (latencies []time.Duration, first, last time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.samples {
+		if s.sent.Before(from) || !s.sent.Before(to) {
+			continue
+		}
+		latencies = append(latencies, s.received.Sub(s.sent))
+		if first.IsZero() || s.received.Before(first) {
+			first = s.received
+		}
+		if s.received.After(last) {
+			last = s.received
+		}
+	}
+	return latencies, first, last
+}
+
+// summarise computes the latency summary of ds, which it sorts.
+func summarise(ds []time.Duration) Latency {
+	if len(ds) == 0 {
+		return Latency{}
+	}
+	sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+	var total time.Duration
+	for _, d := range ds {
+		total += d
+	}
+	return Latency{
+		Mean: ms(total / time.Duration(len(ds))),
+		P50:  ms(percentile(ds, 50)),
+		P95:  ms(percentile(ds, 95)),
+		P99:  ms(percentile(ds, 99)),
+		Max:  ms(ds[len(ds)-1]),
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// percentile returns the nearest-rank percentile p of sorted ds.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
@@ -0,0 +1,134 @@
+package loadtest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// DefaultMaxInFlight bounds concurrent sends.
+const DefaultMaxInFlight = 1000
+
+// PublishFunc sends one event, such as publisher.Service.PublishEvent.
+type PublishFunc func(ctx context.Context, event casino.Event) error
+
+// StepResult is what the publisher did during one step.
+type StepResult struct {
+	Step
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	Sent  int64     `json:"sent"`
+	// Failed sends returned an error.
+	Failed int64 `json:"failed"`
+	// Missed sends were due while MaxInFlight sends were outstanding and
+	// were skipped to keep the schedule.
+	Missed int64 `json:"missed"`
+}
+
+// SendRate is the achieved rate of successful sends.
+func (r StepResult) SendRate() float64 {
+	d := r.End.Sub(r.Start).Seconds()
+	if d <= 0 {
+		return 0
+	}
+	return float64(r.Sent) / d
+}
+
+// Publisher runs a plan of steps.
+type Publisher struct {
+	publish     PublishFunc
+	maxInFlight int
+}
+
+func NewPublisher(publish PublishFunc) *Publisher {
+	return &Publisher{publish: publish, maxInFlight: DefaultMaxInFlight}
+}
+
+// SetMaxInFlight bounds how many sends may be outstanding at once.
+func (p *Publisher) SetMaxInFlight(n int) {
+	if n < 1 {
+		n = 1
+	}
+	p.maxInFlight = n
+}
+
+// Run publishes events from the channel following steps and returns one
+// result per step run. It stops early when ctx is done or events is closed.
+func (p *Publisher) Run(ctx context.Context, steps []Step, events <-chan casino.Event) []StepResult {
+	inFlight := make(chan struct{}, p.maxInFlight)
+	var wg sync.WaitGroup
+
+	limiter := rate.NewLimiter(0, 1)
+	var running []*StepResult
+	for _, step := range steps {
+		// A burst of a tenth of a second absorbs scheduling jitter
+		// without front-loading the step.
+		burst := int(step.Rate / 10)
+		if burst < 1 {
+			burst = 1
+		}
+		limiter.SetBurst(burst)
+		limiter.SetLimit(rate.Limit(step.Rate))
+
+		res := &StepResult{Step: step, Start: time.Now()}
+		stepCtx, cancel := context.WithTimeout(ctx, step.Duration)
+		done := p.runStep(stepCtx, limiter, events, inFlight, &wg, res)
+		cancel()
+		res.End = time.Now()
+		running = append(running, res)
+		if done || ctx.Err() != nil {
+			break
+		}
+	}
+
+	wg.Wait()
+	results := make([]StepResult, len(running))
+	for i, res := range running {
+		results[i] = *res
+	}
+	return results
+}
+
+// runStep sends until ctx is done. It reports whether events ran out.
+func (p *Publisher) runStep(ctx context.Context, limiter *rate.Limiter, events <-chan casino.Event, inFlight chan struct{}, wg *sync.WaitGroup, res *StepResult) bool {
+	for {
+		if err := limiter.Wait(ctx); err != nil {
+			// The next token is due after the step ends.
+			<-ctx.Done()
+			return false
+		}
+		var event casino.Event
+		var ok bool
+		select {
+		case event, ok = <-events:
+			if !ok {
+				return true
+			}
+		case <-ctx.Done():
+			return false
+		}
+
+		select {
+		case inFlight <- struct{}{}:
+		default:
+			atomic.AddInt64(&res.Missed, 1)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-inFlight }()
+			// Sends in flight at the end of a step still count for it.
+			if err := p.publish(context.Background(), event); err != nil {
+				atomic.AddInt64(&res.Failed, 1)
+				return
+			}
+			atomic.AddInt64(&res.Sent, 1)
+		}()
+	}
+}
@@ -0,0 +1,81 @@
+package loadtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// endless returns a channel of events that is never exhausted.
+func endless(ctx context.Context) <-chan casino.Event {
+	ch := make(chan casino.Event)
+	go func() {
+		for id := 1; ; id++ {
+			select {
+			case ch <- casino.Event{ID: id}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+func TestPublisherRate(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	p := NewPublisher(func(context.Context, casino.Event) error { return nil })
+	results := p.Run(ctx, []Step{{Rate: 100, Duration: 300 * time.Millisecond}, {Rate: 400, Duration: 300 * time.Millisecond}}, endless(ctx))
+
+	if len(results) != 2 {
+		t.Fatalf("got %d results, want 2", len(results))
+	}
+	// 30 and 120 events plus the initial bursts, with slack for slow machines.
+	for i, want := range []int64{30, 120} {
+		if got := results[i].Sent; got < want*6/10 || got > want*15/10 {
+			t.Errorf("step %d sent %d, want about %d", i+1, got, want)
+		}
+	}
+}
+
+func TestPublisherOpenLoop(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Sends never finish in time, so every due send beyond the in-flight
+	// limit is missed rather than delaying the schedule.
+	release := make(chan struct{})
+	p := NewPublisher(func(context.Context, casino.Event) error {
+		<-release
+		return errors.New("timeout")
+	})
+	p.SetMaxInFlight(5)
+	time.AfterFunc(300*time.Millisecond, func() { close(release) })
+
+	results := p.Run(ctx, []Step{{Rate: 200, Duration: 200 * time.Millisecond}}, endless(ctx))
+	res := results[0]
+	if res.Failed != 5 || res.Sent != 0 {
+		t.Errorf("sent %d and failed %d, want 0 and 5", res.Sent, res.Failed)
+	}
+	if res.Missed < 20 {
+		t.Errorf("missed %d, want the rest of about 40 due sends", res.Missed)
+	}
+}
+
+func TestPublisherEventsExhausted(t *testing.T) {
+	events := make(chan casino.Event, 3)
+	for id := 1; id <= 3; id++ {
+		events <- casino.Event{ID: id}
+	}
+	close(events)
+
+	p := NewPublisher(func(context.Context, casino.Event) error { return nil })
+	results := p.Run(context.Background(), []Step{{Rate: 1000, Duration: time.Minute}, {Rate: 1000, Duration: time.Minute}}, events)
+	if len(results) != 1 || results[0].Sent != 3 {
+		t.Errorf("results = %+v, want one step with 3 sent", results)
+	}
+}
@@ -0,0 +1,106 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Report is the outcome of a load test, per step and in total.
+type Report struct {
+	Start time.Time    `json:"start"`
+	End   time.Time    `json:"end"`
+	Steps []StepReport `json:"steps"`
+	Total StepReport   `json:"total"`
+	// Ignored enriched events carried no send time.
+	Ignored int `json:"ignored"`
+}
+
+// StepReport compares what was sent during a step with what came out
+// enriched. Throughput is received events per second from the start of
+// the step until the last of them arrived.
+type StepReport struct {
+	TargetRate float64 `json:"target_rate"`
+	Duration   string  `json:"duration"`
+	Sent       int64   `json:"sent"`
+	Failed     int64   `json:"failed"`
+	Missed     int64   `json:"missed"`
+	SendRate   float64 `json:"send_rate"`
+	Received   int     `json:"received"`
+	Lost       int64   `json:"lost"`
+	Throughput float64 `json:"throughput"`
+	Latency    Latency `json:"latency"`
+}
+
+// NewReport matches the publisher's step results with the enriched events
+// recorded by r. Events are attributed to the step they were sent in.
+func NewReport(results []StepResult, r *Recorder) Report {
+	report := Report{Steps: make([]StepReport, 0, len(results)), Ignored: r.Ignored()}
+	if len(results) == 0 {
+		return report
+	}
+	report.Start, report.End = results[0].Start, results[len(results)-1].End
+
+	var total StepResult
+	for _, res := range results {
+		report.Steps = append(report.Steps, stepReport(res, r))
+		total.Sent += res.Sent
+		total.Failed += res.Failed
+		total.Missed += res.Missed
+	}
+	total.Start, total.End = report.Start, report.End
+	total.Duration = report.End.Sub(report.Start)
+	report.Total = stepReport(total, r)
+	return report
+}
+
+func stepReport(res StepResult, r *Recorder) StepReport {
+	latencies, _, last := r.between(res.Start, res.End)
+	s := StepReport{
+		TargetRate: res.Rate,
+		Duration:   res.Duration.String(),
+		Sent:       res.Sent,
+		Failed:     res.Failed,
+		Missed:     res.Missed,
+		SendRate:   res.SendRate(),
+		Received:   len(latencies),
+		Lost:       res.Sent - int64(len(latencies)),
+	}
+	if d := last.Sub(res.Start).Seconds(); len(latencies) > 0 && d > 0 {
+		s.Throughput = float64(len(latencies)) / d
+	}
+	s.Latency = summarise(latencies)
+	return s
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteMarkdown writes the report as a Markdown table, one row per step
+// and a total row.
+func (r Report) WriteMarkdown(w io.Writer) error {
+	fmt.Fprintf(w, "# Load test %s\n\n", r.Start.UTC().Format(time.RFC3339))
+	fmt.Fprintf(w, "Ran for %s.", r.End.Sub(r.Start).Round(time.Millisecond))
+	if r.Ignored > 0 {
+		fmt.Fprintf(w, " %d enriched events without a send time were ignored.", r.Ignored)
+	}
+	fmt.Fprint(w, "\n\n")
+	fmt.Fprintln(w, "| Step | Target/s | Duration | Sent | Failed | Missed | Sent/s | Received | Lost | Throughput/s | p50 ms | p95 ms | p99 ms | Max ms |")
+	fmt.Fprintln(w, "|------|---------:|----------|-----:|-------:|-------:|-------:|---------:|-----:|-------------:|-------:|-------:|-------:|-------:|")
+	for i, s := range r.Steps {
+		writeRow(w, fmt.Sprint(i+1), fmt.Sprintf("%.0f", s.TargetRate), s)
+	}
+	_, err := writeRow(w, "Total", "", r.Total)
+	return err
+}
+
+func writeRow(w io.Writer, name, target string, s StepReport) (int, error) {
+	return fmt.Fprintf(w, "| %s | %s | %s | %d | %d | %d | %.1f | %d | %d | %.1f | %.2f | %.2f | %.2f | %.2f |\n",
+		name, target, s.Duration, s.Sent, s.Failed, s.Missed, s.SendRate,
+		s.Received, s.Lost, s.Throughput,
+		s.Latency.P50, s.Latency.P95, s.Latency.P99, s.Latency.Max)
+}
@@ -0,0 +1,73 @@
+package loadtest
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPercentiles(t *testing.T) {
+	var ds []time.Duration
+	for i := 100; i >= 1; i-- {
+		ds = append(ds, time.Duration(i)*time.Millisecond)
+	}
+	got := summarise(ds)
+	want := Latency{Mean: 50.5, P50: 50, P95: 95, P99: 99, Max: 100}
+	if got != want {
+		t.Errorf("summarise() = %+v, want %+v", got, want)
+	}
+	if (summarise(nil) != Latency{}) {
+		t.Error("summarise(nil) is not zero")
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	start := time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+	results := []StepResult{
+		{Step: Step{Rate: 10, Duration: time.Second}, Start: start, End: start.Add(time.Second), Sent: 3},
+		{Step: Step{Rate: 20, Duration: time.Second}, Start: start.Add(time.Second), End: start.Add(2 * time.Second), Sent: 2, Missed: 1},
+	}
+
+	r := NewRecorder()
+	stamp := func(d time.Duration) string { return start.Add(d).Format(time.RFC3339Nano) }
+	r.Record(stamp(0), start.Add(10*time.Millisecond))
+	r.Record(stamp(100*time.Millisecond), start.Add(130*time.Millisecond))
+	r.Record(stamp(500*time.Millisecond), start.Add(1*time.Second))
+	r.Record(stamp(1500*time.Millisecond), start.Add(1600*time.Millisecond))
+	r.Record("", start)
+
+	report := NewReport(results, r)
+	if report.Ignored != 1 {
+		t.Errorf("Ignored = %d, want 1", report.Ignored)
+	}
+
+	first := report.Steps[0]
+	if first.Received != 3 || first.Lost != 0 || first.Latency.P50 != 30 || first.Latency.Max != 500 {
+		t.Errorf("step 1 = %+v", first)
+	}
+	if first.Throughput != 3 {
+		t.Errorf("step 1 throughput = %v, want 3/s", first.Throughput)
+	}
+	second := report.Steps[1]
+	if second.Received != 1 || second.Lost != 1 || second.Missed != 1 || second.Latency.P99 != 100 {
+		t.Errorf("step 2 = %+v", second)
+	}
+	if report.Total.Sent != 5 || report.Total.Received != 4 || report.Total.Duration != "2s" {
+		t.Errorf("total = %+v", report.Total)
+	}
+
+	var md bytes.Buffer
+	if err := report.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"| 1 | 10 | 1s | 3 | 0 | 0 |", "| Total |", "p99 ms"} {
+		if !strings.Contains(md.String(), want) {
+			t.Errorf("Markdown report lacks %q:\n%s", want, md.String())
+		}
+	}
+	var js bytes.Buffer
+	if err := report.WriteJSON(&js); err != nil || !strings.Contains(js.String(), `"p95_ms"`) {
+		t.Errorf("JSON report = %s, %v", js.String(), err)
+	}
+}
@@ -0,0 +1,52 @@
+// Package loadtest publishes events at a target rate and measures how long
+// they take to come out enriched. The publisher side is open-loop: sends
+// follow a token bucket regardless of how fast earlier sends complete, so
+// a slow pipeline shows up as latency instead of a lower send rate.
+package loadtest
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Step publishes at Rate events per second for Duration.
+type Step struct {
+	Rate     float64       `json:"rate"`
+	Duration time.Duration `json:"duration"`
+}
+
+// Ramp returns steps evenly spaced up to target: Ramp(1000, 4, time.Minute)
+// runs 250, 500, 750 and 1000 events per second for a minute each.
+func Ramp(target float64, steps int, each time.Duration) []Step {
+	if steps < 1 {
+		steps = 1
+	}
+	out := make([]Step, steps)
+	for i := range out {
+		out[i] = Step{Rate: target * float64(i+1) / float64(steps), Duration: each}
+	}
+	return out
+}
+
+// ParseSteps parses an explicit plan such as "100:30s,500:1m,1000:1m".
+func ParseSteps(s string) ([]Step, error) {
+	var steps []Step
+	for _, part := range strings.Split(s, ",") {
+		rate, dur, ok := strings.Cut(strings.TrimSpace(part), ":")
+		if !ok {
+			return nil, fmt.Errorf("step %q: want rate:duration", part)
+		}
+		r, err := strconv.ParseFloat(rate, 64)
+		if err != nil || r <= 0 {
+			return nil, fmt.Errorf("step %q: rate must be a positive number", part)
+		}
+		d, err := time.ParseDuration(dur)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("step %q: duration must be positive, such as 30s", part)
+		}
+		steps = append(steps, Step{Rate: r, Duration: d})
+	}
+	return steps, nil
+}
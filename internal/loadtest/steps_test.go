@@ -0,0 +1,32 @@
+package loadtest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestRamp(t *testing.T) {
+	got := Ramp(1000, 4, time.Minute)
+	want := []Step{{250, time.Minute}, {500, time.Minute}, {750, time.Minute}, {1000, time.Minute}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Ramp() = %v, want %v", got, want)
+	}
+}
+
+func TestParseSteps(t *testing.T) {
+	got, err := ParseSteps("100:30s, 500:1m")
+	if err != nil {
+		t.Fatalf("ParseSteps() error = %v", err)
+	}
+	want := []Step{{100, 30 * time.Second}, {500, time.Minute}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseSteps() = %v, want %v", got, want)
+	}
+
+	for _, bad := range []string{"", "100", "0:30s", "fast:30s", "100:soon", "100:-1s"} {
+		if _, err := ParseSteps(bad); err == nil {
+			t.Errorf("ParseSteps(%q) error = nil, want an error", bad)
+		}
+	}
+}
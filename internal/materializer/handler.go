@@ -0,0 +1,82 @@
+package materializer
+
+import (
+    "encoding/json"
+    "errors"
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
+)
+
+// Handler serves GET /leaderboards/{metric}, where metric is bets, wins,
+// deposits, net_loss or biggest_win. Without a window it ranks players over
+// their lifetime. Query parameters, all optional:
+//
+//  n       number of players, 10 by default and at most 100
+//  window  minute, hour or day (tumbling) or a duration such as 1h (sliding)
+//  at      RFC 3339 event time inside the window, the newest by default
+func Handler(s *Service) http.Handler {
+    mux := http.NewServeMux()
+    mux.HandleFunc("GET /leaderboards/{metric}", func(w http.ResponseWriter, r *http.Request) {
+        q := r.URL.Query()
+        metric := r.PathValue("metric")
+        if err := validMetric(metric); err != nil {
+            writeError(w, http.StatusNotFound, err.Error())
+            return
+        }
+
+        n := DefaultLeaderboardSize
+        if v := q.Get("n"); v != "" {
+            var err error
+            if n, err = strconv.Atoi(v); err != nil || n <= 0 || n > MaxLeaderboardSize {
+                writeError(w, http.StatusBadRequest, "invalid n: want an integer from 1 to 100")
+                return
+            }
+        }
+
+        if q.Get("window") == "" {
+            if q.Get("at") != "" {
+                writeError(w, http.StatusBadRequest, "at needs a window")
+                return
+            }
+            board, _ := s.Leaderboard(metric, n)
+            writeJSON(w, http.StatusOK, board)
+            return
+        }
+
+        spec, err := aggregator.ParseWindow(q.Get("window"))
+        if err != nil {
+            writeError(w, http.StatusBadRequest, err.Error())
+            return
+        }
+        var at time.Time
+        if v := q.Get("at"); v != "" {
+            if at, err = time.Parse(time.RFC3339Nano, v); err != nil {
+                writeError(w, http.StatusBadRequest, "invalid at: want an RFC 3339 time")
+                return
+            }
+        }
+
+        board, err := s.WindowLeaderboard(metric, n, spec, at)
+        switch {
+        case errors.Is(err, aggregator.ErrNotRetained), errors.Is(err, ErrNoWindows):
+            writeError(w, http.StatusNotFound, err.Error())
+        case err != nil:
+            writeError(w, http.StatusInternalServerError, err.Error())
+        default:
+            writeJSON(w, http.StatusOK, board)
+        }
+    })
+    return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+    writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,51 @@
+package materializer
+
+import (
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+func TestHandler(t *testing.T) {
+    s := New()
+    for _, e := range []casino.Event{bet(1, 100, true, 0), bet(2, 300, true, time.Minute)} {
+        s.Process(e)
+        s.Observe(e)
+    }
+
+    tests := []struct {
+        path   string
+        status int
+    }{
+        {"/leaderboards/bets", http.StatusOK},
+        {"/leaderboards/net_loss?n=5", http.StatusOK},
+        {"/leaderboards/biggest_win?window=1h", http.StatusOK},
+        {"/leaderboards/wins?window=hour&at=2024-02-24T12:30:00Z", http.StatusOK},
+        {"/leaderboards/losses", http.StatusNotFound},
+        {"/leaderboards/bets?n=0", http.StatusBadRequest},
+        {"/leaderboards/bets?n=1000", http.StatusBadRequest},
+        {"/leaderboards/bets?window=week", http.StatusBadRequest},
+        {"/leaderboards/bets?at=2024-02-24T12:30:00Z", http.StatusBadRequest},
+        {"/leaderboards/bets?window=day&at=2024-02-20T12:00:00Z", http.StatusNotFound},
+    }
+    for _, tt := range tests {
+        rec := httptest.NewRecorder()
+        Handler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.path, nil))
+        if rec.Code != tt.status {
+            t.Errorf("GET %s = %d, want %d: %s", tt.path, rec.Code, tt.status, rec.Body)
+        }
+    }
+
+    rec := httptest.NewRecorder()
+    Handler(s).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/leaderboards/biggest_win?window=5m&n=1", nil))
+    var board Leaderboard
+    if err := json.NewDecoder(rec.Body).Decode(&board); err != nil {
+        t.Fatal(err)
+    }
+    if board.Window != "5m" || len(board.Entries) != 1 || board.Entries[0] != (Entry{Rank: 1, PlayerID: 2, Score: 300}) {
+        t.Errorf("leaderboard = %+v", board)
+    }
+}
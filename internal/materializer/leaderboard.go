@@ -0,0 +1,322 @@
+package materializer
+
+import (
+    "container/heap"
+    "errors"
+    "fmt"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// Leaderboard metrics. Amounts are EUR cents.
+const (
+    MetricBets       = "bets"        // number of bets
+    MetricWins       = "wins"        // number of won bets
+    MetricDeposits   = "deposits"    // sum of deposits
+    MetricNetLoss    = "net_loss"    // sum of bets minus sum of wins
+    MetricBiggestWin = "biggest_win" // largest single win
+)
+
+// Metrics lists the leaderboard metrics in display order.
+var Metrics = []string{MetricBets, MetricWins, MetricDeposits, MetricNetLoss, MetricBiggestWin}
+
+const (
+    DefaultLeaderboardSize = 10
+    MaxLeaderboardSize     = 100
+)
+
+var ErrUnknownMetric = errors.New("unknown leaderboard metric")
+
+func validMetric(metric string) error {
+    for _, m := range Metrics {
+        if m == metric {
+            return nil
+        }
+    }
+    return fmt.Errorf("%w %q: want bets, wins, deposits, net_loss or biggest_win", ErrUnknownMetric, metric)
+}
+
+// score returns the stats' value for metric.
+func (p *PlayerStats) score(metric string) int64 {
+    switch metric {
+    case MetricBets:
+        return p.BetCount
+    case MetricWins:
+        return p.WinCount
+    case MetricDeposits:
+        return p.DepositTotal
+    case MetricNetLoss:
+        return p.BetTotal - p.WinTotal
+    case MetricBiggestWin:
+        return p.BiggestWin
+    }
+    return 0
+}
+
+func (p *PlayerStats) merge(o *PlayerStats) {
+    p.BetCount += o.BetCount
+    p.BetTotal += o.BetTotal
+    p.WinCount += o.WinCount
+    p.WinTotal += o.WinTotal
+    p.DepositTotal += o.DepositTotal
+    if o.BiggestWin > p.BiggestWin {
+        p.BiggestWin = o.BiggestWin
+    }
+}
+
+// Entry is one player on a leaderboard.
+type Entry struct {
+    Rank     int   `json:"rank"`
+    PlayerID int   `json:"player_id"`
+    Score    int64 `json:"score"`
+}
+
+// Leaderboard is the top players by one metric, over the players' lifetime
+// or over a window of event time. Scores are estimates if Approximate.
+type Leaderboard struct {
+    Metric      string     `json:"metric"`
+    Approximate bool       `json:"approximate,omitempty"`
+    Window      string     `json:"window,omitempty"`
+    Start       *time.Time `json:"start,omitempty"`
+    End         *time.Time `json:"end,omitempty"`
+    Entries     []Entry    `json:"entries"`
+}
+
+// ranked is a player's score on a ranking.
+type ranked struct {
+    id    int
+    score int64
+}
+
+// outranks orders players by score, highest first, and ties by player ID.
+func (r ranked) outranks(o ranked) bool {
+    if r.score != o.score {
+        return r.score > o.score
+    }
+    return r.id < o.id
+}
+
+// ranking is a max-heap of the players with a positive score on one
+// metric, indexed by player so that a change to one player's stats is
+// fixed up in O(log players).
+type ranking struct {
+    metric  string
+    entries []ranked
+    index   map[int]int
+}
+
+func newRanking(metric string) *ranking {
+    return &ranking{metric: metric, index: make(map[int]int)}
+}
+
+func (r *ranking) Len() int           { return len(r.entries) }
+func (r *ranking) Less(i, j int) bool { return r.entries[i].outranks(r.entries[j]) }
+
+func (r *ranking) Swap(i, j int) {
+    r.entries[i], r.entries[j] = r.entries[j], r.entries[i]
+    r.index[r.entries[i].id] = i
+    r.index[r.entries[j].id] = j
+}
+
+func (r *ranking) Push(x interface{}) {
+    e := x.(ranked)
+    r.index[e.id] = len(r.entries)
+    r.entries = append(r.entries, e)
+}
+
+func (r *ranking) Pop() interface{} {
+    e := r.entries[len(r.entries)-1]
+    r.entries = r.entries[:len(r.entries)-1]
+    delete(r.index, e.id)
+    return e
+}
+
+// update moves player id to its new score. Players whose score drops to
+// zero or below leave the ranking.
+func (r *ranking) update(id int, stats *PlayerStats) {
+    score := stats.score(r.metric)
+    i, ok := r.index[id]
+    switch {
+    case ok && score > 0:
+        if r.entries[i].score != score {
+            r.entries[i].score = score
+            heap.Fix(r, i)
+        }
+    case ok:
+        heap.Remove(r, i)
+    case score > 0:
+        heap.Push(r, ranked{id: id, score: score})
+    }
+}
+
+// top returns the n highest ranked players. It walks the heap from the
+// root with a frontier of candidates, so it costs O(n log n) whatever the
+// number of players.
+func (r *ranking) top(n int) []Entry {
+    entries := []Entry{}
+    if len(r.entries) == 0 {
+        return entries
+    }
+    frontier := &frontier{ranking: r, items: []int{0}}
+    for len(entries) < n && frontier.Len() > 0 {
+        i := heap.Pop(frontier).(int)
+        e := r.entries[i]
+        entries = append(entries, Entry{Rank: len(entries) + 1, PlayerID: e.id, Score: e.score})
+        for _, child := range []int{2*i + 1, 2*i + 2} {
+            if child < len(r.entries) {
+                heap.Push(frontier, child)
+            }
+        }
+    }
+    return entries
+}
+
+// frontier is a max-heap of positions in a ranking.
+type frontier struct {
+    ranking *ranking
+    items   []int
+}
+
+func (f *frontier) Len() int { return len(f.items) }
+func (f *frontier) Less(i, j int) bool {
+    return f.ranking.entries[f.items[i]].outranks(f.ranking.entries[f.items[j]])
+}
+func (f *frontier) Swap(i, j int)       { f.items[i], f.items[j] = f.items[j], f.items[i] }
+func (f *frontier) Push(x interface{}) { f.items = append(f.items, x.(int)) }
+func (f *frontier) Pop() interface{} {
+    x := f.items[len(f.items)-1]
+    f.items = f.items[:len(f.items)-1]
+    return x
+}
+
+// bottom is a min-heap of the best n players seen so far, used to select
+// the top n of a window without sorting every player in it.
+type bottom []ranked
+
+func (b bottom) Len() int            { return len(b) }
+func (b bottom) Less(i, j int) bool  { return b[j].outranks(b[i]) }
+func (b bottom) Swap(i, j int)       { b[i], b[j] = b[j], b[i] }
+func (b *bottom) Push(x interface{}) { *b = append(*b, x.(ranked)) }
+func (b *bottom) Pop() interface{} {
+    old := *b
+    x := old[len(old)-1]
+    *b = old[:len(old)-1]
+    return x
+}
+
+// topOf returns the n highest scores of players on metric.
+func topOf(players map[int]*PlayerStats, metric string, n int) []Entry {
+    if n <= 0 {
+        return []Entry{}
+    }
+    best := make(bottom, 0, n)
+    for id, stats := range players {
+        e := ranked{id: id, score: stats.score(metric)}
+        if e.score <= 0 {
+            continue
+        }
+        if len(best) < n {
+            heap.Push(&best, e)
+        } else if e.outranks(best[0]) {
+            best[0] = e
+            heap.Fix(&best, 0)
+        }
+    }
+    entries := make([]Entry, len(best))
+    for i := len(entries) - 1; i >= 0; i-- {
+        e := heap.Pop(&best).(ranked)
+        entries[i] = Entry{Rank: i + 1, PlayerID: e.id, Score: e.score}
+    }
+    return entries
+}
+
+// Leaderboard returns the top n players by metric over their lifetime.
+func (s *Service) Leaderboard(metric string, n int) (Leaderboard, error) {
+    if err := validMetric(metric); err != nil {
+        return Leaderboard{}, err
+    }
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    return Leaderboard{Metric: metric, Approximate: s.sketches != nil, Entries: s.top(metric, n)}, nil
+}
+
+// top returns the top n players by metric. The caller holds s.mu.
+func (s *Service) top(metric string, n int) []Entry {
+    if s.sketches != nil {
+        return s.sketches.top(metric, n)
+    }
+    return s.rankings[metric].top(n)
+}
+
+// WindowLeaderboard returns the top n players by metric over a window of
+// event time, the window spec at the given time as in aggregator.Query. A
+// zero at means the newest event time observed.
+func (s *Service) WindowLeaderboard(metric string, n int, spec aggregator.WindowSpec, at time.Time) (Leaderboard, error) {
+    if err := validMetric(metric); err != nil {
+        return Leaderboard{}, err
+    }
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    if s.sketches != nil {
+        return Leaderboard{}, ErrNoWindows
+    }
+
+    if at.IsZero() {
+        at = s.latest
+        if at.IsZero() {
+            at = time.Now()
+        }
+    }
+    start, end := spec.Bounds(at)
+    if !s.latest.IsZero() && start.Before(s.latest.Add(-s.retention).Truncate(aggregator.Pane)) {
+        return Leaderboard{}, aggregator.ErrNotRetained
+    }
+
+    players := make(map[int]*PlayerStats)
+    for key, pane := range s.panes {
+        paneStart := time.Unix(key, 0)
+        if paneStart.Before(start) || !paneStart.Before(end) {
+            continue
+        }
+        for id, stats := range pane {
+            playerStats(players, id).merge(stats)
+        }
+    }
+    return Leaderboard{
+        Metric:  metric,
+        Window:  spec.Name,
+        Start:   &start,
+        End:     &end,
+        Entries: topOf(players, metric, n),
+    }, nil
+}
+
+// observePane adds event to the player's stats in the pane of its event
+// time and drops panes older than the retention. Events older than the
+// retention are ignored; younger ones are added however late, as deciding
+// which late events count is left to the watermark's policy. The caller
+// holds s.mu.
+func (s *Service) observePane(event casino.Event) {
+    at := event.CreatedAt
+    oldest := s.latest.Add(-s.retention).Truncate(aggregator.Pane)
+    if at.Before(oldest) {
+        return
+    }
+    if oldest.Unix() > s.pruned {
+        for key := range s.panes {
+            if key < oldest.Unix() {
+                delete(s.panes, key)
+            }
+        }
+        s.pruned = oldest.Unix()
+    }
+
+    key := at.Truncate(aggregator.Pane).Unix()
+    pane, ok := s.panes[key]
+    if !ok {
+        pane = make(map[int]*PlayerStats)
+        s.panes[key] = pane
+    }
+    playerStats(pane, event.PlayerID).add(event)
+}
@@ -0,0 +1,164 @@
+package materializer
+
+import (
+    "math/rand"
+    "sort"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+var leaderboardBase = time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+
+func bet(player int, eur int64, won bool, offset time.Duration) casino.Event {
+    return casino.Event{PlayerID: player, Type: "bet", AmountEUR: money.EUR(eur), HasWon: won, CreatedAt: leaderboardBase.Add(offset)}
+}
+
+func deposit(player int, eur int64, offset time.Duration) casino.Event {
+    return casino.Event{PlayerID: player, Type: "deposit", AmountEUR: money.EUR(eur), CreatedAt: leaderboardBase.Add(offset)}
+}
+
+func TestLeaderboard(t *testing.T) {
+    s := New()
+    for _, e := range []casino.Event{
+        bet(1, 100, false, 0),
+        bet(1, 100, false, 0),
+        bet(1, 500, true, 0),
+        bet(2, 5000, false, 0),
+        bet(2, 2000, true, 0),
+        bet(3, 300, true, 0),
+        deposit(3, 10000, 0),
+        deposit(4, 20000, 0),
+    } {
+        s.Process(e)
+    }
+
+    tests := []struct {
+        metric string
+        want   []Entry
+    }{
+        // Bets are ranked by count, not amount.
+        {MetricBets, []Entry{{1, 1, 3}, {2, 2, 2}, {3, 3, 1}}},
+        // Ties go to the lower player ID.
+        {MetricWins, []Entry{{1, 1, 1}, {2, 2, 1}, {3, 3, 1}}},
+        {MetricDeposits, []Entry{{1, 4, 20000}, {2, 3, 10000}}},
+        // Players who won more than they bet are not on it.
+        {MetricNetLoss, []Entry{{1, 2, 5000}, {2, 1, 200}}},
+        {MetricBiggestWin, []Entry{{1, 2, 2000}, {2, 1, 500}, {3, 3, 300}}},
+    }
+    for _, tt := range tests {
+        board, err := s.Leaderboard(tt.metric, 10)
+        if err != nil {
+            t.Fatalf("Leaderboard(%s) error = %v", tt.metric, err)
+        }
+        if !equalEntries(board.Entries, tt.want) {
+            t.Errorf("Leaderboard(%s) = %v, want %v", tt.metric, board.Entries, tt.want)
+        }
+    }
+
+    if board, _ := s.Leaderboard(MetricBets, 2); len(board.Entries) != 2 {
+        t.Errorf("Leaderboard(bets, 2) has %d entries", len(board.Entries))
+    }
+    if _, err := s.Leaderboard("losses", 10); err == nil {
+        t.Error("Leaderboard(losses) error = nil")
+    }
+
+    data := s.GetData()
+    if data.TopPlayerBets != (TopPlayer{ID: 1, Count: 3}) {
+        t.Errorf("TopPlayerBets = %+v", data.TopPlayerBets)
+    }
+    if data.TopPlayerDeposits != (TopPlayer{ID: 4, Count: 20000}) {
+        t.Errorf("TopPlayerDeposits = %+v", data.TopPlayerDeposits)
+    }
+}
+
+// TestRankingMatchesSort checks the incremental rankings against a full
+// sort after random updates, including net losses that go down again.
+func TestRankingMatchesSort(t *testing.T) {
+    rng := rand.New(rand.NewSource(1))
+    s := New()
+    for i := 0; i < 5000; i++ {
+        player := rng.Intn(200) + 1
+        if rng.Intn(5) == 0 {
+            s.Process(deposit(player, int64(rng.Intn(10000)), 0))
+        } else {
+            s.Process(bet(player, int64(rng.Intn(1000)+1), rng.Intn(3) == 0, 0))
+        }
+    }
+
+    for _, metric := range Metrics {
+        var want []ranked
+        for id, stats := range s.playerStats {
+            if score := stats.score(metric); score > 0 {
+                want = append(want, ranked{id: id, score: score})
+            }
+        }
+        sort.Slice(want, func(i, j int) bool { return want[i].outranks(want[j]) })
+
+        board, _ := s.Leaderboard(metric, 25)
+        if len(board.Entries) != 25 {
+            t.Fatalf("Leaderboard(%s) has %d entries, want 25", metric, len(board.Entries))
+        }
+        for i, e := range board.Entries {
+            if e.PlayerID != want[i].id || e.Score != want[i].score || e.Rank != i+1 {
+                t.Errorf("Leaderboard(%s)[%d] = %+v, want player %d with %d", metric, i, e, want[i].id, want[i].score)
+            }
+        }
+    }
+}
+
+func TestWindowLeaderboard(t *testing.T) {
+    s := New()
+    for _, e := range []casino.Event{
+        bet(1, 100, false, 0),
+        bet(1, 100, false, time.Minute),
+        bet(1, 100, false, 2*time.Minute),
+        bet(2, 100, false, 58*time.Minute),
+        bet(2, 100, false, 59*time.Minute),
+        bet(3, 100, false, 61*time.Minute),
+    } {
+        s.Observe(e)
+    }
+
+    hour, _ := aggregator.ParseWindow("hour")
+    board, err := s.WindowLeaderboard(MetricBets, 10, hour, leaderboardBase)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if want := []Entry{{1, 1, 3}, {2, 2, 2}}; !equalEntries(board.Entries, want) {
+        t.Errorf("tumbling hour = %v, want %v", board.Entries, want)
+    }
+    if !board.Start.Equal(leaderboardBase) || !board.End.Equal(leaderboardBase.Add(time.Hour)) {
+        t.Errorf("tumbling hour = [%v, %v)", board.Start, board.End)
+    }
+
+    // The sliding hour ends with the newest minute, 13:01.
+    sliding, _ := aggregator.ParseWindow("1h")
+    board, _ = s.WindowLeaderboard(MetricBets, 10, sliding, time.Time{})
+    if want := []Entry{{1, 2, 2}, {2, 1, 1}, {3, 3, 1}}; !equalEntries(board.Entries, want) {
+        t.Errorf("sliding hour = %v, want %v", board.Entries, want)
+    }
+
+    s.SetRetention(time.Hour)
+    s.Observe(bet(3, 100, false, 3*time.Hour))
+    if _, err := s.WindowLeaderboard(MetricBets, 10, hour, leaderboardBase); err != aggregator.ErrNotRetained {
+        t.Errorf("expired window error = %v, want ErrNotRetained", err)
+    }
+    if len(s.panes) != 1 {
+        t.Errorf("%d panes retained, want 1", len(s.panes))
+    }
+}
+
+func equalEntries(got, want []Entry) bool {
+    if len(got) != len(want) {
+        return false
+    }
+    for i := range got {
+        if got[i] != want[i] {
+            return false
+        }
+    }
+    return true
+}
@@ -4,10 +4,13 @@ import (
     "sync"
     "time"
     "fmt"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
 )
 
+// TopPlayer is the first entry of a lifetime leaderboard: bets and wins are
+// counts, deposits EUR cents.
 type TopPlayer struct {
     ID    int   `json:"id"`
     Count int64 `json:"count"`
@@ -25,28 +28,86 @@ type MaterializedData struct {
 type Service struct {
     data          *MaterializedData
     playerStats   map[int]*PlayerStats
-    eventTimes    []time.Time // Ring buffer for moving average
-    currentIndex  int
-    startTime     time.Time
+    rankings      map[string]*ranking
+    sketches      *sketchBoards // replace playerStats and rankings if set
     mu            sync.RWMutex
+
+    // Event-time rates, fed by Observe. perSecond counts events per second
+    // of the last minute, keyed by seconds[i] in Unix seconds.
+    perSecond     [60]int64
+    seconds       [60]int64
+    observed      int64
+    first, latest time.Time // latest is at most the wall clock
+    now           func() time.Time
+
+    // Player stats per minute of event time for windowed leaderboards,
+    // keyed by the pane's start in Unix seconds.
+    panes         map[int64]map[int]*PlayerStats
+    retention     time.Duration
+    pruned        int64
 }
 
+// Totals are in EUR cents.
 type PlayerStats struct {
-    BetTotal     float64  // Track total bet amount in EUR
+    BetCount     int64
+    BetTotal     int64  // Track total bet amount in EUR
     WinCount     int64
-    WinTotal     float64  // Track total win amount in EUR
+    WinTotal     int64  // Track total win amount in EUR
+    BiggestWin   int64
     DepositTotal int64
 }
 
+func (p *PlayerStats) add(event casino.Event) {
+    switch event.Type {
+    case "bet":
+        p.BetCount++
+        p.BetTotal += event.AmountEUR.Amount
+        if event.HasWon {
+            p.WinCount++
+            p.WinTotal += event.AmountEUR.Amount
+            if event.AmountEUR.Amount > p.BiggestWin {
+                p.BiggestWin = event.AmountEUR.Amount
+            }
+        }
+    case "deposit":
+        p.DepositTotal += event.AmountEUR.Amount
+    }
+}
+
+func playerStats(players map[int]*PlayerStats, id int) *PlayerStats {
+    stats, ok := players[id]
+    if !ok {
+        stats = &PlayerStats{}
+        players[id] = stats
+    }
+    return stats
+}
+
 func New() *Service {
+    rankings := make(map[string]*ranking, len(Metrics))
+    for _, metric := range Metrics {
+        rankings[metric] = newRanking(metric)
+    }
     return &Service{
         data: &MaterializedData{},
         playerStats: make(map[int]*PlayerStats),
-        eventTimes: make([]time.Time, 60), // 60 second buffer
-        startTime: time.Now(),
+        rankings: rankings,
+        panes: make(map[int64]map[int]*PlayerStats),
+        retention: aggregator.DefaultRetention,
+        now: time.Now,
     }
 }
 
+// SetRetention sets how much event time windowed leaderboards keep,
+// aggregator.DefaultRetention by default. It must be called before the
+// first event. Retention is also the lateness bound for leaderboards: the
+// watermark drops late events before they get here unless its policy is
+// watermark.Update, and then any event still inside the retention changes
+// the windows it falls in, even ones already read.
+func (s *Service) SetRetention(retention time.Duration) {
+    s.retention = retention
+}
+
 func (s *Service) Process(event casino.Event) {
     s.mu.Lock()
     defer s.mu.Unlock()
@@ -54,71 +115,87 @@ func (s *Service) Process(event casino.Event) {
     // Update total events
     s.data.EventsTotal++
 
-    // Update moving average buffer
-    now := time.Now()
-    s.eventTimes[s.currentIndex] = now
-    s.currentIndex = (s.currentIndex + 1) % len(s.eventTimes)
-
-    // Get or create player stats
-    stats, ok := s.playerStats[event.PlayerID]
-    if !ok {
-        stats = &PlayerStats{}
-        s.playerStats[event.PlayerID] = stats
-    }
+    if s.sketches != nil {
+        s.sketches.add(event)
+    } else {
+        // Update player stats
+        stats := playerStats(s.playerStats, event.PlayerID)
+        stats.add(event)
 
-    // Update player stats
-    switch event.Type {
-    case "bet":
-        stats.BetTotal += event.AmountEUR  // Track bet amount
-        if event.HasWon {
-            stats.WinCount++
-            stats.WinTotal += event.AmountEUR  // Track win amount
+        // Move the player on the leaderboards, O(log players) each
+        for _, r := range s.rankings {
+            r.update(event.PlayerID, stats)
         }
-    case "deposit":
-        stats.DepositTotal += int64(event.AmountEUR)
     }
 
     // Update top players
     s.updateTopPlayers()
+}
+
+// Observe updates the rates over event time. Events should come in
+// created_at order, as released by a watermark.Buffer, so that replayed
+// or delayed events count at the time they happened. Late events passed
+// on by its update policy still count if they are within the last minute.
+// Events created after the wall clock do not move the newest event time.
+func (s *Service) Observe(event casino.Event) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    at := event.CreatedAt
+    s.observed++
+    if s.first.IsZero() || at.Before(s.first) {
+        s.first = at
+    }
+    if at.After(s.latest) && !at.After(s.now()) {
+        s.latest = at
+    }
+
+    latest := s.latest.Unix()
+    if sec := at.Unix(); sec > latest-60 {
+        i := sec % 60
+        if s.seconds[i] != sec {
+            s.seconds[i] = sec
+            s.perSecond[i] = 0
+        }
+        s.perSecond[i]++
+    }
 
-    // Calculate events per minute
-    duration := now.Sub(s.startTime).Minutes()
-    if duration > 0 {
-        s.data.EventsPerMinute = float64(s.data.EventsTotal) / duration
+    // Events per minute over the event time seen, at least a minute so
+    // that the first events do not spike the rate.
+    span := s.latest.Sub(s.first)
+    if span < time.Minute {
+        span = time.Minute
     }
+    s.data.EventsPerMinute = float64(s.observed) / span.Minutes()
 
-    // Calculate moving average
-    count := 0
-    oneMinuteAgo := now.Add(-time.Minute)
-    for _, t := range s.eventTimes {
-        if t.After(oneMinuteAgo) {
-            count++
+    // Moving average over the minute up to the newest event
+    var count int64
+    for i, sec := range s.seconds {
+        if sec > latest-60 && sec <= latest {
+            count += s.perSecond[i]
         }
     }
     s.data.EventsPerSecondMovingAverage = float64(count) / 60.0
+    metrics.EventsPerSecond.Set(s.data.EventsPerSecondMovingAverage)
+
+    if s.sketches == nil {
+        s.observePane(event)
+    }
 }
 
+// updateTopPlayers copies the leaders of the lifetime leaderboards into
+// the top_player_* fields.
 func (s *Service) updateTopPlayers() {
     var topBets, topWins, topDeposits TopPlayer
 
-    for playerID, stats := range s.playerStats {
-        // Compare bet amounts
-        if int64(stats.BetTotal) > topBets.Count {
-            topBets.ID = playerID
-            topBets.Count = int64(stats.BetTotal)
-        }
-
-        // Compare win amounts
-        if int64(stats.WinTotal) > topWins.Count {
-            topWins.ID = playerID
-            topWins.Count = int64(stats.WinTotal)
-        }
-
-        // Compare deposit totals
-        if stats.DepositTotal > topDeposits.Count {
-            topDeposits.ID = playerID
-            topDeposits.Count = stats.DepositTotal
-        }
+    if top := s.top(MetricBets, 1); len(top) > 0 {
+        topBets = TopPlayer{ID: top[0].PlayerID, Count: top[0].Score}
+    }
+    if top := s.top(MetricWins, 1); len(top) > 0 {
+        topWins = TopPlayer{ID: top[0].PlayerID, Count: top[0].Score}
+    }
+    if top := s.top(MetricDeposits, 1); len(top) > 0 {
+        topDeposits = TopPlayer{ID: top[0].PlayerID, Count: top[0].Score}
     }
 
     s.data.TopPlayerBets = topBets
@@ -129,8 +206,6 @@ func (s *Service) updateTopPlayers() {
 
     s.data.TopPlayerDeposits = topDeposits
     metrics.TopPlayerDeposits.WithLabelValues(fmt.Sprintf("%d", topDeposits.ID)).Set(float64(topDeposits.Count))
-
-    metrics.EventsPerSecond.Set(s.data.EventsPerSecondMovingAverage)
 }
 
 func (s *Service) GetData() MaterializedData {
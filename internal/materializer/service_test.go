@@ -2,7 +2,9 @@ package materializer
 
 import (
     "testing"
+    "time"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
 )
 
 func TestMaterializer(t *testing.T) {
@@ -10,10 +12,10 @@ func TestMaterializer(t *testing.T) {
 
     // Process some test events
     events := []casino.Event{
-        {ID: 1, PlayerID: 1, Type: "bet", AmountEUR: 100, HasWon: true},
-        {ID: 2, PlayerID: 1, Type: "bet", AmountEUR: 100, HasWon: false},
-        {ID: 3, PlayerID: 2, Type: "deposit", AmountEUR: 1000},
-        {ID: 4, PlayerID: 2, Type: "bet", AmountEUR: 100, HasWon: true},
+        {ID: 1, PlayerID: 1, Type: "bet", AmountEUR: money.EUR(100), HasWon: true},
+        {ID: 2, PlayerID: 1, Type: "bet", AmountEUR: money.EUR(100), HasWon: false},
+        {ID: 3, PlayerID: 2, Type: "deposit", AmountEUR: money.EUR(1000)},
+        {ID: 4, PlayerID: 2, Type: "bet", AmountEUR: money.EUR(100), HasWon: true},
     }
 
     for _, e := range events {
@@ -45,4 +47,35 @@ func TestMaterializer(t *testing.T) {
         t.Errorf("Expected player 2 with 1000 deposits, got player %d with %d", 
             data.TopPlayerDeposits.ID, data.TopPlayerDeposits.Count)
     }
-} 
\ No newline at end of file
+} 
+
+func TestMaterializerEventTimeRates(t *testing.T) {
+    s := New()
+    base := time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+
+    // 120 events over two minutes of event time, replayed in an instant.
+    for i := 0; i < 120; i++ {
+        s.Observe(casino.Event{ID: i + 1, PlayerID: 1, Type: "game_start", CreatedAt: base.Add(time.Duration(i) * time.Second)})
+    }
+    data := s.GetData()
+    if got := data.EventsPerMinute; got < 60 || got > 61 {
+        t.Errorf("EventsPerMinute = %v, want about 60", got)
+    }
+    if got := data.EventsPerSecondMovingAverage; got != 1 {
+        t.Errorf("EventsPerSecondMovingAverage = %v, want 1", got)
+    }
+
+    // A burst in the newest second doubles the last minute's average.
+    for i := 0; i < 60; i++ {
+        s.Observe(casino.Event{PlayerID: 1, Type: "game_start", CreatedAt: base.Add(119 * time.Second)})
+    }
+    if got := s.GetData().EventsPerSecondMovingAverage; got != 2 {
+        t.Errorf("EventsPerSecondMovingAverage after burst = %v, want 2", got)
+    }
+
+    // An event older than the last minute does not count in the average.
+    s.Observe(casino.Event{PlayerID: 1, Type: "game_start", CreatedAt: base})
+    if got := s.GetData().EventsPerSecondMovingAverage; got != 2 {
+        t.Errorf("EventsPerSecondMovingAverage after an old event = %v, want 2", got)
+    }
+}
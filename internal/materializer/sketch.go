@@ -0,0 +1,80 @@
+package materializer
+
+import (
+    "errors"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
+)
+
+// ErrNoWindows is returned for windowed leaderboards with sketches, which
+// keep no per-player stats to window.
+var ErrNoWindows = errors.New("windowed leaderboards are not kept with sketches")
+
+// sketchBoards estimates the leaderboards in bounded memory: Count-Min
+// sketches estimate each player's totals and a TopK per metric keeps the
+// heaviest players. Scores are estimates. Counts and sums never
+// undercount and exceed the true value by at most epsilon times the
+// stream's total with probability 1-delta; net loss is the difference of
+// two such estimates, so its error goes either way.
+type sketchBoards struct {
+    bets, wins, deposits *sketch.CountMin
+    betsEUR, winsEUR     *sketch.CountMin
+    biggestWin           *sketch.CountMin // keeps maxima
+    tops                 map[string]*sketch.TopK
+}
+
+func newSketchBoards(cfg sketch.Config) *sketchBoards {
+    b := &sketchBoards{
+        bets:       cfg.NewCountMin(),
+        wins:       cfg.NewCountMin(),
+        deposits:   cfg.NewCountMin(),
+        betsEUR:    cfg.NewCountMin(),
+        winsEUR:    cfg.NewCountMin(),
+        biggestWin: cfg.NewCountMin(),
+        tops:       make(map[string]*sketch.TopK, len(Metrics)),
+    }
+    for _, metric := range Metrics {
+        b.tops[metric] = cfg.NewTopK()
+    }
+    return b
+}
+
+func (b *sketchBoards) add(event casino.Event) {
+    key := uint64(event.PlayerID)
+    amount := event.AmountEUR.Amount
+    switch event.Type {
+    case "bet":
+        b.tops[MetricBets].Offer(key, b.bets.Add(key, 1))
+        bets := b.betsEUR.Add(key, amount)
+        wins := b.winsEUR.Estimate(key)
+        if event.HasWon {
+            b.tops[MetricWins].Offer(key, b.wins.Add(key, 1))
+            b.tops[MetricBiggestWin].Offer(key, b.biggestWin.Max(key, amount))
+            wins = b.winsEUR.Add(key, amount)
+        }
+        b.tops[MetricNetLoss].Offer(key, bets-wins)
+    case "deposit":
+        b.tops[MetricDeposits].Offer(key, b.deposits.Add(key, amount))
+    }
+}
+
+func (b *sketchBoards) top(metric string, n int) []Entry {
+    items := b.tops[metric].Top(n)
+    entries := make([]Entry, len(items))
+    for i, item := range items {
+        entries[i] = Entry{Rank: i + 1, PlayerID: int(item.Key), Score: item.Estimate}
+    }
+    return entries
+}
+
+// SetSketches replaces the per-player stats and exact leaderboards with
+// sketches sized by cfg; at most cfg.TopK players are ranked. Windowed
+// leaderboards are not kept. It must be called before the first event.
+func (s *Service) SetSketches(cfg sketch.Config) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.sketches = newSketchBoards(cfg)
+    s.playerStats = nil
+    s.rankings = nil
+    s.panes = nil
+}
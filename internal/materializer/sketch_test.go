@@ -0,0 +1,96 @@
+package materializer
+
+import (
+    "context"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/generator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
+)
+
+func TestSketchLeaderboardsAgainstExact(t *testing.T) {
+    sc := generator.DefaultScenario()
+    sc.Seed = 1
+    sc.Start = leaderboardBase
+    sc.Players = generator.Population{Count: 20000, FirstID: 1}
+
+    cfg := sketch.DefaultConfig()
+    exact, approx := New(), New()
+    approx.SetSketches(cfg)
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    events := 0
+    for e := range generator.NewSimulator(sc).Generate(ctx) {
+        // Amounts stand in for their EUR value.
+        e.AmountEUR = money.EUR(int64(e.Amount))
+        exact.Process(e)
+        approx.Process(e)
+        if events++; events == 100000 {
+            break
+        }
+    }
+    if approx.playerStats != nil {
+        t.Error("per-player stats kept with sketches")
+    }
+
+    // The Count-Min bound: epsilon times the total of the sketched values.
+    // Biggest wins are only bounded from below.
+    bound := func(f func(*PlayerStats) int64) int64 {
+        var sum int64
+        for _, p := range exact.playerStats {
+            sum += f(p)
+        }
+        return int64(cfg.CMSEpsilon * float64(sum))
+    }
+    bounds := map[string]int64{
+        MetricBets:     bound(func(p *PlayerStats) int64 { return p.BetCount }),
+        MetricWins:     bound(func(p *PlayerStats) int64 { return p.WinCount }),
+        MetricDeposits: bound(func(p *PlayerStats) int64 { return p.DepositTotal }),
+        MetricNetLoss:  bound(func(p *PlayerStats) int64 { return p.BetTotal + p.WinTotal }),
+    }
+    const n = 10
+    for _, metric := range Metrics {
+        got, _ := approx.Leaderboard(metric, n)
+        want, _ := exact.Leaderboard(metric, MaxLeaderboardSize)
+        if !got.Approximate || len(got.Entries) != n {
+            t.Fatalf("%s: %d entries, approximate %v", metric, len(got.Entries), got.Approximate)
+        }
+
+        exactScore := func(id int) int64 { return exact.playerStats[id].score(metric) }
+        for _, e := range got.Entries {
+            diff := e.Score - exactScore(e.PlayerID)
+            if metric != MetricNetLoss && diff < 0 {
+                t.Errorf("%s: player %d estimate %d undercounts %d", metric, e.PlayerID, e.Score, exactScore(e.PlayerID))
+            }
+            if metric != MetricBiggestWin && (diff > bounds[metric] || -diff > bounds[metric]) {
+                t.Errorf("%s: player %d estimate %d, exact %d, bound %d", metric, e.PlayerID, e.Score, exactScore(e.PlayerID), bounds[metric])
+            }
+        }
+
+        // Every player clearly above the n-th exact score is found.
+        found := make(map[int]bool)
+        for _, e := range got.Entries {
+            found[e.PlayerID] = true
+        }
+        cutoff := want.Entries[n-1].Score + 2*bounds[metric]
+        for _, e := range want.Entries[:n] {
+            if e.Score > cutoff && !found[e.PlayerID] {
+                t.Errorf("%s: player %d with %d missing", metric, e.PlayerID, e.Score)
+            }
+        }
+    }
+
+    if got, want := approx.GetData().TopPlayerBets, exact.GetData().TopPlayerBets; got.Count < want.Count {
+        t.Errorf("TopPlayerBets = %+v, exact %+v", got, want)
+    }
+
+    hour, _ := aggregator.ParseWindow("hour")
+    approx.Observe(casino.Event{PlayerID: 1, Type: "bet", CreatedAt: time.Now()})
+    if _, err := approx.WindowLeaderboard(MetricBets, n, hour, time.Time{}); err != ErrNoWindows {
+        t.Errorf("WindowLeaderboard() error = %v, want ErrNoWindows", err)
+    }
+}
@@ -41,6 +41,198 @@ var (
 		Help: "The total number of enrichment errors",
 	})
 
+	EnrichmentStages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_enrichment_stage_total",
+		Help: "The total number of enrichment stage runs by outcome",
+	}, []string{"stage", "status"})
+
+	EnrichmentStageDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "casino_enrichment_stage_duration_seconds",
+		Help:    "Time spent in each enrichment stage",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"stage"})
+
+	DeadLetters = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_dead_letters_total",
+		Help: "The total number of events sent to the dead-letter subject",
+	}, []string{"enricher"})
+
+	InvalidEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_invalid_events_total",
+		Help: "Events rejected by schema validation, by reason",
+	}, []string{"reason"})
+
+	EventsBySchemaVersion = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_events_by_schema_version_total",
+		Help: "Received events by envelope schema version (0 = bare event)",
+	}, []string{"version"})
+
+	EventsByCodec = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_events_by_codec_total",
+		Help: "Received events by wire format (json or protobuf)",
+	}, []string{"codec"})
+
+	// Deduplication metrics
+	DuplicateEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "casino_duplicate_events_total",
+		Help: "Events dropped because they were already processed",
+	})
+
+	DedupWindowSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_dedup_window_size",
+		Help: "Processed event keys remembered by the dedup window",
+	})
+
+	// Event time metrics
+	LateEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_late_events_total",
+		Help: "Events that arrived behind the watermark, by late event policy",
+	}, []string{"policy"})
+
+	FutureEvents = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "casino_future_events_total",
+		Help: "Events created further ahead of the wall clock than the allowed lateness",
+	})
+
+	Watermark = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_watermark_seconds",
+		Help: "Current event-time watermark as a Unix timestamp",
+	})
+
+	EventTimeBuffered = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_event_time_buffered",
+		Help: "Events held back until the watermark passes them",
+	})
+
+	// Aggregator metrics
+	AggregatePanes = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_aggregator_panes",
+		Help: "One-minute panes of windowed aggregates held in memory",
+	})
+
+	// Event store metrics
+	EventStoreWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_event_store_writes_total",
+		Help: "Enriched events written to the event store by result",
+	}, []string{"result"})
+
+	EventStoreDuplicates = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "casino_event_store_duplicates_total",
+		Help: "Events not written to the event store because their event key was already stored",
+	})
+
+	EventStoreBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_event_store_batch_size",
+		Help:    "Events written per COPY batch",
+		Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200, 500},
+	})
+
+	EventStoreWriteDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_event_store_write_duration_seconds",
+		Help:    "Duration of event store batch writes",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// Worker pool metrics
+	WorkerPoolSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_worker_pool_size",
+		Help: "Number of subscriber workers",
+	})
+
+	WorkerQueueCapacity = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_worker_queue_capacity",
+		Help: "Maximum number of events queued per worker",
+	})
+
+	WorkerQueueDepth = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "casino_worker_queue_depth",
+		Help: "Number of events waiting in each worker queue",
+	}, []string{"worker"})
+
+	WorkersBusy = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_workers_busy",
+		Help: "Number of workers currently processing an event",
+	})
+
+	WorkerBackpressure = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "casino_worker_backpressure_total",
+		Help: "The total number of events that waited for a full worker queue",
+	})
+
+	WorkerBackpressureWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_worker_backpressure_wait_seconds",
+		Help:    "Time spent waiting for space in a full worker queue",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// Exchange rate metrics
+	ExchangeRateLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_exchange_rate_lookups_total",
+		Help: "Exchange rate lookups by result (hit, stale, db, miss, negative)",
+	}, []string{"result"})
+
+	ExchangeRateRefreshes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_exchange_rate_refreshes_total",
+		Help: "Exchange rate refreshes by result (success, error, throttled, coalesced)",
+	}, []string{"result"})
+
+	ExchangeRateRefreshDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_exchange_rate_refresh_duration_seconds",
+		Help:    "Time taken to fetch and store exchange rates from the provider chain",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// Player lookup batching metrics
+	PlayerBatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_player_batch_size",
+		Help:    "Distinct player IDs resolved per batched lookup",
+		Buckets: []float64{1, 2, 5, 10, 20, 50, 100, 200},
+	})
+
+	PlayerBatchWait = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_player_batch_wait_seconds",
+		Help:    "Time from a player batch opening until it is flushed",
+		Buckets: []float64{.0005, .001, .002, .005, .01, .02, .05},
+	})
+
+	PlayerBatchQueryDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "casino_player_batch_query_duration_seconds",
+		Help:    "Duration of batched player queries",
+		Buckets: prometheus.DefBuckets,
+	})
+
+	// Player replica metrics
+	PlayerLookups = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_player_lookups_total",
+		Help: "Player lookups by source (replica or db)",
+	}, []string{"source"})
+
+	PlayerReplicaReady = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_player_replica_ready",
+		Help: "Whether the in-memory player replica is in sync (1) or lookups fall back to the database (0)",
+	})
+
+	PlayerReplicaSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_player_replica_size",
+		Help: "Number of players held in the in-memory replica",
+	})
+
+	PlayerReplicaNotifications = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_player_replica_notifications_total",
+		Help: "Player change notifications applied to the replica, by operation",
+	}, []string{"op"})
+
+	// Game catalogue metrics
+	GameCatalogueSize = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "casino_game_catalogue_size",
+		Help: "Games in the in-process catalogue",
+	})
+
+	GameCatalogueReloads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "casino_game_catalogue_reloads_total",
+		Help: "Game catalogue reloads from the database, by result",
+	}, []string{"result"})
+
 	ProcessingTime = promauto.NewHistogram(prometheus.HistogramOpts{
 		Name:    "casino_event_processing_duration_seconds",
 		Help:    "Time spent processing events",
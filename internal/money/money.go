@@ -0,0 +1,195 @@
+// Package money represents amounts as integers in a currency's minor unit
+// (cents for EUR, satoshi for BTC) and converts between currencies exactly.
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var ErrUnknownCurrency = errors.New("unknown currency")
+
+// Exponents maps currency codes to the number of decimal places of their
+// minor unit: 300 EUR minor units are 3.00 EUR, 1 BTC minor unit is 0.00000001 BTC.
+var Exponents = map[string]int{
+	"EUR": 2,
+	"USD": 2,
+	"GBP": 2,
+	"NZD": 2,
+	"AUD": 2,
+	"CAD": 2,
+	"CHF": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"BTC": 8,
+}
+
+func Exponent(currency string) (int, error) {
+	exp, ok := Exponents[currency]
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrUnknownCurrency, currency)
+	}
+	return exp, nil
+}
+
+// Money is an amount in minor units of Currency.
+//
+// In JSON a Money is encoded as its bare minor-unit amount, so existing
+// keys such as amount_eur keep their numeric form; the currency is implied
+// by the field and is not decoded.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// EUR returns an amount of euro cents.
+func EUR(cents int64) Money {
+	return Money{Amount: cents, Currency: "EUR"}
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+// Add returns m+o. Both must be in the same currency; an empty currency
+// (a decoded or zero value) adopts the other's.
+func (m Money) Add(o Money) (Money, error) {
+	cur := m.Currency
+	switch {
+	case cur == "":
+		cur = o.Currency
+	case o.Currency != "" && o.Currency != cur:
+		return Money{}, fmt.Errorf("cannot add %s to %s", o.Currency, cur)
+	}
+	return Money{Amount: m.Amount + o.Amount, Currency: cur}, nil
+}
+
+// Decimal formats m in major units with the currency's decimal places,
+// e.g. "3.00" for 300 EUR minor units.
+func (m Money) Decimal() string {
+	exp, err := Exponent(m.Currency)
+	if err != nil || exp == 0 {
+		return strconv.FormatInt(m.Amount, 10)
+	}
+
+	sign := ""
+	abs := m.Amount
+	if abs < 0 {
+		sign = "-"
+		abs = -abs
+	}
+	digits := strconv.FormatInt(abs, 10)
+	if len(digits) <= exp {
+		digits = strings.Repeat("0", exp-len(digits)+1) + digits
+	}
+	cut := len(digits) - exp
+	return sign + digits[:cut] + "." + digits[cut:]
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.FormatInt(m.Amount, 10)), nil
+}
+
+// UnmarshalJSON accepts integers and, for compatibility with producers
+// that used floating point, numbers with a fraction rounded half to even.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '"' {
+		return fmt.Errorf("money: amount must be a number, got %s", data)
+	}
+	var n json.Number
+	if err := json.Unmarshal(data, &n); err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+	if i, err := n.Int64(); err == nil {
+		m.Amount = i
+		return nil
+	}
+	f, err := n.Float64()
+	if err != nil {
+		return fmt.Errorf("money: %w", err)
+	}
+	m.Amount = int64(math.RoundToEven(f))
+	return nil
+}
+
+// Rate is an exact exchange rate: units of the target currency per one
+// unit of the source currency, both in major units.
+type Rate struct {
+	r *big.Rat
+}
+
+// RateFromFloat converts f using its shortest decimal representation, so
+// 0.91 becomes exactly 91/100 rather than the nearest binary fraction.
+func RateFromFloat(f float64) (Rate, error) {
+	if f <= 0 || math.IsNaN(f) || math.IsInf(f, 0) {
+		return Rate{}, fmt.Errorf("invalid rate %v", f)
+	}
+	return ParseRate(strconv.FormatFloat(f, 'g', -1, 64))
+}
+
+// ParseRate parses a decimal string such as "1.0834".
+func ParseRate(s string) (Rate, error) {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() <= 0 {
+		return Rate{}, fmt.Errorf("invalid rate %q", s)
+	}
+	return Rate{r: r}, nil
+}
+
+func (r Rate) String() string {
+	if r.r == nil {
+		return "0"
+	}
+	return r.r.FloatString(10)
+}
+
+// Convert returns m in currency to, multiplying by rate and scaling between
+// the currencies' minor units, with the result rounded using mode.
+func Convert(m Money, to string, rate Rate, mode RoundingMode) (Money, error) {
+	if rate.r == nil {
+		return Money{}, errors.New("zero rate")
+	}
+	fromExp, err := Exponent(m.Currency)
+	if err != nil {
+		return Money{}, err
+	}
+	toExp, err := Exponent(to)
+	if err != nil {
+		return Money{}, err
+	}
+
+	v := new(big.Rat).SetInt64(m.Amount)
+	v.Mul(v, rate.r)
+	scale := new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(abs(toExp-fromExp))), nil))
+	if toExp >= fromExp {
+		v.Mul(v, scale)
+	} else {
+		v.Quo(v, scale)
+	}
+
+	amount, err := round(v, mode)
+	if err != nil {
+		return Money{}, fmt.Errorf("failed to convert %s to %s: %w", m, to, err)
+	}
+	return Money{Amount: amount, Currency: to}, nil
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
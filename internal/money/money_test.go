@@ -0,0 +1,153 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		name   string
+		amount Money
+		rate   string
+		mode   RoundingMode
+		want   int64
+	}{
+		{name: "USD cents", amount: New(1000, "USD"), rate: "0.91", want: 910},
+		{name: "BTC satoshi", amount: New(100000, "BTC"), rate: "35000", want: 3500},
+		{name: "one satoshi rounds to zero", amount: New(1, "BTC"), rate: "35000", want: 0},
+		{name: "JPY has no minor unit", amount: New(1000, "JPY"), rate: "0.0062", want: 620},
+		{name: "no float drift", amount: New(10, "USD"), rate: "0.1", mode: HalfUp, want: 1},
+		{name: "half even down", amount: New(150, "USD"), rate: "0.91", want: 136}, // 136.5
+		{name: "half even up", amount: New(250, "USD"), rate: "0.91", want: 228},   // 227.5
+		{name: "half up", amount: New(150, "USD"), rate: "0.91", mode: HalfUp, want: 137},
+		{name: "down", amount: New(199, "USD"), rate: "0.5", mode: Down, want: 99}, // 99.5
+		{name: "up", amount: New(101, "USD"), rate: "0.5", mode: Up, want: 51},     // 50.5
+		{name: "negative half up", amount: New(-150, "USD"), rate: "0.91", mode: HalfUp, want: -137},
+		{name: "negative down", amount: New(-199, "USD"), rate: "0.5", mode: Down, want: -99},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rate, err := ParseRate(tt.rate)
+			if err != nil {
+				t.Fatalf("ParseRate() error = %v", err)
+			}
+			got, err := Convert(tt.amount, "EUR", rate, tt.mode)
+			if err != nil {
+				t.Fatalf("Convert() error = %v", err)
+			}
+			if got.Amount != tt.want || got.Currency != "EUR" {
+				t.Errorf("Convert() = %v, want %d EUR cents", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	rate, _ := ParseRate("1")
+	if _, err := Convert(New(1, "XYZ"), "EUR", rate, HalfEven); err == nil {
+		t.Error("Convert() expected error for unknown currency")
+	}
+	if _, err := Convert(New(1, "USD"), "EUR", Rate{}, HalfEven); err == nil {
+		t.Error("Convert() expected error for zero rate")
+	}
+	big, _ := ParseRate("1000000")
+	if _, err := Convert(New(1<<62, "EUR"), "EUR", big, HalfEven); err == nil {
+		t.Error("Convert() expected overflow error")
+	}
+	if _, err := RateFromFloat(0); err == nil {
+		t.Error("RateFromFloat(0) expected error")
+	}
+}
+
+func TestRateFromFloatIsExact(t *testing.T) {
+	rate, err := RateFromFloat(0.91)
+	if err != nil {
+		t.Fatalf("RateFromFloat() error = %v", err)
+	}
+	if got := rate.String(); got != "0.9100000000" {
+		t.Errorf("Rate = %s, want 0.91 exactly", got)
+	}
+}
+
+func TestDecimal(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{EUR(300), "3.00"},
+		{EUR(5), "0.05"},
+		{EUR(-1234), "-12.34"},
+		{New(1, "BTC"), "0.00000001"},
+		{New(500, "JPY"), "500"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.Decimal(); got != tt.want {
+			t.Errorf("%#v.Decimal() = %s, want %s", tt.m, got, tt.want)
+		}
+	}
+}
+
+func TestAdd(t *testing.T) {
+	sum, err := Money{}.Add(EUR(150))
+	if err != nil || sum != EUR(150) {
+		t.Errorf("Add() = %v, %v, want 1.50 EUR", sum, err)
+	}
+	if _, err := EUR(1).Add(New(1, "USD")); err == nil {
+		t.Error("Add() expected error for mixed currencies")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	type payload struct {
+		AmountEUR Money `json:"amount_eur"`
+	}
+
+	data, err := json.Marshal(payload{AmountEUR: EUR(910)})
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if string(data) != `{"amount_eur":910}` {
+		t.Errorf("Marshal() = %s", data)
+	}
+
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{`{"amount_eur":910}`, 910},
+		{`{"amount_eur":910.5}`, 910}, // legacy float, half to even
+		{`{"amount_eur":911.5}`, 912},
+		{`{"amount_eur":1e3}`, 1000},
+	}
+	for _, tt := range tests {
+		var p payload
+		if err := json.Unmarshal([]byte(tt.in), &p); err != nil {
+			t.Fatalf("Unmarshal(%s) error = %v", tt.in, err)
+		}
+		if p.AmountEUR.Amount != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, p.AmountEUR.Amount, tt.want)
+		}
+	}
+
+	var p payload
+	if err := json.Unmarshal([]byte(`{"amount_eur":"910"}`), &p); err == nil {
+		t.Error("Unmarshal() expected error for string amount")
+	}
+}
+
+func TestParseRoundingMode(t *testing.T) {
+	for _, name := range []string{"half_even", "half_up", "down", "up"} {
+		mode, err := ParseRoundingMode(name)
+		if err != nil {
+			t.Fatalf("ParseRoundingMode(%s) error = %v", name, err)
+		}
+		if mode.String() != name {
+			t.Errorf("String() = %s, want %s", mode, name)
+		}
+	}
+	if _, err := ParseRoundingMode("bankers"); err == nil {
+		t.Error("ParseRoundingMode() expected error")
+	}
+}
@@ -0,0 +1,87 @@
+package money
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// RoundingMode decides how a converted amount between two minor units is rounded.
+type RoundingMode int
+
+const (
+	// HalfEven rounds to the nearest unit, ties to even (banker's rounding).
+	// It is unbiased over many conversions and is the default.
+	HalfEven RoundingMode = iota
+	// HalfUp rounds to the nearest unit, ties away from zero.
+	HalfUp
+	// Down truncates toward zero.
+	Down
+	// Up rounds away from zero.
+	Up
+)
+
+var roundingNames = map[string]RoundingMode{
+	"half_even": HalfEven,
+	"half_up":   HalfUp,
+	"down":      Down,
+	"up":        Up,
+}
+
+func ParseRoundingMode(s string) (RoundingMode, error) {
+	mode, ok := roundingNames[s]
+	if !ok {
+		return 0, fmt.Errorf("unknown rounding mode %q, want half_even, half_up, down or up", s)
+	}
+	return mode, nil
+}
+
+func (m RoundingMode) String() string {
+	for name, mode := range roundingNames {
+		if mode == m {
+			return name
+		}
+	}
+	return fmt.Sprintf("RoundingMode(%d)", int(m))
+}
+
+var errOverflow = errors.New("amount overflows int64")
+
+// round converts v to an integer using mode.
+func round(v *big.Rat, mode RoundingMode) (int64, error) {
+	num, den := v.Num(), v.Denom()
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int)) // truncated toward zero
+
+	if r.Sign() != 0 {
+		away := false
+		switch mode {
+		case Down:
+		case Up:
+			away = true
+		case HalfUp, HalfEven:
+			// Compare 2|r| with the denominator to find which half we are in.
+			twice := new(big.Int).Abs(r)
+			twice.Lsh(twice, 1)
+			switch twice.Cmp(den) {
+			case 1:
+				away = true
+			case 0:
+				away = mode == HalfUp || q.Bit(0) == 1
+			}
+		default:
+			return 0, fmt.Errorf("unknown rounding mode %d", mode)
+		}
+		if away {
+			if v.Sign() < 0 {
+				q.Sub(q, big.NewInt(1))
+			} else {
+				q.Add(q, big.NewInt(1))
+			}
+		}
+	}
+
+	if !q.IsInt64() {
+		return 0, errOverflow
+	}
+	return q.Int64(), nil
+}
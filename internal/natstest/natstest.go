@@ -0,0 +1,54 @@
+// Package natstest runs an in-process NATS server for tests.
+package natstest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nats-io/nats-server/v2/server"
+)
+
+// RunJetStreamServer starts a NATS server with JetStream enabled on a random
+// port, storing data in a temporary directory. It is shut down with the test.
+func RunJetStreamServer(t testing.TB) *server.Server {
+	t.Helper()
+
+	return run(t, &server.Options{
+		Host:      "127.0.0.1",
+		Port:      -1,
+		JetStream: true,
+		StoreDir:  t.TempDir(),
+		NoLog:     true,
+		NoSigs:    true,
+	})
+}
+
+// RunServer starts a NATS server without JetStream on a random port. It is
+// shut down with the test.
+func RunServer(t testing.TB) *server.Server {
+	t.Helper()
+
+	return run(t, &server.Options{
+		Host:   "127.0.0.1",
+		Port:   -1,
+		NoLog:  true,
+		NoSigs: true,
+	})
+}
+
+func run(t testing.TB, opts *server.Options) *server.Server {
+	t.Helper()
+
+	srv, err := server.NewServer(opts)
+	if err != nil {
+		t.Fatalf("Failed to create NATS server: %v", err)
+	}
+
+	go srv.Start()
+	if !srv.ReadyForConnections(5 * time.Second) {
+		t.Fatal("NATS server not ready")
+	}
+	t.Cleanup(srv.Shutdown)
+
+	return srv
+}
@@ -0,0 +1,222 @@
+package publisher
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/natstest"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+func TestJetStreamPublisher(t *testing.T) {
+	srv := natstest.RunJetStreamServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	mockGen := &mockGenerator{events: make(chan casino.Event, 3)}
+	for id := 1; id <= 3; id++ {
+		mockGen.events <- casino.Event{ID: id, Type: "bet"}
+	}
+	close(mockGen.events)
+
+	pub, err := NewJetStream(ctx, srv.ClientURL(), mockGen)
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
+
+	if err := pub.Start(ctx); err != nil {
+		t.Fatalf("Publisher stopped with error: %v", err)
+	}
+
+	// Every event must be stored in the stream once Start returns.
+	nc, err := nats.Connect(srv.ClientURL())
+	if err != nil {
+		t.Fatalf("Failed to connect to NATS: %v", err)
+	}
+	defer nc.Close()
+
+	js, err := jetstream.New(nc)
+	if err != nil {
+		t.Fatalf("Failed to create JetStream context: %v", err)
+	}
+	s, err := js.Stream(ctx, stream.Name)
+	if err != nil {
+		t.Fatalf("Stream %s not declared: %v", stream.Name, err)
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get stream info: %v", err)
+	}
+	if info.State.Msgs != 3 {
+		t.Fatalf("Expected 3 stored events, got %d", info.State.Msgs)
+	}
+
+	msg, err := s.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to get first message: %v", err)
+	}
+	env, err := envelope.Decode(msg.Data)
+	if err != nil {
+		t.Fatalf("Failed to decode stored event: %v", err)
+	}
+	if env.Event.ID != 1 || msg.Subject != EventsTopic {
+		t.Errorf("First stored message = event %d on %s, want event 1 on %s", env.Event.ID, msg.Subject, EventsTopic)
+	}
+	if env.SchemaVersion != envelope.Version || env.Source != Source || env.EventID == "" {
+		t.Errorf("Stored envelope = %+v, want version %d from %s with an event id", env, envelope.Version, Source)
+	}
+	if ct := msg.Header.Get(codec.Header); ct != codec.ContentTypeJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, codec.ContentTypeJSON)
+	}
+	if id := msg.Header.Get(nats.MsgIdHdr); id != env.EventID {
+		t.Errorf("%s = %q, want the envelope event id %q", nats.MsgIdHdr, id, env.EventID)
+	}
+}
+
+func TestJetStreamPublisherRetryStoredOnce(t *testing.T) {
+	srv := natstest.RunJetStreamServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pub, err := NewJetStream(ctx, srv.ClientURL(), &mockGenerator{})
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
+
+	// A retry after a lost ack resends the same message.
+	msg := nats.NewMsg(EventsTopic)
+	msg.Header.Set(nats.MsgIdHdr, envelope.NewEventID())
+	msg.Data, _ = envelope.New(Source, casino.Event{ID: 1}).Marshal()
+	for i := 0; i < 2; i++ {
+		if err := pub.publishWithAck(ctx, msg); err != nil {
+			t.Fatalf("publishWithAck() error = %v", err)
+		}
+	}
+
+	s, err := pub.js.Stream(ctx, stream.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := s.Info(ctx)
+	if err != nil {
+		t.Fatalf("Failed to get stream info: %v", err)
+	}
+	if info.State.Msgs != 1 {
+		t.Errorf("Stored %d messages, want 1", info.State.Msgs)
+	}
+}
+
+func TestJetStreamPublisherProtobuf(t *testing.T) {
+	srv := natstest.RunJetStreamServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pub, err := NewJetStream(ctx, srv.ClientURL(), &mockGenerator{})
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
+	pub.SetCodec(codec.Protobuf)
+
+	if err := pub.PublishEvent(ctx, casino.Event{ID: 1, PlayerID: 10, Type: "deposit", Amount: 100, Currency: "EUR"}); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	js, err := jetstream.New(pub.nc)
+	if err != nil {
+		t.Fatalf("Failed to create JetStream context: %v", err)
+	}
+	s, err := js.Stream(ctx, stream.Name)
+	if err != nil {
+		t.Fatalf("Stream %s not declared: %v", stream.Name, err)
+	}
+	msg, err := s.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+
+	ct := msg.Header.Get(codec.Header)
+	if ct != codec.ContentTypeProtobuf {
+		t.Fatalf("Content-Type = %q, want %q", ct, codec.ContentTypeProtobuf)
+	}
+	c, err := codec.ForContentType(ct)
+	if err != nil {
+		t.Fatal(err)
+	}
+	env, err := c.UnmarshalEnvelope(msg.Data)
+	if err != nil {
+		t.Fatalf("Failed to decode stored event: %v", err)
+	}
+	if env.Event.ID != 1 || env.Event.PlayerID != 10 || env.Source != Source {
+		t.Errorf("Stored envelope = %+v, want event 1 of player 10 from %s", env, Source)
+	}
+}
+
+func TestJetStreamPublisherStampSentAt(t *testing.T) {
+	srv := natstest.RunJetStreamServer(t)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	pub, err := NewJetStream(ctx, srv.ClientURL(), &mockGenerator{})
+	if err != nil {
+		t.Fatalf("Failed to create publisher: %v", err)
+	}
+	defer pub.Close()
+
+	event := casino.Event{ID: 1, PlayerID: 10, Type: "deposit", Amount: 100, Currency: "EUR"}
+	if err := pub.PublishEvent(ctx, event); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+	pub.SetStampSentAt(true)
+	before := time.Now()
+	if err := pub.PublishEvent(ctx, event); err != nil {
+		t.Fatalf("PublishEvent() error = %v", err)
+	}
+
+	js, err := jetstream.New(pub.nc)
+	if err != nil {
+		t.Fatalf("Failed to create JetStream context: %v", err)
+	}
+	s, err := js.Stream(ctx, stream.Name)
+	if err != nil {
+		t.Fatalf("Stream %s not declared: %v", stream.Name, err)
+	}
+
+	unstamped, err := s.GetMsg(ctx, 1)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	if got := unstamped.Header.Get(stream.SentAtHeader); got != "" {
+		t.Errorf("Unstamped message has %s %q", stream.SentAtHeader, got)
+	}
+
+	stamped, err := s.GetMsg(ctx, 2)
+	if err != nil {
+		t.Fatalf("Failed to get message: %v", err)
+	}
+	sentAt, err := time.Parse(time.RFC3339Nano, stamped.Header.Get(stream.SentAtHeader))
+	if err != nil {
+		t.Fatalf("Invalid %s: %v", stream.SentAtHeader, err)
+	}
+	if sentAt.Before(before) || sentAt.After(time.Now()) {
+		t.Errorf("%s = %v, want the publish time", stream.SentAtHeader, sentAt)
+	}
+}
+
+func TestJetStreamPublisherNoServer(t *testing.T) {
+	if _, err := NewJetStream(context.Background(), "nats://127.0.0.1:1", &mockGenerator{}); err == nil {
+		t.Fatal("Expected error when NATS is unreachable")
+	}
+}
@@ -2,21 +2,36 @@ package publisher
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
 	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
-	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino/generator"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/generator"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
 	"log"
+	"time"
 )
 
 const (
-	EventsTopic = "casino.events"
+	EventsTopic = stream.EventsSubject
+
+	// Source recorded in the envelope of published events.
+	Source = "publisher"
+
+	// Publish attempts before giving up on a JetStream ack.
+	maxPublishAttempts = 5
+	publishRetryWait   = 250 * time.Millisecond
 )
 
 type Service struct {
 	nc *nats.Conn
+	js jetstream.JetStream
 	gen generator.Generator
+	codec codec.Codec
+	source string
+	stampSentAt bool
 }
 
 func New(natsURL string, gen generator.Generator) (*Service, error) {
@@ -28,36 +43,115 @@ func New(natsURL string, gen generator.Generator) (*Service, error) {
 	return &Service{
 		nc: nc,
 		gen: gen,
+		codec: codec.JSON,
+		source: Source,
 	}, nil
 }
 
+// SetCodec selects the wire format of published events, JSON by default.
+// The Content-Type header tells subscribers which one was used.
+func (s *Service) SetCodec(c codec.Codec) {
+	s.codec = c
+}
+
+// NewJetStream creates a publisher that waits for a JetStream ack on every
+// event, declaring the casino events stream if it does not exist yet.
+func NewJetStream(ctx context.Context, natsURL string, gen generator.Generator) (*Service, error) {
+	s, err := New(natsURL, gen)
+	if err != nil {
+		return nil, err
+	}
+
+	js, err := jetstream.New(s.nc)
+	if err != nil {
+		s.Close()
+		return nil, fmt.Errorf("failed to create JetStream context: %w", err)
+	}
+
+	if _, err := stream.Ensure(ctx, js); err != nil {
+		s.Close()
+		return nil, err
+	}
+
+	s.js = js
+	return s, nil
+}
+
 func (s *Service) Start(ctx context.Context) error {
 	events := s.gen.Generate(ctx)
-	
+
 	for event := range events {
 		if err := s.PublishEvent(ctx, event); err != nil {
 			log.Printf("Failed to publish event: %v", err)
 			continue
 		}
 	}
-	
+
 	return nil
 }
 
+// SetSource changes the source recorded in envelopes, Source by default.
+func (s *Service) SetSource(source string) {
+	s.source = source
+}
+
+// SetStampSentAt makes PublishEvent set stream.SentAtHeader on every
+// message, for latency measurements.
+func (s *Service) SetStampSentAt(stamp bool) {
+	s.stampSentAt = stamp
+}
+
+// PublishEvent publishes event wrapped in a versioned envelope.
 func (s *Service) PublishEvent(ctx context.Context, event casino.Event) error {
-	data, err := json.Marshal(event)
+	env := envelope.New(s.source, event)
+	data, err := s.codec.MarshalEnvelope(env)
 	if err != nil {
 		return fmt.Errorf("failed to marshal event: %w", err)
 	}
 
-	if err := s.nc.Publish(EventsTopic, data); err != nil {
+	// Retries resend the same Nats-Msg-Id, so JetStream stores the event
+	// once even if an earlier attempt landed but its ack was lost.
+	msg := nats.NewMsg(EventsTopic)
+	msg.Header.Set(codec.Header, s.codec.ContentType())
+	msg.Header.Set(nats.MsgIdHdr, env.EventID)
+	if s.stampSentAt {
+		msg.Header.Set(stream.SentAtHeader, time.Now().UTC().Format(time.RFC3339Nano))
+	}
+	msg.Data = data
+
+	if s.js != nil {
+		return s.publishWithAck(ctx, msg)
+	}
+
+	if err := s.nc.PublishMsg(msg); err != nil {
 		return fmt.Errorf("failed to publish event: %w", err)
 	}
 
 	return nil
 }
 
+// publishWithAck publishes to JetStream and retries with linear backoff
+// until the server acknowledges the message or the attempts run out.
+func (s *Service) publishWithAck(ctx context.Context, msg *nats.Msg) error {
+	var err error
+	for attempt := 1; attempt <= maxPublishAttempts; attempt++ {
+		if _, err = s.js.PublishMsg(ctx, msg); err == nil {
+			return nil
+		}
+
+		log.Printf("Publish attempt %d/%d failed: %v", attempt, maxPublishAttempts, err)
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to publish event: %w", ctx.Err())
+		case <-time.After(time.Duration(attempt) * publishRetryWait):
+		}
+	}
+
+	return fmt.Errorf("failed to publish event after %d attempts: %w", maxPublishAttempts, err)
+}
+
 func (s *Service) Close() error {
 	s.nc.Close()
 	return nil
-} 
\ No newline at end of file
+}
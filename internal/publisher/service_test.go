@@ -2,8 +2,8 @@ package publisher
 
 import (
 	"context"
-	"encoding/json"
 	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
 	"github.com/nats-io/nats.go"
 	"testing"
 	"time"
@@ -34,12 +34,12 @@ func TestPublisher(t *testing.T) {
 	// Create a subscriber to verify published messages
 	receivedEvents := make(chan casino.Event, 10)
 	sub, err := nc.Subscribe(EventsTopic, func(msg *nats.Msg) {
-		var event casino.Event
-		if err := json.Unmarshal(msg.Data, &event); err != nil {
-			t.Errorf("Failed to unmarshal event: %v", err)
+		env, err := envelope.Decode(msg.Data)
+		if err != nil {
+			t.Errorf("Failed to decode event: %v", err)
 			return
 		}
-		receivedEvents <- event
+		receivedEvents <- env.Event
 	})
 	if err != nil {
 		t.Fatalf("Failed to subscribe: %v", err)
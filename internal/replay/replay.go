@@ -0,0 +1,102 @@
+// Package replay re-runs historical events through the pipeline. Events are
+// read from a Source, stripped of their enrichment and handed to a Sink,
+// either a publisher or an in-process pipeline for dry runs, paced by their
+// created_at times relative to the first event.
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+// AsFastAsPossible is the speed that disables pacing.
+const AsFastAsPossible = 0
+
+// Sink receives replayed events. An error counts the event as failed
+// without stopping the replay.
+type Sink func(ctx context.Context, event casino.Event) error
+
+// Stats summarises a replay.
+type Stats struct {
+	Events int
+	Failed int
+}
+
+type Replayer struct {
+	speed float64
+	now   func() time.Time
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// New returns a replayer at speed times the original pacing: 1 keeps the
+// original gaps between events, 10 makes them ten times shorter and
+// AsFastAsPossible drops them.
+func New(speed float64) *Replayer {
+	if speed < 0 {
+		speed = AsFastAsPossible
+	}
+	return &Replayer{speed: speed, now: time.Now, sleep: sleep}
+}
+
+// Run replays every event of src into sink. It stops at the end of src, on
+// a read error or when ctx is done.
+//
+// Each event is due at the start of the replay plus its created_at offset
+// from the first event, divided by the speed. Run sleeps until then rather
+// than for the gap since the previous event, so time spent reading and in
+// sink does not add up over a long replay.
+func (r *Replayer) Run(ctx context.Context, src Source, sink Sink) (Stats, error) {
+	var stats Stats
+	var start, first time.Time
+	for {
+		event, err := src.Next(ctx)
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		}
+		if err != nil {
+			return stats, err
+		}
+
+		if first.IsZero() {
+			start, first = r.now(), event.CreatedAt
+		}
+		if err := r.sleep(ctx, r.due(start, first, event.CreatedAt).Sub(r.now())); err != nil {
+			return stats, err
+		}
+
+		stats.Events++
+		if err := sink(ctx, event.Raw()); err != nil {
+			log.Printf("Failed to replay event %d: %v", event.ID, err)
+			stats.Failed++
+		}
+	}
+}
+
+// due returns when to replay an event created at at, for a replay started
+// at start with the first event created at first. Events created before the
+// first one are due at once.
+func (r *Replayer) due(start, first, at time.Time) time.Time {
+	if r.speed == AsFastAsPossible || !at.After(first) {
+		return start
+	}
+	return start.Add(time.Duration(float64(at.Sub(first)) / r.speed))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
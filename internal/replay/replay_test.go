@@ -0,0 +1,167 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+)
+
+type sliceSource struct {
+	events []casino.Event
+}
+
+func (s *sliceSource) Next(ctx context.Context) (casino.Event, error) {
+	if len(s.events) == 0 {
+		return casino.Event{}, io.EOF
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
+
+func events(gaps ...time.Duration) []casino.Event {
+	at := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	out := []casino.Event{{ID: 1, CreatedAt: at}}
+	for i, gap := range gaps {
+		at = at.Add(gap)
+		out = append(out, casino.Event{ID: i + 2, CreatedAt: at})
+	}
+	return out
+}
+
+func TestReplayerPacing(t *testing.T) {
+	tests := []struct {
+		name  string
+		speed float64
+		gaps  []time.Duration
+		want  []time.Duration
+	}{
+		{"original", 1, []time.Duration{time.Second, 3 * time.Second}, []time.Duration{0, time.Second, 3 * time.Second}},
+		{"faster", 10, []time.Duration{time.Second, 3 * time.Second}, []time.Duration{0, 100 * time.Millisecond, 300 * time.Millisecond}},
+		{"slower", 0.5, []time.Duration{time.Second}, []time.Duration{0, 2 * time.Second}},
+		{"as fast as possible", AsFastAsPossible, []time.Duration{time.Second, time.Hour}, []time.Duration{0, 0, 0}},
+		{"out of order", 1, []time.Duration{-time.Second, 2 * time.Second}, []time.Duration{0, 0, time.Second}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New(tt.speed)
+			var slept []time.Duration
+			clock := fakeClock(r)
+			r.sleep = func(ctx context.Context, d time.Duration) error {
+				slept = append(slept, max(d, 0))
+				*clock = clock.Add(max(d, 0))
+				return nil
+			}
+
+			stats, err := r.Run(context.Background(), &sliceSource{events: events(tt.gaps...)}, func(context.Context, casino.Event) error { return nil })
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			if stats.Events != len(tt.want) {
+				t.Errorf("Events = %d, want %d", stats.Events, len(tt.want))
+			}
+			if !reflect.DeepEqual(slept, tt.want) {
+				t.Errorf("slept %v, want %v", slept, tt.want)
+			}
+		})
+	}
+}
+
+func TestReplayerDoesNotDrift(t *testing.T) {
+	r := New(1)
+	clock := fakeClock(r)
+	var slept time.Duration
+	r.sleep = func(ctx context.Context, d time.Duration) error {
+		if d > 0 {
+			slept += d
+			*clock = clock.Add(d)
+		}
+		return nil
+	}
+
+	// A second between events, of which the sink takes 300ms.
+	var due []time.Duration
+	start := *clock
+	gaps := []time.Duration{time.Second, time.Second, time.Second}
+	_, err := r.Run(context.Background(), &sliceSource{events: events(gaps...)}, func(context.Context, casino.Event) error {
+		due = append(due, clock.Sub(start))
+		*clock = clock.Add(300 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []time.Duration{0, time.Second, 2 * time.Second, 3 * time.Second}; !reflect.DeepEqual(due, want) {
+		t.Errorf("events replayed at %v, want %v", due, want)
+	}
+	if want := 3 * 700 * time.Millisecond; slept != want {
+		t.Errorf("slept %v, want %v", slept, want)
+	}
+}
+
+// fakeClock makes r read the time from the returned clock.
+func fakeClock(r *Replayer) *time.Time {
+	clock := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	r.now = func() time.Time { return clock }
+	return &clock
+}
+
+func TestReplayerStripsEnrichment(t *testing.T) {
+	enriched := casino.Event{
+		ID: 1, PlayerID: 10, GameID: 100, Type: "bet", Amount: 500, Currency: "USD",
+		AmountEUR:   money.EUR(468),
+		Player:      casino.Player{Email: "john@example.com"},
+		Game:        &casino.Game{ID: 100, Title: "Rocket Dice"},
+		Description: "Player #10 placed a bet.",
+	}
+
+	var got []casino.Event
+	_, err := New(AsFastAsPossible).Run(context.Background(), &sliceSource{events: []casino.Event{enriched}}, func(ctx context.Context, event casino.Event) error {
+		got = append(got, event)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := []casino.Event{enriched.Raw()}; !reflect.DeepEqual(got, want) {
+		t.Errorf("sink got %+v, want %+v", got, want)
+	}
+}
+
+func TestReplayerCountsFailures(t *testing.T) {
+	stats, err := New(AsFastAsPossible).Run(context.Background(), &sliceSource{events: events(time.Second, time.Second)}, func(ctx context.Context, event casino.Event) error {
+		if event.ID == 2 {
+			return errors.New("publish failed")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if want := (Stats{Events: 3, Failed: 1}); stats != want {
+		t.Errorf("stats = %+v, want %+v", stats, want)
+	}
+}
+
+func TestReplayerStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var n int
+	_, err := New(1).Run(ctx, &sliceSource{events: events(time.Hour)}, func(context.Context, casino.Event) error {
+		n++
+		cancel()
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Run() error = %v, want context.Canceled", err)
+	}
+	if n != 1 {
+		t.Errorf("sink called %d times, want 1", n)
+	}
+}
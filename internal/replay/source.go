@@ -0,0 +1,88 @@
+package replay
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/eventstore"
+)
+
+// maxLine bounds a single JSONL line.
+const maxLine = 1 << 20
+
+// Source yields events to replay in order. Next returns io.EOF after the
+// last event.
+type Source interface {
+	Next(ctx context.Context) (casino.Event, error)
+}
+
+type fileSource struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewFileSource reads one event per line, bare or enveloped, as published
+// onto casino.events or written by the replay dry run. Blank lines are
+// skipped.
+func NewFileSource(r io.Reader) Source {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLine)
+	return &fileSource{scanner: scanner}
+}
+
+func (s *fileSource) Next(ctx context.Context) (casino.Event, error) {
+	for s.scanner.Scan() {
+		s.line++
+		line := s.scanner.Bytes()
+		if strings.TrimSpace(string(line)) == "" {
+			continue
+		}
+		env, err := envelope.Decode(line)
+		if err != nil {
+			return casino.Event{}, fmt.Errorf("line %d: %w", s.line, err)
+		}
+		return env.Event, nil
+	}
+	if err := s.scanner.Err(); err != nil {
+		return casino.Event{}, fmt.Errorf("failed to read line %d: %w", s.line+1, err)
+	}
+	return casino.Event{}, io.EOF
+}
+
+type storeSource struct {
+	q      eventstore.Querier
+	filter eventstore.Filter
+	events []casino.Event
+	done   bool
+}
+
+// NewStoreSource pages through the stored events matching f, oldest first.
+func NewStoreSource(q eventstore.Querier, f eventstore.Filter) Source {
+	if f.Limit == 0 {
+		f.Limit = eventstore.MaxLimit
+	}
+	return &storeSource{q: q, filter: f}
+}
+
+func (s *storeSource) Next(ctx context.Context) (casino.Event, error) {
+	for len(s.events) == 0 {
+		if s.done {
+			return casino.Event{}, io.EOF
+		}
+		page, err := s.q.Query(ctx, s.filter)
+		if err != nil {
+			return casino.Event{}, fmt.Errorf("failed to query stored events: %w", err)
+		}
+		s.events = page.Events
+		s.filter.Cursor = page.NextCursor
+		s.done = page.NextCursor == ""
+	}
+	event := s.events[0]
+	s.events = s.events[1:]
+	return event, nil
+}
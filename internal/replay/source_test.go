@@ -0,0 +1,87 @@
+package replay
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/eventstore"
+)
+
+func readAll(t *testing.T, src Source) []casino.Event {
+	t.Helper()
+	var out []casino.Event
+	for {
+		event, err := src.Next(context.Background())
+		if errors.Is(err, io.EOF) {
+			return out
+		}
+		if err != nil {
+			t.Fatalf("Next() error = %v", err)
+		}
+		out = append(out, event)
+	}
+}
+
+func TestFileSource(t *testing.T) {
+	input := `{"id":1,"player_id":10,"type":"game_start","created_at":"2024-01-01T12:00:00Z"}
+
+{"schema_version":1,"event_id":"abc","source":"publisher","produced_at":"2024-01-01T12:00:01Z","event":{"id":2,"player_id":10,"type":"game_stop","created_at":"2024-01-01T12:00:01Z"}}
+`
+	got := readAll(t, NewFileSource(strings.NewReader(input)))
+	if len(got) != 2 {
+		t.Fatalf("got %d events, want 2", len(got))
+	}
+	if got[0].ID != 1 || got[1].ID != 2 || got[1].Type != "game_stop" {
+		t.Errorf("got %+v", got)
+	}
+}
+
+func TestFileSourceBadLine(t *testing.T) {
+	src := NewFileSource(strings.NewReader("{\"id\":1}\nnot json\n"))
+	if _, err := src.Next(context.Background()); err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	_, err := src.Next(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Next() error = %v, want an error for line 2", err)
+	}
+}
+
+type pagedQuerier struct {
+	pages   []eventstore.Page
+	filters []eventstore.Filter
+}
+
+func (q *pagedQuerier) Query(ctx context.Context, f eventstore.Filter) (eventstore.Page, error) {
+	q.filters = append(q.filters, f)
+	page := q.pages[0]
+	q.pages = q.pages[1:]
+	return page, nil
+}
+
+func TestStoreSource(t *testing.T) {
+	q := &pagedQuerier{pages: []eventstore.Page{
+		{Events: []casino.Event{{ID: 1}, {ID: 2}}, NextCursor: "c1"},
+		{Events: []casino.Event{}, NextCursor: "c2"},
+		{Events: []casino.Event{{ID: 3}}},
+	}}
+
+	got := readAll(t, NewStoreSource(q, eventstore.Filter{PlayerID: 10}))
+	if len(got) != 3 || got[0].ID != 1 || got[2].ID != 3 {
+		t.Errorf("got %+v, want events 1, 2 and 3", got)
+	}
+
+	wantCursors := []string{"", "c1", "c2"}
+	if len(q.filters) != len(wantCursors) {
+		t.Fatalf("made %d queries, want %d", len(q.filters), len(wantCursors))
+	}
+	for i, f := range q.filters {
+		if f.Cursor != wantCursors[i] || f.PlayerID != 10 || f.Limit != eventstore.MaxLimit {
+			t.Errorf("query %d filter = %+v", i, f)
+		}
+	}
+}
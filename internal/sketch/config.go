@@ -0,0 +1,57 @@
+package sketch
+
+import (
+	"fmt"
+	"math"
+)
+
+// Config sizes the sketches. Memory per sketch:
+//
+//	HyperLogLog  2^HLLPrecision bytes once dense, less for few keys
+//	CountMin     8·⌈e/CMSEpsilon⌉·⌈ln(1/CMSDelta)⌉ bytes
+//	TopK         about 40·TopK bytes
+//	TDigest      about 16·7·Compression bytes including the buffer
+type Config struct {
+	HLLPrecision uint8
+	CMSEpsilon   float64
+	CMSDelta     float64
+	TopK         int
+	Compression  float64
+}
+
+// DefaultConfig gives a 0.8% standard error for distinct counts, Count-Min
+// estimates within 0.1% of the stream total with 99% probability, the top
+// 100 keys and quantiles of a t-digest with compression 100.
+func DefaultConfig() Config {
+	return Config{
+		HLLPrecision: 14,
+		CMSEpsilon:   0.001,
+		CMSDelta:     0.01,
+		TopK:         100,
+		Compression:  100,
+	}
+}
+
+func (c Config) Validate() error {
+	if c.HLLPrecision < MinPrecision || c.HLLPrecision > MaxPrecision {
+		return fmt.Errorf("HyperLogLog precision %d out of range %d-%d", c.HLLPrecision, MinPrecision, MaxPrecision)
+	}
+	if !(c.CMSEpsilon > 0 && c.CMSEpsilon < 1) {
+		return fmt.Errorf("Count-Min epsilon %v out of range (0, 1)", c.CMSEpsilon)
+	}
+	if !(c.CMSDelta > 0 && c.CMSDelta < 1) {
+		return fmt.Errorf("Count-Min delta %v out of range (0, 1)", c.CMSDelta)
+	}
+	if c.TopK < 1 {
+		return fmt.Errorf("top-k size %d must be positive", c.TopK)
+	}
+	if c.Compression < 10 || math.IsInf(c.Compression, 0) {
+		return fmt.Errorf("t-digest compression %v must be at least 10", c.Compression)
+	}
+	return nil
+}
+
+func (c Config) NewHyperLogLog() *HyperLogLog { return NewHyperLogLog(c.HLLPrecision) }
+func (c Config) NewCountMin() *CountMin       { return NewCountMin(c.CMSEpsilon, c.CMSDelta) }
+func (c Config) NewTopK() *TopK               { return NewTopK(c.TopK) }
+func (c Config) NewTDigest() *TDigest         { return NewTDigest(c.Compression) }
@@ -0,0 +1,74 @@
+package sketch
+
+import "math"
+
+// CountMin estimates per-key totals of non-negative values in width×depth
+// counters. An estimate never undercounts, and with probability 1-delta it
+// overcounts by at most epsilon times the sum of all values added, for
+// width = ⌈e/epsilon⌉ and depth = ⌈ln(1/delta)⌉.
+//
+// A sketch either sums values with Add or keeps their maximum with Max;
+// the estimate is then an upper bound on the key's largest value.
+type CountMin struct {
+	width  uint64
+	depth  int
+	counts []int64
+	total  int64
+}
+
+func NewCountMin(epsilon, delta float64) *CountMin {
+	width := uint64(math.Ceil(math.E / epsilon))
+	depth := int(math.Ceil(math.Log(1 / delta)))
+	if depth < 1 {
+		depth = 1
+	}
+	return &CountMin{width: width, depth: depth, counts: make([]int64, width*uint64(depth))}
+}
+
+// cell returns the counter of key in row i. Each row hashes the key with a
+// different seed.
+func (c *CountMin) cell(key uint64, i int) int {
+	return i*int(c.width) + int(Hash(key^uint64(i+1)*0x9e3779b97f4a7c15)%c.width)
+}
+
+// Add adds n to key's total and returns the new estimate.
+func (c *CountMin) Add(key uint64, n int64) int64 {
+	c.total += n
+	estimate := int64(math.MaxInt64)
+	for i := 0; i < c.depth; i++ {
+		j := c.cell(key, i)
+		c.counts[j] += n
+		estimate = min(estimate, c.counts[j])
+	}
+	return estimate
+}
+
+// Max raises key's value to at least v and returns the new estimate.
+func (c *CountMin) Max(key uint64, v int64) int64 {
+	estimate := int64(math.MaxInt64)
+	for i := 0; i < c.depth; i++ {
+		j := c.cell(key, i)
+		c.counts[j] = max(c.counts[j], v)
+		estimate = min(estimate, c.counts[j])
+	}
+	return estimate
+}
+
+// Estimate returns key's estimated total, or an upper bound on its maximum.
+func (c *CountMin) Estimate(key uint64) int64 {
+	estimate := int64(math.MaxInt64)
+	for i := 0; i < c.depth; i++ {
+		estimate = min(estimate, c.counts[c.cell(key, i)])
+	}
+	return estimate
+}
+
+// Total is the sum of all values added.
+func (c *CountMin) Total() int64 {
+	return c.total
+}
+
+// Bytes is the memory held by the counters.
+func (c *CountMin) Bytes() int {
+	return len(c.counts) * 8
+}
@@ -0,0 +1,157 @@
+// Package sketch holds probabilistic summaries with bounded memory for
+// streams with too many players to track exactly: HyperLogLog for distinct
+// counts, Count-Min for per-key totals, TopK for the heaviest keys and
+// t-digest for quantiles. Their sizes are set by Config.
+package sketch
+
+import (
+	"math"
+	"math/bits"
+)
+
+// Precision bounds for HyperLogLog.
+const (
+	MinPrecision = 4
+	MaxPrecision = 16
+)
+
+// Hash mixes an integer key into 64 well-distributed bits (the SplitMix64
+// finalizer), so that consecutive player IDs spread over the registers.
+func Hash(key uint64) uint64 {
+	key ^= key >> 30
+	key *= 0xbf58476d1ce4e5b9
+	key ^= key >> 27
+	key *= 0x94d049bb133111eb
+	key ^= key >> 31
+	return key
+}
+
+// HyperLogLog estimates the number of distinct keys added with a standard
+// error of about 1.04/√m for m = 2^precision registers. Small sketches keep
+// their non-zero registers in a map and switch to a dense array of m bytes
+// once that would no longer be smaller, so a sketch per window or group
+// costs little while few keys have been added.
+type HyperLogLog struct {
+	p      uint8
+	sparse map[uint32]uint8
+	dense  []uint8
+}
+
+func NewHyperLogLog(precision uint8) *HyperLogLog {
+	if precision < MinPrecision {
+		precision = MinPrecision
+	}
+	if precision > MaxPrecision {
+		precision = MaxPrecision
+	}
+	return &HyperLogLog{p: precision, sparse: make(map[uint32]uint8)}
+}
+
+func (h *HyperLogLog) m() uint32 { return 1 << h.p }
+
+// Add adds key. Adding a key again does not change the estimate.
+func (h *HyperLogLog) Add(key uint64) {
+	x := Hash(key)
+	idx := uint32(x >> (64 - h.p))
+	// The rank is the position of the first 1 bit after the index bits;
+	// the sentinel bit caps it when the rest are all zero.
+	rank := uint8(bits.LeadingZeros64(x<<h.p|1<<(h.p-1))) + 1
+	h.set(idx, rank)
+}
+
+func (h *HyperLogLog) set(idx uint32, rank uint8) {
+	if h.dense != nil {
+		if rank > h.dense[idx] {
+			h.dense[idx] = rank
+		}
+		return
+	}
+	if rank > h.sparse[idx] {
+		h.sparse[idx] = rank
+	}
+	// A map entry takes several bytes; past m/8 entries the array is
+	// smaller.
+	if uint32(len(h.sparse)) > h.m()/8 {
+		h.densify()
+	}
+}
+
+func (h *HyperLogLog) densify() {
+	h.dense = make([]uint8, h.m())
+	for idx, rank := range h.sparse {
+		h.dense[idx] = rank
+	}
+	h.sparse = nil
+}
+
+// Merge adds o's keys to h. Both must have the same precision.
+func (h *HyperLogLog) Merge(o *HyperLogLog) {
+	if o.dense == nil {
+		for idx, rank := range o.sparse {
+			h.set(idx, rank)
+		}
+		return
+	}
+	if h.dense == nil {
+		h.densify()
+	}
+	for idx, rank := range o.dense {
+		if rank > h.dense[idx] {
+			h.dense[idx] = rank
+		}
+	}
+}
+
+// Count returns the estimated number of distinct keys.
+func (h *HyperLogLog) Count() uint64 {
+	m := float64(h.m())
+	var sum float64
+	zeros := 0
+	if h.dense != nil {
+		for _, rank := range h.dense {
+			sum += math.Ldexp(1, -int(rank))
+			if rank == 0 {
+				zeros++
+			}
+		}
+	} else {
+		zeros = int(h.m()) - len(h.sparse)
+		sum = float64(zeros)
+		for _, rank := range h.sparse {
+			sum += math.Ldexp(1, -int(rank))
+		}
+	}
+
+	estimate := alpha(h.m()) * m * m / sum
+	// Small cardinalities: linear counting over the empty registers is
+	// more accurate. 64-bit hashes need no large range correction.
+	if estimate <= 2.5*m && zeros > 0 {
+		estimate = m * math.Log(m/float64(zeros))
+	}
+	return uint64(estimate + 0.5)
+}
+
+// RelativeError is the standard error of Count relative to the true count.
+func (h *HyperLogLog) RelativeError() float64 {
+	return 1.04 / math.Sqrt(float64(h.m()))
+}
+
+// Bytes is the approximate memory held by the registers.
+func (h *HyperLogLog) Bytes() int {
+	if h.dense != nil {
+		return len(h.dense)
+	}
+	return len(h.sparse) * 8
+}
+
+func alpha(m uint32) float64 {
+	switch m {
+	case 16:
+		return 0.673
+	case 32:
+		return 0.697
+	case 64:
+		return 0.709
+	}
+	return 0.7213 / (1 + 1.079/float64(m))
+}
@@ -0,0 +1,226 @@
+package sketch
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestHyperLogLogErrorBound(t *testing.T) {
+	for _, n := range []int{10, 1000, 50000, 500000} {
+		h := NewHyperLogLog(14)
+		for i := 0; i < n; i++ {
+			h.Add(uint64(i))
+			h.Add(uint64(i)) // duplicates do not count
+		}
+		got := float64(h.Count())
+		// Three standard errors.
+		if err := math.Abs(got-float64(n)) / float64(n); err > 3*h.RelativeError() {
+			t.Errorf("Count() of %d keys = %v, relative error %.4f > %.4f", n, got, err, 3*h.RelativeError())
+		}
+		if h.Bytes() > 1<<14 {
+			t.Errorf("%d keys take %d bytes, want at most %d", n, h.Bytes(), 1<<14)
+		}
+	}
+}
+
+func TestHyperLogLogMerge(t *testing.T) {
+	a, b, all := NewHyperLogLog(12), NewHyperLogLog(12), NewHyperLogLog(12)
+	for i := 0; i < 30000; i++ {
+		all.Add(uint64(i))
+		if i < 20000 {
+			a.Add(uint64(i))
+		}
+		if i >= 10000 {
+			b.Add(uint64(i))
+		}
+	}
+	small := NewHyperLogLog(12)
+	small.Add(7)
+	a.Merge(small)
+	a.Merge(b)
+	if a.Count() != all.Count() {
+		t.Errorf("merged Count() = %d, want %d as for one sketch", a.Count(), all.Count())
+	}
+
+	// Sparse sketches merge into sparse ones exactly.
+	c, d := NewHyperLogLog(14), NewHyperLogLog(14)
+	c.Add(1)
+	c.Add(2)
+	d.Add(2)
+	d.Add(3)
+	c.Merge(d)
+	if c.Count() != 3 {
+		t.Errorf("sparse merged Count() = %d, want 3", c.Count())
+	}
+}
+
+// zipf returns a stream of n player IDs where a few players account for
+// much of the activity, as in a real player base.
+func zipf(n int, players uint64) []uint64 {
+	z := rand.NewZipf(rand.New(rand.NewSource(1)), 1.2, 1, players-1)
+	keys := make([]uint64, n)
+	for i := range keys {
+		keys[i] = z.Uint64() + 1
+	}
+	return keys
+}
+
+func TestCountMinErrorBound(t *testing.T) {
+	const epsilon, delta = 0.001, 0.01
+	c := NewCountMin(epsilon, delta)
+	exact := make(map[uint64]int64)
+	for _, key := range zipf(200000, 100000) {
+		c.Add(key, 1)
+		exact[key]++
+	}
+
+	bound := int64(epsilon * float64(c.Total()))
+	over := 0
+	for key, n := range exact {
+		got := c.Estimate(key)
+		if got < n {
+			t.Fatalf("Estimate(%d) = %d undercounts %d", key, got, n)
+		}
+		if got-n > bound {
+			over++
+		}
+	}
+	// At most a delta fraction of keys may exceed the bound.
+	if frac := float64(over) / float64(len(exact)); frac > delta {
+		t.Errorf("%.4f of keys overcount by more than %d, want at most %v", frac, bound, delta)
+	}
+	if want := 8 * 2719 * 5; c.Bytes() != want {
+		t.Errorf("Bytes() = %d, want %d", c.Bytes(), want)
+	}
+}
+
+func TestCountMinMax(t *testing.T) {
+	c := NewCountMin(0.01, 0.01)
+	c.Max(1, 500)
+	c.Max(1, 300)
+	c.Max(2, 100)
+	if got := c.Estimate(1); got != 500 {
+		t.Errorf("Estimate(1) = %d, want 500", got)
+	}
+	if got := c.Estimate(2); got < 100 {
+		t.Errorf("Estimate(2) = %d, want at least 100", got)
+	}
+}
+
+// TestTopKHeavyHitters checks that every key heavier than the error bound
+// above the k-th largest is found, with a Count-Min supplying estimates.
+func TestTopKHeavyHitters(t *testing.T) {
+	const k = 20
+	c := NewCountMin(0.001, 0.01)
+	top := NewTopK(k)
+	exact := make(map[uint64]int64)
+	for _, key := range zipf(200000, 100000) {
+		top.Offer(key, c.Add(key, 1))
+		exact[key]++
+	}
+
+	var want []Item
+	for key, n := range exact {
+		want = append(want, Item{Key: key, Estimate: n})
+	}
+	sort.Slice(want, func(i, j int) bool { return outranks(want[i], want[j]) })
+
+	got := top.Top(k)
+	if len(got) != k {
+		t.Fatalf("Top(%d) has %d items", k, len(got))
+	}
+	found := make(map[uint64]bool)
+	for _, item := range got {
+		found[item.Key] = true
+	}
+	bound := int64(0.001 * float64(c.Total()))
+	for _, item := range want[:k] {
+		if !found[item.Key] && item.Estimate > want[k-1].Estimate+bound {
+			t.Errorf("heavy hitter %d with %d missing from the top %d", item.Key, item.Estimate, k)
+		}
+	}
+	for i, item := range got {
+		if n := exact[item.Key]; item.Estimate < n || item.Estimate-n > bound {
+			t.Errorf("Top[%d] = %+v, exact %d", i, item, n)
+		}
+	}
+}
+
+func TestTopKOffer(t *testing.T) {
+	top := NewTopK(2)
+	top.Offer(1, 10)
+	top.Offer(2, 20)
+	top.Offer(3, 5) // below the lowest held
+	top.Offer(4, 15)
+	top.Offer(2, 0) // drops out
+	got := top.Top(10)
+	want := []Item{{4, 15}}
+	if len(got) != len(want) || got[0] != want[0] {
+		t.Errorf("Top() = %v, want %v", got, want)
+	}
+}
+
+func TestTDigestErrorBound(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	td := NewTDigest(100)
+	values := make([]float64, 100000)
+	for i := range values {
+		// Log-normal bet sizes around 10 EUR.
+		values[i] = math.Round(1000 * math.Exp(rng.NormFloat64()))
+		td.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0.01, 0.1, 0.5, 0.9, 0.99, 0.999} {
+		got := td.Quantile(q)
+		// The error is measured in rank: the fraction of values below
+		// the estimate should be close to q.
+		rank := float64(sort.SearchFloat64s(values, got)) / float64(len(values))
+		if err := math.Abs(rank - q); err > 0.01 {
+			t.Errorf("Quantile(%v) = %v at rank %.4f, error %.4f", q, got, rank, err)
+		}
+	}
+	if td.Quantile(0) != values[0] || td.Quantile(1) != values[len(values)-1] {
+		t.Errorf("Quantile(0), Quantile(1) = %v, %v, want the min and max", td.Quantile(0), td.Quantile(1))
+	}
+	if n := len(td.merged()); n > 200 {
+		t.Errorf("%d centroids, want at most 200", n)
+	}
+	if !math.IsNaN(NewTDigest(100).Quantile(0.5)) {
+		t.Error("Quantile of an empty digest is not NaN")
+	}
+}
+
+func TestTDigestMerge(t *testing.T) {
+	a, b := NewTDigest(100), NewTDigest(100)
+	for i := 1; i <= 1000; i++ {
+		a.Add(float64(i))
+		b.Add(float64(i + 1000))
+	}
+	a.Merge(b)
+	if a.Count() != 2000 {
+		t.Errorf("Count() = %d, want 2000", a.Count())
+	}
+	if got := a.Quantile(0.5); math.Abs(got-1000) > 20 {
+		t.Errorf("Quantile(0.5) = %v, want about 1000", got)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	if err := DefaultConfig().Validate(); err != nil {
+		t.Errorf("DefaultConfig().Validate() = %v", err)
+	}
+	for _, c := range []Config{
+		{HLLPrecision: 20, CMSEpsilon: 0.001, CMSDelta: 0.01, TopK: 10, Compression: 100},
+		{HLLPrecision: 14, CMSEpsilon: 0, CMSDelta: 0.01, TopK: 10, Compression: 100},
+		{HLLPrecision: 14, CMSEpsilon: 0.001, CMSDelta: 1, TopK: 10, Compression: 100},
+		{HLLPrecision: 14, CMSEpsilon: 0.001, CMSDelta: 0.01, TopK: 0, Compression: 100},
+		{HLLPrecision: 14, CMSEpsilon: 0.001, CMSDelta: 0.01, TopK: 10, Compression: 1},
+	} {
+		if err := c.Validate(); err == nil {
+			t.Errorf("Validate(%+v) = nil", c)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package sketch
+
+import (
+	"math"
+	"sort"
+)
+
+// TDigest estimates quantiles of a stream of values from a bounded number
+// of centroids, clusters of nearby values kept as their mean and weight.
+// Clusters are small near the tails and large around the median, so
+// extreme quantiles stay accurate. Larger compressions keep more centroids,
+// at most about the compression, and give smaller errors.
+type TDigest struct {
+	compression float64
+	centroids   []centroid // merged, ordered by mean
+	buffer      []centroid // added since the last merge
+	count       float64
+	min, max    float64
+}
+
+type centroid struct {
+	mean   float64
+	weight float64
+}
+
+func NewTDigest(compression float64) *TDigest {
+	if compression < 10 {
+		compression = 10
+	}
+	return &TDigest{compression: compression, min: math.Inf(1), max: math.Inf(-1)}
+}
+
+// bufferSize is how many values are added between merges.
+func (t *TDigest) bufferSize() int {
+	return int(5 * t.compression)
+}
+
+// Add adds one value.
+func (t *TDigest) Add(x float64) {
+	t.add(centroid{mean: x, weight: 1})
+}
+
+func (t *TDigest) add(c centroid) {
+	t.buffer = append(t.buffer, c)
+	t.count += c.weight
+	t.min = math.Min(t.min, c.mean)
+	t.max = math.Max(t.max, c.mean)
+	if len(t.buffer) >= t.bufferSize() {
+		t.centroids = t.merged()
+		t.buffer = t.buffer[:0]
+	}
+}
+
+// Merge adds o's values to t.
+func (t *TDigest) Merge(o *TDigest) {
+	for _, c := range o.centroids {
+		t.add(c)
+	}
+	for _, c := range o.buffer {
+		t.add(c)
+	}
+}
+
+// merged returns the centroids with the buffer merged in. Neighbouring
+// centroids are combined while the quantiles the result spans differ by
+// at most 1 on the scale k(q) = δ/2π·asin(2q-1) for compression δ, which
+// keeps centroids small near q = 0 and q = 1.
+func (t *TDigest) merged() []centroid {
+	if len(t.buffer) == 0 {
+		return t.centroids
+	}
+	all := make([]centroid, 0, len(t.centroids)+len(t.buffer))
+	all = append(all, t.centroids...)
+	all = append(all, t.buffer...)
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, int(2*t.compression))
+	cur := all[0]
+	var soFar float64
+	for _, c := range all[1:] {
+		proposed := cur.weight + c.weight
+		if t.scale((soFar+proposed)/t.count)-t.scale(soFar/t.count) <= 1 {
+			cur.mean += (c.mean - cur.mean) * c.weight / proposed
+			cur.weight = proposed
+			continue
+		}
+		merged = append(merged, cur)
+		soFar += cur.weight
+		cur = c
+	}
+	return append(merged, cur)
+}
+
+func (t *TDigest) scale(q float64) float64 {
+	return t.compression / (2 * math.Pi) * math.Asin(2*math.Min(q, 1)-1)
+}
+
+// Quantile returns the estimated q-quantile, 0 ≤ q ≤ 1, or NaN if no
+// values were added. It interpolates between the centroids' means, taken
+// to sit at the middle of their weight.
+func (t *TDigest) Quantile(q float64) float64 {
+	if t.count == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return t.min
+	}
+	if q >= 1 {
+		return t.max
+	}
+	cs := t.merged()
+	target := q * t.count
+
+	// Before the first centroid's middle: between the minimum and it.
+	mid := cs[0].weight / 2
+	if target < mid {
+		return t.min + (cs[0].mean-t.min)*target/mid
+	}
+	cum := cs[0].weight
+	for i := 1; i < len(cs); i++ {
+		next := cum + cs[i].weight/2
+		if target < next {
+			return cs[i-1].mean + (cs[i].mean-cs[i-1].mean)*(target-mid)/(next-mid)
+		}
+		mid = next
+		cum += cs[i].weight
+	}
+	// After the last centroid's middle: between it and the maximum.
+	last := cs[len(cs)-1]
+	return last.mean + (t.max-last.mean)*(target-mid)/(t.count-mid)
+}
+
+// Count is the number of values added.
+func (t *TDigest) Count() int64 {
+	return int64(t.count)
+}
+
+// Bytes is the approximate memory held by the centroids and the buffer.
+func (t *TDigest) Bytes() int {
+	return (cap(t.centroids) + cap(t.buffer)) * 16
+}
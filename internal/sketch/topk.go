@@ -0,0 +1,105 @@
+package sketch
+
+import (
+	"container/heap"
+	"sort"
+)
+
+// Item is a key and its estimated value.
+type Item struct {
+	Key      uint64
+	Estimate int64
+}
+
+// TopK keeps the k keys with the highest estimates offered, the heavy
+// hitters of a stream. Estimates come from the caller, usually a CountMin,
+// and are offered again after every update of the key, so a key enters
+// the top once its estimate exceeds the lowest one held. Memory is O(k)
+// whatever the number of keys.
+type TopK struct {
+	k     int
+	items []Item // min-heap by estimate
+	index map[uint64]int
+}
+
+func NewTopK(k int) *TopK {
+	if k < 1 {
+		k = 1
+	}
+	return &TopK{k: k, index: make(map[uint64]int, k)}
+}
+
+// Offer records key's current estimate. Keys whose estimate drops to zero
+// or below leave the top.
+func (t *TopK) Offer(key uint64, estimate int64) {
+	i, ok := t.index[key]
+	switch {
+	case ok && estimate > 0:
+		t.items[i].Estimate = estimate
+		heap.Fix(t, i)
+	case ok:
+		heap.Remove(t, i)
+	case estimate <= 0:
+		// Not held and nothing to rank.
+	case len(t.items) < t.k:
+		heap.Push(t, Item{Key: key, Estimate: estimate})
+	case outranks(Item{Key: key, Estimate: estimate}, t.items[0]):
+		delete(t.index, t.items[0].Key)
+		t.items[0] = Item{Key: key, Estimate: estimate}
+		t.index[key] = 0
+		heap.Fix(t, 0)
+	}
+}
+
+// Top returns up to n keys, highest estimate first and ties by lower key.
+// It selects them in one pass in O(k·n), so the leader costs O(k).
+func (t *TopK) Top(n int) []Item {
+	n = max(0, min(n, len(t.items)))
+	top := make([]Item, 0, n)
+	for _, item := range t.items {
+		i := sort.Search(len(top), func(i int) bool { return outranks(item, top[i]) })
+		if i == n {
+			continue
+		}
+		if len(top) < n {
+			top = append(top, Item{})
+		}
+		copy(top[i+1:], top[i:len(top)-1])
+		top[i] = item
+	}
+	return top
+}
+
+// K is the number of keys kept.
+func (t *TopK) K() int {
+	return t.k
+}
+
+func outranks(a, b Item) bool {
+	if a.Estimate != b.Estimate {
+		return a.Estimate > b.Estimate
+	}
+	return a.Key < b.Key
+}
+
+func (t *TopK) Len() int           { return len(t.items) }
+func (t *TopK) Less(i, j int) bool { return outranks(t.items[j], t.items[i]) }
+
+func (t *TopK) Swap(i, j int) {
+	t.items[i], t.items[j] = t.items[j], t.items[i]
+	t.index[t.items[i].Key] = i
+	t.index[t.items[j].Key] = j
+}
+
+func (t *TopK) Push(x interface{}) {
+	item := x.(Item)
+	t.index[item.Key] = len(t.items)
+	t.items = append(t.items, item)
+}
+
+func (t *TopK) Pop() interface{} {
+	item := t.items[len(t.items)-1]
+	t.items = t.items[:len(t.items)-1]
+	delete(t.index, item.Key)
+	return item
+}
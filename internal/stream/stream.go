@@ -0,0 +1,67 @@
+package stream
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	// Name of the JetStream stream holding raw and enriched casino events.
+	Name = "CASINO_EVENTS"
+
+	EventsSubject   = "casino.events"
+	EnrichedSubject = "casino.events.enriched"
+	// LateSubject receives enriched events that arrived behind the
+	// watermark, under the side-output late event policy.
+	LateSubject = "casino.events.late"
+
+	// Default durable consumer used by the subscriber.
+	DefaultConsumer = "casino-subscriber"
+)
+
+// SentAtHeader carries the RFC 3339 time a raw event was sent, set by the
+// load test. The subscriber copies it onto the enriched event so that
+// end-to-end latency can be measured on casino.events.enriched.
+const SentAtHeader = "Casino-Sent-At"
+
+// DuplicateWindow is how long the server remembers Nats-Msg-Id headers to
+// drop messages published twice, such as publisher retries after a lost ack.
+const DuplicateWindow = 2 * time.Minute
+
+// Config describes the stream declared by both publisher and subscriber.
+// Declaring it from either side means start-up order does not matter.
+func Config() jetstream.StreamConfig {
+	return jetstream.StreamConfig{
+		Name:       Name,
+		Subjects:   []string{EventsSubject, EnrichedSubject, LateSubject},
+		Storage:    jetstream.FileStorage,
+		Retention:  jetstream.LimitsPolicy,
+		MaxAge:     7 * 24 * time.Hour,
+		Duplicates: DuplicateWindow,
+	}
+}
+
+// Ensure creates the casino events stream or updates it to the current config.
+func Ensure(ctx context.Context, js jetstream.JetStream) (jetstream.Stream, error) {
+	s, err := js.CreateOrUpdateStream(ctx, Config())
+	if err != nil {
+		return nil, fmt.Errorf("failed to declare stream %s: %w", Name, err)
+	}
+	return s, nil
+}
+
+// ConsumerConfig returns a durable pull consumer reading raw events only.
+// Messages that are not acked within ackWait are redelivered, up to maxDeliver times.
+func ConsumerConfig(durable string, ackWait time.Duration, maxDeliver int) jetstream.ConsumerConfig {
+	return jetstream.ConsumerConfig{
+		Durable:       durable,
+		FilterSubject: EventsSubject,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       ackWait,
+		MaxDeliver:    maxDeliver,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	}
+}
@@ -0,0 +1,87 @@
+package subscriber
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+    "github.com/nats-io/nats.go/jetstream"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dlq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// stageError records which step of process failed so the dead letter
+// can name the failing enricher (or decode/publish).
+type stageError struct {
+    stage string
+    err   error
+}
+
+func (e *stageError) Error() string {
+    return e.err.Error()
+}
+
+func (e *stageError) Unwrap() error {
+    return e.err
+}
+
+// ensureDeadLetters declares the dead-letter stream for core NATS mode, so
+// that dead letters are stored even though events are not. They are
+// published through JetStream, so a letter the stream did not store fails
+// instead of being treated as handled. Without JetStream on the server,
+// letters fall back to a plain core publish that nothing stores.
+func (s *Service) ensureDeadLetters(ctx context.Context) {
+    js, err := jetstream.New(s.nc)
+    if err == nil {
+        _, err = dlq.Ensure(ctx, js)
+    }
+    if err != nil {
+        log.Printf("WARNING: dead-letter stream unavailable, publishing dead letters to %s without storing them: %v", dlq.Subject, err)
+        return
+    }
+    s.letters = js
+}
+
+// deadLetter publishes the raw payload and failure details to the
+// dead-letter stream so the event can be inspected and replayed later. It
+// returns once the stream has stored the letter, or once it is published
+// when the server has no JetStream.
+func (s *Service) deadLetter(ctx context.Context, msg message, cause error, attempts int) error {
+    letter := dlq.DeadLetter{
+        Payload:     msg.data,
+        ContentType: msg.contentType,
+        Enricher:    "unknown",
+        Error:       cause.Error(),
+        Attempts:    attempts,
+        FailedAt:    time.Now().UTC(),
+    }
+
+    var se *stageError
+    if errors.As(cause, &se) {
+        letter.Enricher = se.stage
+    }
+    var verr *casino.ValidationError
+    if errors.As(cause, &verr) {
+        letter.Reason = verr.Reason
+    }
+
+    body, err := letter.Marshal()
+    if err != nil {
+        return fmt.Errorf("failed to marshal dead letter: %w", err)
+    }
+
+    if s.letters != nil {
+        _, err = s.letters.Publish(ctx, dlq.Subject, body)
+    } else {
+        err = s.nc.Publish(dlq.Subject, body)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to publish dead letter: %w", err)
+    }
+
+    log.Printf("Dead-lettered event after %d attempt(s) in %s: %v", attempts, letter.Enricher, cause)
+    metrics.DeadLetters.WithLabelValues(letter.Enricher).Inc()
+    return nil
+}
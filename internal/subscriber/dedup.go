@@ -0,0 +1,97 @@
+package subscriber
+
+import (
+    "context"
+    "log"
+    "strconv"
+    "sync"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dedup"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// SetDedup replaces the window of processed events used to drop
+// duplicates; nil turns deduplication off.
+func (s *Service) SetDedup(w *dedup.Window) {
+    s.dedup = w
+}
+
+// dedupKey identifies an event across redeliveries and publisher retries:
+// the envelope's event id, or the event's own id for bare events.
+func dedupKey(env envelope.Envelope) string {
+    if env.EventID != "" {
+        return env.EventID
+    }
+    return "id:" + strconv.Itoa(env.Event.ID)
+}
+
+// duplicate reports whether the event was already processed, or is still
+// waiting for the event store, counting it if so. Events of one player are
+// processed in order by a single worker, so a duplicate cannot race the
+// original.
+func (s *Service) duplicate(env envelope.Envelope) bool {
+    if s.dedup == nil {
+        return false
+    }
+    key := dedupKey(env)
+    if !s.storing.has(key) && !s.dedup.Seen(key) {
+        return false
+    }
+    log.Printf("Dropped duplicate event %d (%s)", env.Event.ID, dedupKey(env))
+    metrics.DuplicateEvents.Inc()
+    return true
+}
+
+// markProcessed records a fully processed event. A failure to persist the
+// key is only logged: the event has been handled either way.
+func (s *Service) markProcessed(ctx context.Context, env envelope.Envelope) {
+    if s.dedup == nil {
+        return
+    }
+    if err := s.dedup.Add(ctx, dedupKey(env)); err != nil {
+        log.Printf("Failed to record processed event %d: %v", env.Event.ID, err)
+    }
+}
+
+// inFlight is the set of keys of events waiting for the event store. Their
+// worker has moved on, so they count as seen until they are processed.
+type inFlight struct {
+    mu   sync.Mutex
+    keys map[string]bool
+}
+
+func (f *inFlight) add(key string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if f.keys == nil {
+        f.keys = make(map[string]bool)
+    }
+    f.keys[key] = true
+}
+
+func (f *inFlight) remove(key string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    delete(f.keys, key)
+}
+
+func (f *inFlight) has(key string) bool {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.keys[key]
+}
+
+// startStoring marks the event as waiting for the event store.
+func (s *Service) startStoring(env envelope.Envelope) {
+    if s.dedup != nil {
+        s.storing.add(dedupKey(env))
+    }
+}
+
+// doneStoring clears the mark once the event has been stored, and marked
+// processed, or has failed.
+func (s *Service) doneStoring(env envelope.Envelope) {
+    if s.dedup != nil {
+        s.storing.remove(dedupKey(env))
+    }
+}
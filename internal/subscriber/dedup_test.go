@@ -0,0 +1,125 @@
+package subscriber
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/money"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/natstest"
+)
+
+func TestProcessDropsDuplicates(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    enriched := 0
+    sub, err := New(srv.ClientURL(), &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            enriched++
+            event.AmountEUR = money.EUR(int64(event.Amount))
+            return nil
+        },
+    })
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    var msgs []message
+    for id := 1; id <= 3; id++ {
+        data, _ := envelope.New("test", validBet(id)).Marshal()
+        msgs = append(msgs, message{data: data})
+    }
+    // The same envelope in protobuf is the same event.
+    env := envelope.New("test", validBet(4))
+    data, _ := env.Marshal()
+    msgs = append(msgs, message{data: data})
+    pb, _ := codec.Protobuf.MarshalEnvelope(env)
+    msgs = append(msgs, message{data: pb, contentType: codec.ContentTypeProtobuf})
+    // Bare events are keyed by their own id.
+    bare, _ := json.Marshal(validBet(5))
+    msgs = append(msgs, message{data: bare}, message{data: bare})
+
+    ctx := context.Background()
+    for _, m := range msgs {
+        if err := sub.processWait(ctx, m); err != nil {
+            t.Fatalf("process() error = %v", err)
+        }
+    }
+    materialized := sub.materializer.GetData()
+    aggregates := sub.aggregator.GetAggregates()
+    if materialized.EventsTotal != 5 || aggregates.TotalBetsEUR != 5*500 {
+        t.Fatalf("After first delivery: %d events, %d EUR cents of bets, want 5 and %d",
+            materialized.EventsTotal, aggregates.TotalBetsEUR, 5*500)
+    }
+
+    // Redeliver everything.
+    for _, m := range msgs {
+        if err := sub.processWait(ctx, m); err != nil {
+            t.Fatalf("process() of duplicate error = %v", err)
+        }
+    }
+    if got := sub.materializer.GetData(); got.EventsTotal != materialized.EventsTotal {
+        t.Errorf("EventsTotal after redelivery = %d, want %d", got.EventsTotal, materialized.EventsTotal)
+    }
+    if got := sub.aggregator.GetAggregates(); got.TotalBetsEUR != aggregates.TotalBetsEUR {
+        t.Errorf("TotalBetsEUR after redelivery = %d, want %d", got.TotalBetsEUR, aggregates.TotalBetsEUR)
+    }
+    if enriched != 5 {
+        t.Errorf("Enriched %d events, want 5", enriched)
+    }
+}
+
+func TestProcessRetriesFailedEvents(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    fail := true
+    sub, err := New(srv.ClientURL(), &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            if fail {
+                fail = false
+                return context.DeadlineExceeded
+            }
+            return nil
+        },
+    })
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    data, _ := envelope.New("test", validBet(1)).Marshal()
+    if err := sub.processWait(context.Background(), message{data: data}); err == nil {
+        t.Fatal("process() succeeded, want the enricher error")
+    }
+    // A failed event was not processed, so its redelivery is not a duplicate.
+    if err := sub.processWait(context.Background(), message{data: data}); err != nil {
+        t.Fatalf("process() of redelivery error = %v", err)
+    }
+    if got := sub.materializer.GetData().EventsTotal; got != 1 {
+        t.Errorf("EventsTotal = %d, want 1", got)
+    }
+}
+
+func TestProcessWithoutDedup(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    sub, err := New(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+    sub.SetDedup(nil)
+
+    data, _ := envelope.New("test", validBet(1)).Marshal()
+    for i := 0; i < 2; i++ {
+        if err := sub.processWait(context.Background(), message{data: data}); err != nil {
+            t.Fatalf("process() error = %v", err)
+        }
+    }
+    if got := sub.materializer.GetData().EventsTotal; got != 2 {
+        t.Errorf("EventsTotal = %d, want 2 with deduplication off", got)
+    }
+}
@@ -0,0 +1,85 @@
+package subscriber
+
+import (
+    "context"
+    "encoding/json"
+    "testing"
+    "time"
+    "github.com/nats-io/nats.go"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/natstest"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/watermark"
+)
+
+func TestProcessEventTime(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+    late := make(chan casino.Event, 10)
+    lateSub, err := nc.Subscribe(stream.LateSubject, func(msg *nats.Msg) {
+        var event casino.Event
+        if err := json.Unmarshal(msg.Data, &event); err != nil {
+            t.Errorf("Failed to unmarshal late event: %v", err)
+            return
+        }
+        late <- event
+    })
+    if err != nil {
+        t.Fatalf("Failed to subscribe to late events: %v", err)
+    }
+    defer lateSub.Unsubscribe()
+    nc.Flush()
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{})
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+    sub.SetEventTime(10*time.Second, watermark.SideOutput)
+
+    base := time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+    offsets := []time.Duration{
+        30 * time.Second,
+        25 * time.Second, // out of order, within lateness
+        time.Minute,
+        0, // behind the watermark at 12:00:50
+    }
+    for i, offset := range offsets {
+        event := validBet(i + 1)
+        event.CreatedAt = base.Add(offset)
+        data, _ := envelope.New("test", event).Marshal()
+        if err := sub.processWait(context.Background(), message{data: data}); err != nil {
+            t.Fatalf("process() error = %v", err)
+        }
+    }
+
+    select {
+    case event := <-late:
+        if event.ID != 4 {
+            t.Errorf("Late event = %d, want 4", event.ID)
+        }
+    case <-time.After(5 * time.Second):
+        t.Fatal("Timeout waiting for the late event")
+    }
+
+    // Totals count every event, windows only those in event-time order.
+    if got := sub.materializer.GetData().EventsTotal; got != 4 {
+        t.Errorf("EventsTotal = %d, want 4", got)
+    }
+    hour, _ := aggregator.ParseWindow("hour")
+    w, _ := sub.aggregator.Query(hour, base, "")
+    if w.Totals.Bets != 2 {
+        t.Errorf("windowed bets before the watermark passes = %d, want 2", w.Totals.Bets)
+    }
+    sub.eventTime.Flush()
+    if w, _ = sub.aggregator.Query(hour, base, ""); w.Totals.Bets != 3 {
+        t.Errorf("windowed bets after flush = %d, want 3 without the late one", w.Totals.Bets)
+    }
+}
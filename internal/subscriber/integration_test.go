@@ -31,10 +31,7 @@ func TestIntegration(t *testing.T) {
     }
     defer playerEnricher.Close()
 
-    gameTitles := map[int]string{
-        100: "Book of Dead",
-    }
-    descriptionEnricher := description.New(gameTitles)
+    descriptionEnricher := description.New()
 
     // Create subscriber
     sub, err := New(nats.DefaultURL, currencyEnricher, playerEnricher, descriptionEnricher)
@@ -110,7 +107,7 @@ func TestIntegration(t *testing.T) {
                 CreatedAt: now,
             },
             validate: func(t *testing.T, e casino.Event) {
-                if e.AmountEUR != 910 {
+                if e.AmountEUR.Amount != 910 {
                     t.Error("AmountEUR not enriched correctly")
                 }
                 if e.Player.Email != "player123@example.com" {
@@ -119,7 +116,7 @@ func TestIntegration(t *testing.T) {
                 if !e.Player.LastSignedInAt.Equal(now) {
                     t.Errorf("Player LastSignedInAt = %v, want %v", e.Player.LastSignedInAt, now)
                 }
-                wantDesc := "Player 123 won USD 10.00 in Book of Dead"
+                wantDesc := fmt.Sprintf(`Player #123 (player123@example.com) placed a winning bet of 10 USD (9.10 EUR) on a game "Rocket Dice" on %s.`, when(now))
                 if e.Description != wantDesc {
                     t.Errorf("Description = %q, want %q", e.Description, wantDesc)
                 }
@@ -131,18 +128,18 @@ func TestIntegration(t *testing.T) {
                 ID:       2,
                 Type:     "deposit",
                 PlayerID: 123,
-                Amount:   1,    // 0.001 BTC (smallest unit)
+                Amount:   100000,    // 0.001 BTC in satoshi
                 Currency: "BTC",
                 CreatedAt: now,
             },
             validate: func(t *testing.T, e casino.Event) {
-                if e.AmountEUR != 35 { // 0.001 BTC = 35 EUR
+                if e.AmountEUR.Amount != 3500 { // 0.001 BTC = 35.00 EUR
                     t.Error("AmountEUR not enriched correctly")
                 }
                 if e.Player.Email != "player123@example.com" {
                     t.Errorf("Player email = %q, want player123@example.com", e.Player.Email)
                 }
-                wantDesc := fmt.Sprintf("Player 123 deposited BTC 0.001 at %s", now.Format(time.RFC3339))
+                wantDesc := fmt.Sprintf(`Player #123 (player123@example.com) made a deposit of 0.001 BTC (35 EUR) on %s.`, when(now))
                 if e.Description != wantDesc {
                     t.Errorf("Description = %q, want %q", e.Description, wantDesc)
                 }
@@ -161,7 +158,7 @@ func TestIntegration(t *testing.T) {
                 if e.Player.Email != "player123@example.com" {
                     t.Errorf("Player email = %q, want player123@example.com", e.Player.Email)
                 }
-                wantDesc := "Player 123 started playing Book of Dead"
+                wantDesc := fmt.Sprintf(`Player #123 (player123@example.com) started playing a game "Rocket Dice" on %s.`, when(now))
                 if e.Description != wantDesc {
                     t.Errorf("Description = %q, want %q", e.Description, wantDesc)
                 }
@@ -187,4 +184,20 @@ func TestIntegration(t *testing.T) {
             }
         })
     }
-} 
\ No newline at end of file
+} 
+
+// when formats t like the English description templates.
+func when(t time.Time) string {
+    t = t.UTC()
+    suffix := "th"
+    switch d := t.Day(); {
+    case d == 11 || d == 12 || d == 13:
+    case d%10 == 1:
+        suffix = "st"
+    case d%10 == 2:
+        suffix = "nd"
+    case d%10 == 3:
+        suffix = "rd"
+    }
+    return fmt.Sprintf("%s %d%s, %d at %s UTC", t.Month(), t.Day(), suffix, t.Year(), t.Format("15:04"))
+}
@@ -0,0 +1,137 @@
+package subscriber
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log"
+    "time"
+    "github.com/nats-io/nats.go/jetstream"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dlq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+)
+
+// JetStreamConfig configures the durable pull consumer used in JetStream mode.
+type JetStreamConfig struct {
+    Durable    string
+    AckWait    time.Duration
+    MaxDeliver int
+}
+
+// EnableJetStream switches Start from a core NATS subscription to a durable
+// pull consumer, so events published while the subscriber is down are
+// delivered once it comes back. It declares the stream if needed.
+func (s *Service) EnableJetStream(ctx context.Context, cfg JetStreamConfig) error {
+    if cfg.Durable == "" {
+        cfg.Durable = stream.DefaultConsumer
+    }
+    if cfg.AckWait <= 0 {
+        cfg.AckWait = 30 * time.Second
+    }
+    if cfg.MaxDeliver == 0 {
+        cfg.MaxDeliver = 5
+    }
+
+    js, err := jetstream.New(s.nc)
+    if err != nil {
+        return fmt.Errorf("failed to create JetStream context: %w", err)
+    }
+
+    if _, err := stream.Ensure(ctx, js); err != nil {
+        return err
+    }
+    if _, err := dlq.Ensure(ctx, js); err != nil {
+        return err
+    }
+
+    s.js = js
+    s.letters = js
+    s.jsConfig = cfg
+    return nil
+}
+
+// consumeJetStream pulls raw events from the durable consumer and acks each
+// one only after it has been enriched, published and materialized.
+// Failed events are nak'ed for redelivery; malformed ones and those out of
+// delivery attempts are dead-lettered and terminated.
+func (s *Service) consumeJetStream(ctx context.Context, pool *workerPool) error {
+    cfg := stream.ConsumerConfig(s.jsConfig.Durable, s.jsConfig.AckWait, s.jsConfig.MaxDeliver)
+    // Never hand out more events than the pool can hold, so queued
+    // events are not redelivered while they wait for a worker.
+    cfg.MaxAckPending = s.workers * (s.queueSize + 1)
+
+    cons, err := s.js.CreateOrUpdateConsumer(ctx, stream.Name, cfg)
+    if err != nil {
+        return fmt.Errorf("failed to create consumer %s: %w", s.jsConfig.Durable, err)
+    }
+
+    log.Printf("Consuming %s with durable consumer %s", EventsTopic, s.jsConfig.Durable)
+
+    cc, err := cons.Consume(func(msg jetstream.Msg) {
+        m := message{
+            data:        msg.Data(),
+            contentType: msg.Headers().Get(codec.Header),
+            sentAt:      msg.Headers().Get(stream.SentAtHeader),
+        }
+        err := pool.submit(ctx, m, func(err error) {
+            s.settle(ctx, msg, err)
+        })
+        if err != nil {
+            log.Printf("Failed to queue event, will be redelivered: %v", err)
+        }
+    })
+    if err != nil {
+        return fmt.Errorf("failed to consume: %w", err)
+    }
+    defer cc.Stop()
+
+    <-ctx.Done()
+    return nil
+}
+
+// settle acks, naks or dead-letters msg depending on the processing result.
+func (s *Service) settle(ctx context.Context, msg jetstream.Msg, err error) {
+    attempts := deliveryAttempts(msg)
+    switch {
+    case err == nil:
+        if err := msg.Ack(); err != nil {
+            log.Printf("Failed to ack event: %v", err)
+        }
+    case errors.Is(err, errMalformedEvent) || s.lastAttempt(attempts):
+        // Retrying cannot help; park it in the dead-letter stream.
+        m := message{data: msg.Data(), contentType: msg.Headers().Get(codec.Header)}
+        if dlqErr := s.deadLetter(ctx, m, err, attempts); dlqErr != nil {
+            log.Printf("Failed to dead-letter event, will be redelivered: %v", dlqErr)
+            msg.NakWithDelay(redeliveryDelay(attempts))
+            return
+        }
+        if err := msg.Term(); err != nil {
+            log.Printf("Failed to terminate event: %v", err)
+        }
+    default:
+        log.Printf("Event will be redelivered: %v", err)
+        if err := msg.NakWithDelay(redeliveryDelay(attempts)); err != nil {
+            log.Printf("Failed to nak event: %v", err)
+        }
+    }
+}
+
+// deliveryAttempts reports how many times msg has been delivered, including this one.
+func deliveryAttempts(msg jetstream.Msg) int {
+    meta, err := msg.Metadata()
+    if err != nil {
+        return 1
+    }
+    return int(meta.NumDelivered)
+}
+
+// lastAttempt reports whether the server will not redeliver after this attempt.
+func (s *Service) lastAttempt(attempts int) bool {
+    return s.jsConfig.MaxDeliver > 0 && attempts >= s.jsConfig.MaxDeliver
+}
+
+// redeliveryDelay backs off linearly with the number of delivery attempts.
+func redeliveryDelay(attempts int) time.Duration {
+    return time.Duration(attempts) * time.Second
+}
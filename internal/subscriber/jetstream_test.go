@@ -0,0 +1,519 @@
+package subscriber
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "strconv"
+    "sync/atomic"
+    "testing"
+    "time"
+    "github.com/nats-io/nats.go"
+    "github.com/nats-io/nats.go/jetstream"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dlq"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/natstest"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+)
+
+func TestJetStreamDurableDelivery(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    js, err := jetstream.New(nc)
+    if err != nil {
+        t.Fatalf("Failed to create JetStream context: %v", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    if _, err := stream.Ensure(ctx, js); err != nil {
+        t.Fatal(err)
+    }
+
+    // Publish before the subscriber exists; core NATS would lose these.
+    for id := 1; id <= 3; id++ {
+        data, _ := envelope.New("test", validBet(id)).Marshal()
+        if _, err := js.Publish(ctx, EventsTopic, data); err != nil {
+            t.Fatalf("Failed to publish event %d: %v", id, err)
+        }
+    }
+    if _, err := js.Publish(ctx, EventsTopic, []byte("{not json")); err != nil {
+        t.Fatalf("Failed to publish malformed event: %v", err)
+    }
+
+    enriched := make(chan casino.Event, 10)
+    enrichedSub, err := nc.Subscribe(EnrichedTopic, func(msg *nats.Msg) {
+        var event casino.Event
+        if err := json.Unmarshal(msg.Data, &event); err != nil {
+            t.Errorf("Failed to unmarshal enriched event: %v", err)
+            return
+        }
+        enriched <- event
+    })
+    if err != nil {
+        t.Fatalf("Failed to subscribe to enriched events: %v", err)
+    }
+    defer enrichedSub.Unsubscribe()
+
+    // Fail event 2 on its first delivery to exercise nak and redelivery.
+    var failed atomic.Bool
+    first := &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            if event.ID == 2 && failed.CompareAndSwap(false, true) {
+                return errors.New("rate not available")
+            }
+            return nil
+        },
+    }
+    second := &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            event.Description = "enriched"
+            return nil
+        },
+    }
+
+    sub, err := New(srv.ClientURL(), first, second)
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    if err := sub.EnableJetStream(ctx, JetStreamConfig{Durable: "test", AckWait: 5 * time.Second}); err != nil {
+        t.Fatalf("Failed to enable JetStream: %v", err)
+    }
+
+    go func() {
+        if err := sub.Start(ctx); err != nil {
+            t.Errorf("Subscriber stopped with error: %v", err)
+        }
+    }()
+
+    seen := make(map[int]bool)
+    timeout := time.After(5 * time.Second)
+    for len(seen) < 3 {
+        select {
+        case event := <-enriched:
+            if event.Description != "enriched" {
+                t.Errorf("Event %d description = %q, want enriched", event.ID, event.Description)
+            }
+            seen[event.ID] = true
+        case <-timeout:
+            t.Fatalf("Timeout waiting for enriched events, got %v", seen)
+        }
+    }
+
+    if !failed.Load() {
+        t.Error("Expected event 2 to fail once before succeeding")
+    }
+
+    // Everything, including the terminated malformed event, must be settled.
+    cons, err := js.Consumer(ctx, stream.Name, "test")
+    if err != nil {
+        t.Fatalf("Failed to look up consumer: %v", err)
+    }
+    deadline := time.Now().Add(2 * time.Second)
+    for {
+        info, err := cons.Info(ctx)
+        if err != nil {
+            t.Fatalf("Failed to get consumer info: %v", err)
+        }
+        if info.NumAckPending == 0 && info.NumPending == 0 {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatalf("Consumer not drained: ack pending %d, pending %d", info.NumAckPending, info.NumPending)
+        }
+        time.Sleep(50 * time.Millisecond)
+    }
+}
+
+func TestJetStreamDeadLetter(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    failing := &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            return errors.New("no rate found for currency XYZ")
+        },
+    }
+
+    sub, err := New(srv.ClientURL(), failing, &mockEnricher{})
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    if err := sub.EnableJetStream(ctx, JetStreamConfig{Durable: "test", AckWait: 5 * time.Second, MaxDeliver: 2}); err != nil {
+        t.Fatalf("Failed to enable JetStream: %v", err)
+    }
+
+    letters := make(chan dlq.DeadLetter, 2)
+    dlqSub, err := nc.Subscribe(dlq.Subject, func(msg *nats.Msg) {
+        var letter dlq.DeadLetter
+        if err := json.Unmarshal(msg.Data, &letter); err != nil {
+            t.Errorf("Failed to unmarshal dead letter: %v", err)
+            return
+        }
+        letters <- letter
+    })
+    if err != nil {
+        t.Fatalf("Failed to subscribe to dead letters: %v", err)
+    }
+    defer dlqSub.Unsubscribe()
+
+    go func() {
+        if err := sub.Start(ctx); err != nil {
+            t.Errorf("Subscriber stopped with error: %v", err)
+        }
+    }()
+
+    js, err := jetstream.New(nc)
+    if err != nil {
+        t.Fatalf("Failed to create JetStream context: %v", err)
+    }
+    payload, _ := envelope.New("test", validBet(42)).Marshal()
+    if _, err := js.Publish(ctx, EventsTopic, payload); err != nil {
+        t.Fatalf("Failed to publish event: %v", err)
+    }
+    if _, err := js.Publish(ctx, EventsTopic, []byte("{not json")); err != nil {
+        t.Fatalf("Failed to publish malformed event: %v", err)
+    }
+    invalid := validBet(43)
+    invalid.Currency = "XYZ"
+    invalidPayload, _ := json.Marshal(invalid)
+    if _, err := js.Publish(ctx, EventsTopic, invalidPayload); err != nil {
+        t.Fatalf("Failed to publish invalid event: %v", err)
+    }
+
+    got := make(map[string]dlq.DeadLetter)
+    timeout := time.After(5 * time.Second)
+    for len(got) < 3 {
+        select {
+        case letter := <-letters:
+            got[letter.Enricher] = letter
+        case <-timeout:
+            t.Fatalf("Timeout waiting for dead letters, got %v", got)
+        }
+    }
+
+    enrichFailure, ok := got["*subscriber.mockEnricher"]
+    if !ok {
+        t.Fatalf("Expected dead letter from the failing enricher, got %v", got)
+    }
+    if enrichFailure.Attempts != 2 {
+        t.Errorf("Attempts = %d, want 2", enrichFailure.Attempts)
+    }
+    if string(enrichFailure.Payload) != string(payload) {
+        t.Errorf("Payload = %s, want %s", enrichFailure.Payload, payload)
+    }
+    if enrichFailure.Error == "" || enrichFailure.FailedAt.IsZero() {
+        t.Errorf("Expected error and timestamp, got %+v", enrichFailure)
+    }
+
+    malformed, ok := got["decode"]
+    if !ok || malformed.Attempts != 1 {
+        t.Errorf("Expected malformed event dead-lettered on first attempt, got %+v", malformed)
+    }
+
+    rejected, ok := got["validate"]
+    if !ok || rejected.Attempts != 1 || rejected.Reason != casino.ReasonUnknownCurrency {
+        t.Errorf("Expected invalid event dead-lettered on first attempt with reason, got %+v", rejected)
+    }
+}
+
+func TestCoreDeadLetterStored(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{})
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    go func() {
+        if err := sub.Start(ctx); err != nil {
+            t.Errorf("Subscriber stopped with error: %v", err)
+        }
+    }()
+
+    // Core NATS mode: events are not stored, but their dead letters are.
+    js, err := jetstream.New(nc)
+    if err != nil {
+        t.Fatalf("Failed to create JetStream context: %v", err)
+    }
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        if err := nc.Publish(EventsTopic, []byte("{not json")); err != nil {
+            t.Fatalf("Failed to publish malformed event: %v", err)
+        }
+        time.Sleep(50 * time.Millisecond)
+        if s, err := js.Stream(ctx, dlq.StreamName); err == nil {
+            if info, err := s.Info(ctx); err == nil && info.State.Msgs > 0 {
+                return
+            }
+        }
+        if time.Now().After(deadline) {
+            t.Fatal("Dead letter was not stored in core NATS mode")
+        }
+    }
+}
+
+func TestCoreDeadLetterWithoutJetStream(t *testing.T) {
+    srv := natstest.RunServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    letters, err := nc.SubscribeSync(dlq.Subject)
+    if err != nil {
+        t.Fatalf("Failed to subscribe to dead letters: %v", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{})
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    stopped := make(chan error, 1)
+    go func() {
+        stopped <- sub.Start(ctx)
+    }()
+
+    // Without JetStream the subscriber still starts and publishes dead
+    // letters on the core subject.
+    deadline := time.Now().Add(5 * time.Second)
+    for {
+        select {
+        case err := <-stopped:
+            t.Fatalf("Subscriber stopped without JetStream: %v", err)
+        default:
+        }
+        if err := nc.Publish(EventsTopic, []byte("{not json")); err != nil {
+            t.Fatalf("Failed to publish malformed event: %v", err)
+        }
+        if msg, err := letters.NextMsg(50 * time.Millisecond); err == nil {
+            var letter dlq.DeadLetter
+            if err := json.Unmarshal(msg.Data, &letter); err != nil {
+                t.Fatalf("Failed to decode dead letter: %v", err)
+            }
+            if string(letter.Payload) != "{not json" {
+                t.Errorf("Dead letter payload = %q, want the malformed event", letter.Payload)
+            }
+            return
+        }
+        if time.Now().After(deadline) {
+            t.Fatal("Dead letter was not published without JetStream")
+        }
+    }
+}
+
+func TestJetStreamMixedCodecs(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    js, err := jetstream.New(nc)
+    if err != nil {
+        t.Fatalf("Failed to create JetStream context: %v", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    if _, err := stream.Ensure(ctx, js); err != nil {
+        t.Fatal(err)
+    }
+
+    // A JSON producer without the header and a protobuf one with it.
+    data, _ := envelope.New("test", validBet(1)).Marshal()
+    if _, err := js.Publish(ctx, EventsTopic, data); err != nil {
+        t.Fatalf("Failed to publish JSON event: %v", err)
+    }
+    msg := nats.NewMsg(EventsTopic)
+    msg.Header.Set(codec.Header, codec.ContentTypeProtobuf)
+    msg.Data, _ = codec.Protobuf.MarshalEnvelope(envelope.New("test", validBet(2)))
+    if _, err := js.PublishMsg(ctx, msg); err != nil {
+        t.Fatalf("Failed to publish protobuf event: %v", err)
+    }
+
+    enriched := make(chan casino.Event, 10)
+    enrichedSub, err := nc.Subscribe(EnrichedTopic, func(msg *nats.Msg) {
+        if ct := msg.Header.Get(codec.Header); ct != codec.ContentTypeProtobuf {
+            t.Errorf("Enriched Content-Type = %q, want %q", ct, codec.ContentTypeProtobuf)
+        }
+        event, err := codec.Protobuf.UnmarshalEvent(msg.Data)
+        if err != nil {
+            t.Errorf("Failed to decode enriched event: %v", err)
+            return
+        }
+        enriched <- event
+    })
+    if err != nil {
+        t.Fatalf("Failed to subscribe to enriched events: %v", err)
+    }
+    defer enrichedSub.Unsubscribe()
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            event.Description = "enriched"
+            return nil
+        },
+    })
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+    sub.SetCodec(codec.Protobuf)
+
+    if err := sub.EnableJetStream(ctx, JetStreamConfig{Durable: "test", AckWait: 5 * time.Second}); err != nil {
+        t.Fatalf("Failed to enable JetStream: %v", err)
+    }
+
+    go func() {
+        if err := sub.Start(ctx); err != nil {
+            t.Errorf("Subscriber stopped with error: %v", err)
+        }
+    }()
+
+    seen := make(map[int]bool)
+    timeout := time.After(5 * time.Second)
+    for len(seen) < 2 {
+        select {
+        case event := <-enriched:
+            if event.Description != "enriched" || event.Amount != 500 || event.Currency != "USD" {
+                t.Errorf("Enriched event = %+v, want the bet with a description", event)
+            }
+            seen[event.ID] = true
+        case <-timeout:
+            t.Fatalf("Timeout waiting for enriched events, got %v", seen)
+        }
+    }
+}
+
+func TestJetStreamSentAtHeader(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    nc, err := nats.Connect(srv.ClientURL())
+    if err != nil {
+        t.Fatalf("Failed to connect to NATS: %v", err)
+    }
+    defer nc.Close()
+
+    js, err := jetstream.New(nc)
+    if err != nil {
+        t.Fatalf("Failed to create JetStream context: %v", err)
+    }
+
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+
+    if _, err := stream.Ensure(ctx, js); err != nil {
+        t.Fatal(err)
+    }
+
+    // Event 1 is stamped by the load test, event 2 is not.
+    sentAt := "2024-02-24T12:00:00.123456789Z"
+    msg := nats.NewMsg(EventsTopic)
+    msg.Header.Set(stream.SentAtHeader, sentAt)
+    msg.Data, _ = envelope.New("test", validBet(1)).Marshal()
+    if _, err := js.PublishMsg(ctx, msg); err != nil {
+        t.Fatalf("Failed to publish stamped event: %v", err)
+    }
+    data, _ := envelope.New("test", validBet(2)).Marshal()
+    if _, err := js.Publish(ctx, EventsTopic, data); err != nil {
+        t.Fatalf("Failed to publish event: %v", err)
+    }
+
+    stamps := make(chan [2]string, 10)
+    enrichedSub, err := nc.Subscribe(EnrichedTopic, func(msg *nats.Msg) {
+        var event casino.Event
+        if err := json.Unmarshal(msg.Data, &event); err != nil {
+            t.Errorf("Failed to unmarshal enriched event: %v", err)
+            return
+        }
+        stamps <- [2]string{strconv.Itoa(event.ID), msg.Header.Get(stream.SentAtHeader)}
+    })
+    if err != nil {
+        t.Fatalf("Failed to subscribe to enriched events: %v", err)
+    }
+    defer enrichedSub.Unsubscribe()
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{})
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    if err := sub.EnableJetStream(ctx, JetStreamConfig{Durable: "test", AckWait: 5 * time.Second}); err != nil {
+        t.Fatalf("Failed to enable JetStream: %v", err)
+    }
+
+    go func() {
+        if err := sub.Start(ctx); err != nil {
+            t.Errorf("Subscriber stopped with error: %v", err)
+        }
+    }()
+
+    want := map[string]string{"1": sentAt, "2": ""}
+    timeout := time.After(5 * time.Second)
+    for range want {
+        select {
+        case got := <-stamps:
+            if got[1] != want[got[0]] {
+                t.Errorf("Event %s sent-at header = %q, want %q", got[0], got[1], want[got[0]])
+            }
+        case <-timeout:
+            t.Fatal("Timeout waiting for enriched events")
+        }
+    }
+}
+
+// validBet returns a bet that passes validation.
+func validBet(id int) casino.Event {
+    return casino.Event{
+        ID:        id,
+        PlayerID:  10,
+        GameID:    100,
+        Type:      "bet",
+        Amount:    500,
+        Currency:  "USD",
+        CreatedAt: time.Now().UTC(),
+    }
+}
@@ -0,0 +1,168 @@
+package subscriber
+
+import (
+    "context"
+    "encoding/binary"
+    "encoding/json"
+    "errors"
+    "hash/fnv"
+    "strconv"
+    "sync"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+const (
+    DefaultWorkers   = 4
+    DefaultQueueSize = 100
+)
+
+// message is a raw event as received, with the Content-Type header naming
+// its codec and the load test's send time, if any.
+type message struct {
+    data        []byte
+    contentType string
+    sentAt      string
+}
+
+// task is a raw event waiting for a worker. done is called once with the
+// result of processing, from the worker goroutine or, for events waiting
+// for the event store, from the store's writer.
+type task struct {
+    msg  message
+    done func(error)
+}
+
+// workerPool processes events in parallel while keeping events of the same
+// player in order: every player hashes to one worker, and each worker
+// drains its own bounded queue sequentially. A full queue blocks submit,
+// which pushes back on the NATS subscription.
+type workerPool struct {
+    queues []chan task
+    wg     sync.WaitGroup
+
+    // mu guards closed so submit never sends on a closed queue.
+    mu     sync.RWMutex
+    closed bool
+}
+
+var errPoolStopped = errors.New("worker pool stopped")
+
+func newWorkerPool(workers, queueSize int) *workerPool {
+    if workers < 1 {
+        workers = 1
+    }
+    if queueSize < 1 {
+        queueSize = 1
+    }
+
+    p := &workerPool{queues: make([]chan task, workers)}
+    for i := range p.queues {
+        p.queues[i] = make(chan task, queueSize)
+    }
+
+    metrics.WorkerPoolSize.Set(float64(workers))
+    metrics.WorkerQueueCapacity.Set(float64(queueSize))
+    return p
+}
+
+// start launches one goroutine per queue running handle for each task;
+// handle calls the task's done itself, possibly after returning.
+// Once ctx is done, remaining tasks are completed with ctx.Err() unprocessed.
+func (p *workerPool) start(ctx context.Context, handle func(context.Context, message, func(error))) {
+    for i, q := range p.queues {
+        p.wg.Add(1)
+        go func(worker string, q chan task) {
+            defer p.wg.Done()
+            for t := range q {
+                metrics.WorkerQueueDepth.WithLabelValues(worker).Set(float64(len(q)))
+                if err := ctx.Err(); err != nil {
+                    t.done(err)
+                    continue
+                }
+
+                metrics.WorkersBusy.Inc()
+                handle(ctx, t.msg, t.done)
+                metrics.WorkersBusy.Dec()
+            }
+        }(strconv.Itoa(i), q)
+    }
+}
+
+// submit queues msg on the worker owning its player, blocking while that
+// worker's queue is full. It returns ctx.Err() if ctx is done first.
+func (p *workerPool) submit(ctx context.Context, msg message, done func(error)) error {
+    p.mu.RLock()
+    defer p.mu.RUnlock()
+    if p.closed {
+        return errPoolStopped
+    }
+
+    i := p.partition(playerID(msg))
+    q := p.queues[i]
+    t := task{msg: msg, done: done}
+
+    select {
+    case q <- t:
+    default:
+        // Queue full: wait for the worker, recording the backpressure.
+        metrics.WorkerBackpressure.Inc()
+        start := time.Now()
+        select {
+        case q <- t:
+            metrics.WorkerBackpressureWait.Observe(time.Since(start).Seconds())
+        case <-ctx.Done():
+            return ctx.Err()
+        }
+    }
+
+    metrics.WorkerQueueDepth.WithLabelValues(strconv.Itoa(i)).Set(float64(len(q)))
+    return nil
+}
+
+// stop closes the queues and waits for workers to finish what is queued.
+// Cancel the context passed to submit first so blocked callers return.
+func (p *workerPool) stop() {
+    p.mu.Lock()
+    p.closed = true
+    for _, q := range p.queues {
+        close(q)
+    }
+    p.mu.Unlock()
+    p.wg.Wait()
+}
+
+func (p *workerPool) partition(playerID int) int {
+    h := fnv.New32a()
+    var buf [8]byte
+    binary.LittleEndian.PutUint64(buf[:], uint64(playerID))
+    h.Write(buf[:])
+    return int(h.Sum32() % uint32(len(p.queues)))
+}
+
+// playerID extracts only the partition key from a raw event, enveloped or
+// bare. Malformed payloads map to player 0; process reports the decode
+// error later.
+func playerID(msg message) int {
+    c, err := codec.ForContentType(msg.contentType)
+    if err != nil {
+        return 0
+    }
+    if c != codec.JSON {
+        env, _ := c.UnmarshalEnvelope(msg.data)
+        return env.Event.PlayerID
+    }
+
+    var key struct {
+        PlayerID int `json:"player_id"`
+        Event    *struct {
+            PlayerID int `json:"player_id"`
+        } `json:"event"`
+    }
+    json.Unmarshal(msg.data, &key)
+    if key.Event != nil {
+        return key.Event.PlayerID
+    }
+    return key.PlayerID
+}
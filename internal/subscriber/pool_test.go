@@ -0,0 +1,163 @@
+package subscriber
+
+import (
+    "context"
+    "encoding/json"
+    "math/rand"
+    "sync"
+    "testing"
+    "time"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+)
+
+func TestWorkerPoolPerPlayerOrdering(t *testing.T) {
+    pool := newWorkerPool(4, 10)
+
+    var mu sync.Mutex
+    seen := make(map[int][]int) // player ID -> event IDs in processing order
+
+    ctx := context.Background()
+    pool.start(ctx, func(ctx context.Context, msg message, done func(error)) {
+        var event casino.Event
+        if err := json.Unmarshal(msg.data, &event); err != nil {
+            done(err)
+            return
+        }
+        time.Sleep(time.Duration(rand.Intn(200)) * time.Microsecond)
+        mu.Lock()
+        seen[event.PlayerID] = append(seen[event.PlayerID], event.ID)
+        mu.Unlock()
+        done(nil)
+    })
+
+    var wg sync.WaitGroup
+    for id := 1; id <= 500; id++ {
+        data, _ := json.Marshal(casino.Event{ID: id, PlayerID: 10 + id%10})
+        wg.Add(1)
+        if err := pool.submit(ctx, message{data: data}, func(err error) {
+            if err != nil {
+                t.Errorf("Unexpected error: %v", err)
+            }
+            wg.Done()
+        }); err != nil {
+            t.Fatalf("submit() error = %v", err)
+        }
+    }
+    wg.Wait()
+    pool.stop()
+
+    if len(seen) != 10 {
+        t.Fatalf("Expected events for 10 players, got %d", len(seen))
+    }
+    for player, ids := range seen {
+        for i := 1; i < len(ids); i++ {
+            if ids[i] <= ids[i-1] {
+                t.Fatalf("Player %d events out of order: %v", player, ids)
+            }
+        }
+    }
+}
+
+func TestWorkerPoolRunsPlayersInParallel(t *testing.T) {
+    pool := newWorkerPool(2, 1)
+
+    // Find two players owned by different workers.
+    a, b := 1, 2
+    for pool.partition(a) == pool.partition(b) {
+        b++
+    }
+
+    started := make(chan int, 2)
+    release := make(chan struct{})
+    ctx := context.Background()
+    pool.start(ctx, func(ctx context.Context, msg message, done func(error)) {
+        started <- playerID(msg)
+        <-release
+        done(nil)
+    })
+    defer pool.stop()
+
+    done := func(error) {}
+    for _, p := range []int{a, b} {
+        data, _ := json.Marshal(casino.Event{PlayerID: p})
+        if err := pool.submit(ctx, message{data: data}, done); err != nil {
+            t.Fatalf("submit() error = %v", err)
+        }
+    }
+
+    // Both must be running at the same time while neither has finished.
+    for i := 0; i < 2; i++ {
+        select {
+        case <-started:
+        case <-time.After(time.Second):
+            t.Fatal("Expected both players to be processed concurrently")
+        }
+    }
+    close(release)
+}
+
+func TestWorkerPoolBackpressure(t *testing.T) {
+    pool := newWorkerPool(1, 1)
+
+    release := make(chan struct{})
+    pool.start(context.Background(), func(ctx context.Context, msg message, done func(error)) {
+        <-release
+        done(nil)
+    })
+    defer pool.stop()
+    defer close(release)
+
+    data, _ := json.Marshal(casino.Event{PlayerID: 10})
+    done := func(error) {}
+
+    // One event is being processed, one fills the queue.
+    for i := 0; i < 2; i++ {
+        if err := pool.submit(context.Background(), message{data: data}, done); err != nil {
+            t.Fatalf("submit() error = %v", err)
+        }
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer cancel()
+
+    // Give the worker time to take the first event off the queue.
+    time.Sleep(10 * time.Millisecond)
+    if err := pool.submit(ctx, message{data: data}, done); err != context.DeadlineExceeded {
+        t.Fatalf("submit() on full queue error = %v, want %v", err, context.DeadlineExceeded)
+    }
+}
+
+func TestWorkerPoolStopped(t *testing.T) {
+    pool := newWorkerPool(1, 1)
+    pool.start(context.Background(), func(ctx context.Context, msg message, done func(error)) { done(nil) })
+    pool.stop()
+
+    if err := pool.submit(context.Background(), message{data: []byte(`{}`)}, func(error) {}); err != errPoolStopped {
+        t.Fatalf("submit() after stop error = %v, want %v", err, errPoolStopped)
+    }
+}
+
+func TestPlayerIDFromEnvelope(t *testing.T) {
+    bare, _ := json.Marshal(casino.Event{ID: 1, PlayerID: 12})
+    wrapped, _ := envelope.New("test", casino.Event{ID: 1, PlayerID: 13}).Marshal()
+    pb, _ := codec.Protobuf.MarshalEnvelope(envelope.New("test", casino.Event{ID: 1, PlayerID: 14}))
+
+    tests := []struct {
+        name string
+        msg  message
+        want int
+    }{
+        {"bare", message{data: bare}, 12},
+        {"envelope", message{data: wrapped, contentType: codec.ContentTypeJSON}, 13},
+        {"protobuf", message{data: pb, contentType: codec.ContentTypeProtobuf}, 14},
+        {"malformed", message{data: []byte("{not json")}, 0},
+        {"unknown content type", message{data: wrapped, contentType: "text/plain"}, 0},
+    }
+    for _, tt := range tests {
+        if got := playerID(tt.msg); got != tt.want {
+            t.Errorf("playerID(%s) = %d, want %d", tt.name, got, tt.want)
+        }
+    }
+}
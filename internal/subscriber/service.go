@@ -4,70 +4,181 @@ import (
     "context"
     "database/sql"
     "encoding/json"
+    "errors"
     "fmt"
     "log"
     "net/http"
+    "strconv"
     "time"
     "github.com/nats-io/nats.go"
+    "github.com/nats-io/nats.go/jetstream"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/codec"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/health"
     "github.com/prometheus/client_golang/prometheus/promhttp"
-    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/player"
-    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher/exchange"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/enricher"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/aggregator"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/materializer"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/config"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/dedup"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/sketch"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/stream"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/watermark"
 )
 
 const (
-    EventsTopic = "casino.events"  // Match the topic name from publisher
+    EventsTopic = stream.EventsSubject  // Match the topic name from publisher
+    EnrichedTopic = stream.EnrichedSubject
 )
 
+// errMalformedEvent is returned by process for payloads that cannot be
+// decoded or fail validation; retrying them cannot help.
+var errMalformedEvent = errors.New("malformed event")
+
 type Service struct {
     nc *nats.Conn
-    enrichers []Enricher
+    js jetstream.JetStream
+    letters jetstream.JetStream // publishes to the dead-letter stream
+    jsConfig JetStreamConfig
+    workers int
+    queueSize int
+    pipeline *enricher.Pipeline
+    codec codec.Codec
+    rates RateRefresher
     health *health.Health
     db *sql.DB
     aggregator *aggregator.Service
     materializer *materializer.Service
+    eventTime *watermark.Buffer
+    dedup *dedup.Window
+    storing inFlight
+    events EventWriter
+    routes map[string]http.Handler
+}
+
+type Enricher = enricher.Enricher
+
+// EventWriter persists enriched events; it is implemented by
+// eventstore.Store. Write queues event under key, which identifies it
+// across redeliveries, and calls done with the result once it is stored,
+// in the order events were written.
+type EventWriter interface {
+    Write(key string, event casino.Event, done func(error))
 }
 
-type Enricher interface {
-    Enrich(context.Context, *casino.Event) error
+// RateRefresher is implemented by the exchange rate service.
+type RateRefresher interface {
+    RefreshRates() error
 }
 
+// New creates a subscriber running enrichers as a pipeline. Enrichers may
+// declare their name, dependencies and timeout by implementing
+// enricher.Specifier; see enricher.NewPipeline for the ordering rules.
 func New(natsURL string, enrichers ...Enricher) (*Service, error) {
-    nc, err := nats.Connect(natsURL)
+    pipeline, err := enricher.NewPipeline(enrichers...)
     if err != nil {
-        return nil, fmt.Errorf("failed to connect to NATS: %w", err)
+        return nil, fmt.Errorf("invalid enrichment pipeline: %w", err)
     }
 
-    // Get the database connection from the player enricher
-    var db *sql.DB
-    for _, e := range enrichers {
-        if pe, ok := e.(*player.Service); ok {
-            db = pe.DB()
-        }
+    nc, err := nats.Connect(natsURL)
+    if err != nil {
+        return nil, fmt.Errorf("failed to connect to NATS: %w", err)
     }
 
-    h := health.New(nc, db)
+    h := health.New(nc, nil)
 
-    // Create aggregator with 1-minute window
-    agg := aggregator.New(time.Minute)
+    agg := aggregator.New(aggregator.DefaultRetention)
     mat := materializer.New()
 
-    return &Service{
+    s := &Service{
         nc: nc,
-        enrichers: enrichers,
+        pipeline: pipeline,
+        codec: codec.JSON,
         health: h,
-        db: db,
         aggregator: agg,
         materializer: mat,
-    }, nil
+        dedup: dedup.New(dedup.DefaultSize, dedup.DefaultTTL),
+        workers: DefaultWorkers,
+        queueSize: DefaultQueueSize,
+    }
+    s.eventTime = watermark.NewBuffer(watermark.DefaultLateness, watermark.Drop, s.processEventTime)
+    return s, nil
+}
+
+// SetDB registers the database checked by the health endpoints.
+func (s *Service) SetDB(db *sql.DB) {
+    s.db = db
+    s.health = health.New(s.nc, db)
+}
+
+// SetCodec selects the wire format of enriched events, JSON by default.
+// Raw events are decoded by their Content-Type header whatever is set here.
+func (s *Service) SetCodec(c codec.Codec) {
+    s.codec = c
+}
+
+// SetEventStore persists every enriched event to w after publishing it.
+// The event is materialized and acknowledged once stored; one that cannot
+// be stored is retried like any other failure.
+func (s *Service) SetEventStore(w EventWriter) {
+    s.events = w
+}
+
+// SetAggregateRetention sets how much event time windowed aggregates and
+// leaderboards keep. It must be called before Start.
+func (s *Service) SetAggregateRetention(retention time.Duration) {
+    s.aggregator = aggregator.New(retention)
+    s.materializer.SetRetention(retention)
+}
+
+// SetSketches bounds the memory kept per player: unique players are
+// counted with HyperLogLogs, leaderboards are estimated with Count-Min
+// sketches and bet size quantiles are added with t-digests. It must be
+// called before Start and after SetAggregateRetention.
+func (s *Service) SetSketches(cfg sketch.Config) {
+    s.aggregator.SetSketches(cfg)
+    s.materializer.SetSketches(cfg)
+}
+
+// SetEventTime configures the watermark that orders events for windowed
+// aggregates and event-time rates: how late an event may arrive and still
+// be processed in order, and what happens to later ones. Under
+// watermark.SideOutput they are published to casino.events.late. It must
+// be called before Start.
+func (s *Service) SetEventTime(lateness time.Duration, policy watermark.Policy) {
+    s.eventTime = watermark.NewBuffer(lateness, policy, s.processEventTime)
+    s.eventTime.SetSideOutput(s.publishLate)
+}
+
+// SetRateRefresher enables periodic exchange rate refreshes.
+func (s *Service) SetRateRefresher(r RateRefresher) {
+    s.rates = r
+}
+
+// SetWorkerPool configures how many events are processed in parallel and
+// how many may wait per worker before the subscription is slowed down.
+// Events of the same player are always processed in order by one worker.
+func (s *Service) SetWorkerPool(workers, queueSize int) {
+    s.workers = workers
+    s.queueSize = queueSize
+}
+
+// Handle registers an extra handler on the HTTP server, such as an admin
+// API. It must be called before Start.
+func (s *Service) Handle(pattern string, h http.Handler) {
+    if s.routes == nil {
+        s.routes = make(map[string]http.Handler)
+    }
+    s.routes[pattern] = h
 }
 
 func (s *Service) Start(ctx context.Context) error {
+    if s.letters == nil {
+        s.ensureDeadLetters(ctx)
+    }
+
     // Set initial connection status
     log.Println("Setting initial metrics")
     metrics.ServiceUp.Set(1)
@@ -75,75 +186,226 @@ func (s *Service) Start(ctx context.Context) error {
 
     go s.startHTTP()
     go s.startRateRefresh(ctx)
+    go s.eventTime.Run(ctx)
+
+    pool := newWorkerPool(s.workers, s.queueSize)
+    pool.start(ctx, s.process)
+    defer pool.stop()
+    log.Printf("Processing events with %d workers, queue size %d", s.workers, s.queueSize)
+
+    if s.js != nil {
+        return s.consumeJetStream(ctx, pool)
+    }
 
     sub, err := s.nc.Subscribe(EventsTopic, func(msg *nats.Msg) {
-        start := time.Now()
-        metrics.IncrementEventsProcessed()
+        m := message{
+            data:        msg.Data,
+            contentType: msg.Header.Get(codec.Header),
+            sentAt:      msg.Header.Get(stream.SentAtHeader),
+        }
+        err := pool.submit(ctx, m, func(err error) {
+            if err == nil {
+                return
+            }
+            // Use a fresh context so events failed by shutdown still land in the DLQ.
+            if err := s.deadLetter(context.Background(), m, err, 1); err != nil {
+                log.Printf("Failed to dead-letter event: %v", err)
+            }
+        })
+        if err != nil {
+            log.Printf("Failed to queue event: %v", err)
+        }
+    })
+    if err != nil {
+        return fmt.Errorf("failed to subscribe: %w", err)
+    }
+    defer sub.Unsubscribe()
 
-        var event casino.Event
-        if err := json.Unmarshal(msg.Data, &event); err != nil {
-            log.Printf("Failed to unmarshal event: %v", err)
-            metrics.IncrementEnrichmentErrors()
+    <-ctx.Done()
+    return nil
+}
+
+// process enriches, outputs and materializes a single raw event, then
+// calls done with the result. A non-nil error means the event was not fully
+// handled; errMalformedEvent marks payloads that will never succeed and
+// must not be retried. With an event store, the event is materialized and
+// done is called once it is stored, from the store's writer; otherwise
+// before process returns.
+func (s *Service) process(ctx context.Context, msg message, done func(error)) {
+    start := time.Now()
+    env, data, err := s.enrich(ctx, msg)
+    if err != nil || data == nil {
+        done(err)
+        return
+    }
+    if s.events == nil {
+        s.complete(ctx, env, data, start)
+        done(nil)
+        return
+    }
+
+    s.startStoring(env)
+    s.events.Write(dedupKey(env), env.Event, func(err error) {
+        defer s.doneStoring(env)
+        if err != nil {
+            log.Printf("Failed to store enriched event: %v", err)
+            done(&stageError{stage: "store", err: fmt.Errorf("failed to store event %d: %w", env.Event.ID, err)})
             return
         }
+        s.complete(ctx, env, data, start)
+        done(nil)
+    })
+}
 
-        log.Printf("Processing event: %+v", event)
+// enrich decodes, validates, enriches and publishes a raw event. It returns
+// the envelope holding the enriched event and its encoding, or no encoding
+// if the event is a duplicate.
+func (s *Service) enrich(ctx context.Context, msg message) (envelope.Envelope, []byte, error) {
+    metrics.IncrementEventsProcessed()
 
-        // First enrich with player data and currency conversion
-        if err := s.enrichers[0].Enrich(ctx, &event); err != nil {
-            log.Printf("Player enricher failed: %v", err)
-            log.Printf("Failed to enrich event: %v", err)
-            metrics.IncrementEnrichmentErrors()
-            return  // Stop if currency conversion fails
-        }
+    c, err := codec.ForContentType(msg.contentType)
+    if err != nil {
+        log.Printf("Failed to decode event: %v", err)
+        metrics.IncrementEnrichmentErrors()
+        return envelope.Envelope{}, nil, &stageError{stage: "decode", err: fmt.Errorf("%w: %v", errMalformedEvent, err)}
+    }
+    metrics.EventsByCodec.WithLabelValues(c.Name()).Inc()
+
+    env, err := c.UnmarshalEnvelope(msg.data)
+    var verr *casino.ValidationError
+    if err != nil && !errors.As(err, &verr) {
+        log.Printf("Failed to unmarshal event: %v", err)
+        metrics.IncrementEnrichmentErrors()
+        return env, nil, &stageError{stage: "decode", err: fmt.Errorf("%w: %v", errMalformedEvent, err)}
+    }
+    event := env.Event
+    if err == nil {
+        metrics.EventsBySchemaVersion.WithLabelValues(strconv.Itoa(env.SchemaVersion)).Inc()
+        err = event.Validate()
+    }
+    if err != nil {
+        errors.As(err, &verr)
+        log.Printf("Rejected event %d: %v", event.ID, err)
+        metrics.InvalidEvents.WithLabelValues(verr.Reason).Inc()
+        return env, nil, &stageError{stage: "validate", err: fmt.Errorf("%w: %w", errMalformedEvent, err)}
+    }
 
-        // Then enrich with description
-        if err := s.enrichers[1].Enrich(ctx, &event); err != nil {
-            log.Printf("Description enricher failed: %v", err)
-            metrics.IncrementEnrichmentErrors()
-        }
+    if s.duplicate(env) {
+        return env, nil, nil
+    }
 
-        // Output the enriched event
-        data, _ := json.Marshal(event)
-        if err := s.nc.Publish("casino.events.enriched", data); err != nil {
-            log.Printf("Failed to publish enriched event: %v", err)
-            metrics.IncrementEnrichmentErrors()
-            return
-        }
+    log.Printf("Processing event: %+v", event)
 
-        // Process aggregates with EUR amounts
-        s.aggregator.Process(event)
-        s.materializer.Process(event)
+    outcomes, err := s.pipeline.Run(ctx, &event)
+    recordOutcomes(event.ID, outcomes)
+    if err != nil {
+        var se *enricher.StageError
+        errors.As(err, &se)
+        log.Printf("Failed to enrich event: %v", err)
+        return env, nil, &stageError{stage: se.Stage, err: fmt.Errorf("failed to enrich event %d: %w", event.ID, err)}
+    }
+
+    // Output the enriched event
+    data, err := s.codec.MarshalEvent(event)
+    if err != nil {
+        log.Printf("Failed to marshal enriched event: %v", err)
+        metrics.IncrementEnrichmentErrors()
+        return env, nil, &stageError{stage: "publish", err: fmt.Errorf("%w: %v", errMalformedEvent, err)}
+    }
+    if err := s.publishEnriched(ctx, data, msg.sentAt); err != nil {
+        log.Printf("Failed to publish enriched event: %v", err)
+        metrics.IncrementEnrichmentErrors()
+        return env, nil, &stageError{stage: "publish", err: fmt.Errorf("failed to publish enriched event %d: %w", event.ID, err)}
+    }
+
+    env.Event = event
+    return env, data, nil
+}
+
+// complete materializes an enriched event and records it as processed.
+func (s *Service) complete(ctx context.Context, env envelope.Envelope, data []byte, start time.Time) {
+    event := env.Event
 
-        metrics.IncrementEventsEnriched()
-        metrics.AddProcessingTime(time.Since(start))
+    // Process aggregates with EUR amounts
+    s.aggregator.Process(event)
+    s.materializer.Process(event)
+    s.eventTime.Add(event)
+    s.markProcessed(ctx, env)
+
+    metrics.IncrementEventsEnriched()
+    metrics.AddProcessingTime(time.Since(start))
+    if s.codec == codec.JSON {
         log.Println(string(data))
+    } else {
+        log.Printf("Enriched event: %+v", event)
+    }
+
+    // Increment total events
+    metrics.EventsProcessed.Inc()
 
-        // Increment total events
-        metrics.EventsProcessed.Inc()
+    // Increment by type
+    metrics.EventsByType.WithLabelValues(event.Type).Inc()
 
-        // Increment by type
-        metrics.EventsByType.WithLabelValues(event.Type).Inc()
+    // Increment by player
+    metrics.EventsByPlayer.WithLabelValues(fmt.Sprintf("%d", event.PlayerID)).Inc()
 
-        // Increment by player
-        metrics.EventsByPlayer.WithLabelValues(fmt.Sprintf("%d", event.PlayerID)).Inc()
+    // Increment by game
+    if event.GameID > 0 {
+        metrics.EventsByGame.WithLabelValues(
+            fmt.Sprintf("%d", event.GameID),
+            event.GameTitle(),
+        ).Inc()
+    }
+}
 
-        // Increment by game
-        if event.GameID > 0 {
-            game := casino.Games[event.GameID]
-            metrics.EventsByGame.WithLabelValues(
-                fmt.Sprintf("%d", event.GameID),
-                game.Title,
-            ).Inc()
+// recordOutcomes logs stage failures and exports per-stage metrics.
+func recordOutcomes(eventID int, outcomes []enricher.Outcome) {
+    for _, o := range outcomes {
+        metrics.EnrichmentStages.WithLabelValues(o.Stage, string(o.Status)).Inc()
+        if o.Status == enricher.StatusSkipped {
+            continue
+        }
+        metrics.EnrichmentStageDuration.WithLabelValues(o.Stage).Observe(o.Duration.Seconds())
+        if o.Status != enricher.StatusOK {
+            log.Printf("Enricher %s %s for event %d: %v", o.Stage, o.Status, eventID, o.Err)
+            metrics.IncrementEnrichmentErrors()
         }
-    })
-    if err != nil {
-        return fmt.Errorf("failed to subscribe: %w", err)
     }
-    defer sub.Unsubscribe()
+}
 
-    <-ctx.Done()
-    return nil
+func (s *Service) publishEnriched(ctx context.Context, data []byte, sentAt string) error {
+    msg := nats.NewMsg(EnrichedTopic)
+    msg.Header.Set(codec.Header, s.codec.ContentType())
+    if sentAt != "" {
+        msg.Header.Set(stream.SentAtHeader, sentAt)
+    }
+    msg.Data = data
+    if s.js != nil {
+        _, err := s.js.PublishMsg(ctx, msg)
+        return err
+    }
+    return s.nc.PublishMsg(msg)
+}
+
+// processEventTime receives events from the watermark in created_at order.
+func (s *Service) processEventTime(event casino.Event) {
+    s.aggregator.ProcessWindowed(event)
+    s.materializer.Observe(event)
+}
+
+// publishLate is the side output for late events.
+func (s *Service) publishLate(event casino.Event) {
+    data, err := s.codec.MarshalEvent(event)
+    if err != nil {
+        log.Printf("Failed to marshal late event %d: %v", event.ID, err)
+        return
+    }
+    msg := nats.NewMsg(stream.LateSubject)
+    msg.Header.Set(codec.Header, s.codec.ContentType())
+    msg.Data = data
+    if err := s.nc.PublishMsg(msg); err != nil {
+        log.Printf("Failed to publish late event %d: %v", event.ID, err)
+    }
 }
 
 func (s *Service) Close() error {
@@ -167,11 +429,7 @@ func (s *Service) startHTTP() {
 
     mux.HandleFunc("/health", s.healthHandler)
 
-    mux.HandleFunc("/aggregates", func(w http.ResponseWriter, r *http.Request) {
-        agg := s.aggregator.GetAggregates()
-        w.Header().Set("Content-Type", "application/json")
-        json.NewEncoder(w).Encode(agg)
-    })
+    mux.Handle("/aggregates", aggregator.Handler(s.aggregator))
 
     mux.HandleFunc("/materialized", func(w http.ResponseWriter, r *http.Request) {
         data := s.materializer.GetData()
@@ -179,6 +437,12 @@ func (s *Service) startHTTP() {
         json.NewEncoder(w).Encode(data)
     })
 
+    mux.Handle("/leaderboards/", materializer.Handler(s.materializer))
+
+    for pattern, h := range s.routes {
+        mux.Handle(pattern, h)
+    }
+
     srv := &http.Server{
         Addr:    ":8080",
         Handler: mux,
@@ -245,7 +509,10 @@ func (s *Service) healthHandler(w http.ResponseWriter, r *http.Request) {
     }
 
     // Check database
-    if err := s.db.PingContext(r.Context()); err != nil {
+    if s.db == nil {
+        status.Components["database"] = "not configured"
+        status.Healthy = false
+    } else if err := s.db.PingContext(r.Context()); err != nil {
         status.Components["database"] = fmt.Sprintf("error: %v", err)
         status.Healthy = false
     } else {
@@ -272,14 +539,7 @@ func (s *Service) healthHandler(w http.ResponseWriter, r *http.Request) {
 
 // startRateRefresh periodically checks and refreshes exchange rates
 func (s *Service) startRateRefresh(ctx context.Context) {
-    var exchange *exchange.Service
-    for _, e := range s.enrichers {
-        if pe, ok := e.(*player.Service); ok {
-            exchange = pe.GetExchangeService()
-            break
-        }
-    }
-    if exchange == nil {
+    if s.rates == nil {
         log.Printf("Warning: Exchange service not found, automatic rate refresh disabled")
         return
     }
@@ -307,7 +567,7 @@ func (s *Service) startRateRefresh(ctx context.Context) {
             return
         case <-ticker.C:
             log.Printf("Checking exchange rates for refresh...")
-            if err := exchange.RefreshRates(); err != nil {
+            if err := s.rates.RefreshRates(); err != nil {
                 log.Printf("Failed to refresh exchange rates: %v", err)
             } else {
                 log.Printf("Exchange rates refreshed successfully")
@@ -2,11 +2,14 @@ package subscriber
 
 import (
     "context"
-    "encoding/json"
+    "errors"
+    "sync"
     "testing"
     "time"
     "github.com/nats-io/nats.go"
     "github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/envelope"
+    "github.com/Bitstarz-eng/event-processing-challenge/internal/natstest"
 )
 
 // mockEnricher implements Enricher interface for testing
@@ -71,11 +74,7 @@ func TestSubscriber(t *testing.T) {
     time.Sleep(100 * time.Millisecond)
 
     // Publish test event
-    event := casino.Event{
-        ID:   1,
-        Type: "test",
-    }
-    data, _ := json.Marshal(event)
+    data, _ := envelope.New("test", validBet(1)).Marshal()
     if err := nc.Publish(EventsTopic, data); err != nil {
         t.Fatalf("Failed to publish event: %v", err)
     }
@@ -88,4 +87,84 @@ func TestSubscriber(t *testing.T) {
     case <-time.After(time.Second):
         t.Fatal("Timeout waiting for event enrichment")
     }
-} 
\ No newline at end of file
+} 
+
+// processWait processes msg and waits for its result.
+func (s *Service) processWait(ctx context.Context, msg message) error {
+    result := make(chan error, 1)
+    s.process(ctx, msg, func(err error) { result <- err })
+    return <-result
+}
+
+// fakeEventWriter stores events asynchronously, like eventstore.Store.
+type fakeEventWriter struct {
+    mu     sync.Mutex
+    keys   []string
+    events []casino.Event
+    err    error
+}
+
+func (f *fakeEventWriter) Write(key string, event casino.Event, done func(error)) {
+    go func() {
+        f.mu.Lock()
+        defer f.mu.Unlock()
+        if f.err != nil {
+            done(f.err)
+            return
+        }
+        f.keys = append(f.keys, key)
+        f.events = append(f.events, event)
+        done(nil)
+    }()
+}
+
+func TestProcessStoresEvents(t *testing.T) {
+    srv := natstest.RunJetStreamServer(t)
+
+    sub, err := New(srv.ClientURL(), &mockEnricher{
+        enrichFunc: func(ctx context.Context, event *casino.Event) error {
+            event.Description = "enriched"
+            return nil
+        },
+    })
+    if err != nil {
+        t.Fatalf("Failed to create subscriber: %v", err)
+    }
+    defer sub.Close()
+
+    store := &fakeEventWriter{}
+    sub.SetEventStore(store)
+
+    env := envelope.New("test", validBet(1))
+    data, _ := env.Marshal()
+    if err := sub.processWait(context.Background(), message{data: data}); err != nil {
+        t.Fatalf("process() error = %v", err)
+    }
+    if len(store.events) != 1 || store.events[0].Description != "enriched" {
+        t.Fatalf("Stored %+v, want the enriched event", store.events)
+    }
+    if store.keys[0] != env.EventID {
+        t.Errorf("Stored under key %q, want the envelope event ID %q", store.keys[0], env.EventID)
+    }
+
+    // A failed write fails the event so it is retried, and it is not
+    // remembered as processed.
+    store.err = errors.New("database down")
+    data, _ = envelope.New("test", validBet(2)).Marshal()
+    err = sub.processWait(context.Background(), message{data: data})
+    var se *stageError
+    if !errors.As(err, &se) || se.stage != "store" {
+        t.Fatalf("process() error = %v, want a store stage error", err)
+    }
+    if errors.Is(err, errMalformedEvent) {
+        t.Error("Store failure marked as malformed, want it retried")
+    }
+
+    store.err = nil
+    if err := sub.processWait(context.Background(), message{data: data}); err != nil {
+        t.Fatalf("process() of retry error = %v", err)
+    }
+    if len(store.events) != 2 {
+        t.Errorf("Stored %d events, want 2", len(store.events))
+    }
+}
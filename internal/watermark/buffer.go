@@ -0,0 +1,216 @@
+// Package watermark orders events by event time. A Buffer tracks the
+// watermark, the newest created_at seen minus the allowed lateness, holds
+// events until the watermark passes them and releases them in created_at
+// order. Events that arrive already behind the watermark are late and are
+// handled by a Policy. Events created further ahead of the wall clock than
+// the lateness do not move the watermark, so one event from a clock far
+// ahead cannot make every later event late.
+package watermark
+
+import (
+	"container/heap"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/metrics"
+)
+
+// DefaultLateness is how far behind the newest event time an event may
+// arrive and still be released in order.
+const DefaultLateness = 5 * time.Second
+
+// minIdle bounds how often Run checks for a quiet stream.
+var minIdle = time.Second
+
+// Policy decides what happens to late events. Every late event is counted
+// in casino_late_events_total whatever the policy.
+type Policy string
+
+const (
+	// Drop discards late events.
+	Drop Policy = "drop"
+	// SideOutput hands late events to the side output instead, such as a
+	// casino.events.late subject.
+	SideOutput Policy = "side-output"
+	// Update releases late events immediately, out of order, so that
+	// results they belong to are updated after the fact.
+	Update Policy = "update"
+)
+
+func ParsePolicy(s string) (Policy, error) {
+	switch p := Policy(s); p {
+	case Drop, SideOutput, Update:
+		return p, nil
+	}
+	return "", fmt.Errorf("unknown late event policy %q, want %s, %s or %s", s, Drop, SideOutput, Update)
+}
+
+// Buffer reorders events by created_at. It is safe for concurrent use;
+// emit and the side output are called with the buffer locked, one event at
+// a time.
+type Buffer struct {
+	lateness time.Duration
+	policy   Policy
+	emit     func(casino.Event)
+	side     func(casino.Event)
+
+	mu      sync.Mutex
+	newest  time.Time // newest created_at seen, up to now plus the lateness
+	pending eventHeap
+	seq     uint64
+	lastAdd time.Time
+	now     func() time.Time
+}
+
+// NewBuffer releases events to emit once they are no longer than lateness
+// ahead of the watermark, with late events handled by policy.
+func NewBuffer(lateness time.Duration, policy Policy, emit func(casino.Event)) *Buffer {
+	return &Buffer{lateness: lateness, policy: policy, emit: emit, now: time.Now}
+}
+
+// SetSideOutput sets where late events go under the SideOutput policy.
+// Without one they are dropped.
+func (b *Buffer) SetSideOutput(f func(casino.Event)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.side = f
+}
+
+// Watermark returns the event time up to which all events are assumed to
+// have arrived. It is zero before the first event.
+func (b *Buffer) Watermark() time.Time {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.watermark()
+}
+
+func (b *Buffer) watermark() time.Time {
+	if b.newest.IsZero() {
+		return time.Time{}
+	}
+	return b.newest.Add(-b.lateness)
+}
+
+// Len returns how many events are waiting for the watermark.
+func (b *Buffer) Len() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.pending)
+}
+
+// Add buffers event and releases every event the watermark has passed. It
+// reports whether the event was late.
+func (b *Buffer) Add(event casino.Event) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.lastAdd = b.now()
+
+	if wm := b.watermark(); !wm.IsZero() && event.CreatedAt.Before(wm) {
+		b.late(event)
+		return true
+	}
+
+	b.seq++
+	heap.Push(&b.pending, pending{event: event, seq: b.seq})
+	switch {
+	case event.CreatedAt.After(b.lastAdd.Add(b.lateness)):
+		// Still released in order, once the watermark catches up or the
+		// stream goes quiet.
+		metrics.FutureEvents.Inc()
+	case event.CreatedAt.After(b.newest):
+		b.newest = event.CreatedAt
+		metrics.Watermark.Set(float64(b.watermark().UnixMilli()) / 1000)
+	}
+	b.release(b.watermark())
+	return false
+}
+
+func (b *Buffer) late(event casino.Event) {
+	metrics.LateEvents.WithLabelValues(string(b.policy)).Inc()
+	switch b.policy {
+	case SideOutput:
+		if b.side != nil {
+			b.side(event)
+		}
+	case Update:
+		b.emit(event)
+	}
+}
+
+// release emits pending events created at or before upTo, oldest first.
+// A zero upTo releases everything.
+func (b *Buffer) release(upTo time.Time) {
+	for len(b.pending) > 0 {
+		next := b.pending[0].event
+		if !upTo.IsZero() && next.CreatedAt.After(upTo) {
+			break
+		}
+		heap.Pop(&b.pending)
+		b.emit(next)
+	}
+	metrics.EventTimeBuffered.Set(float64(len(b.pending)))
+}
+
+// Flush releases every pending event, in order, without moving the
+// watermark.
+func (b *Buffer) Flush() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.release(time.Time{})
+}
+
+// Run flushes the buffer whenever no event has been added for the
+// lateness, at least a second, so that the last events are released when
+// the stream goes quiet. It flushes once more when ctx is done.
+func (b *Buffer) Run(ctx context.Context) {
+	idle := b.lateness
+	if idle < minIdle {
+		idle = minIdle
+	}
+	ticker := time.NewTicker(idle / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			b.Flush()
+			return
+		case <-ticker.C:
+			b.mu.Lock()
+			if len(b.pending) > 0 && b.now().Sub(b.lastAdd) >= idle {
+				b.release(time.Time{})
+			}
+			b.mu.Unlock()
+		}
+	}
+}
+
+type pending struct {
+	event casino.Event
+	seq   uint64 // arrival order, to keep equal times stable
+}
+
+// eventHeap orders pending events by created_at, then by arrival.
+type eventHeap []pending
+
+func (h eventHeap) Len() int { return len(h) }
+
+func (h eventHeap) Less(i, j int) bool {
+	if h[i].event.CreatedAt.Equal(h[j].event.CreatedAt) {
+		return h[i].seq < h[j].seq
+	}
+	return h[i].event.CreatedAt.Before(h[j].event.CreatedAt)
+}
+
+func (h eventHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *eventHeap) Push(x interface{}) { *h = append(*h, x.(pending)) }
+
+func (h *eventHeap) Pop() interface{} {
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
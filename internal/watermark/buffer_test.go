@@ -0,0 +1,139 @@
+package watermark
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Bitstarz-eng/event-processing-challenge/internal/casino"
+)
+
+var base = time.Date(2024, 2, 24, 12, 0, 0, 0, time.UTC)
+
+func at(id int, offset time.Duration) casino.Event {
+	return casino.Event{ID: id, CreatedAt: base.Add(offset)}
+}
+
+func ids(events []casino.Event) []int {
+	out := make([]int, len(events))
+	for i, e := range events {
+		out[i] = e.ID
+	}
+	return out
+}
+
+func TestBufferReorders(t *testing.T) {
+	var out []casino.Event
+	b := NewBuffer(5*time.Second, Drop, func(e casino.Event) { out = append(out, e) })
+
+	b.Add(at(1, 0))
+	b.Add(at(3, 3*time.Second))
+	b.Add(at(2, 2*time.Second)) // out of order, within lateness
+	if len(out) != 0 {
+		t.Fatalf("released %v before the watermark passed them", ids(out))
+	}
+
+	// Moves the watermark to 12:00:05, releasing 1, 2 and 3 in order.
+	b.Add(at(4, 10*time.Second))
+	if want := []int{1, 2, 3}; !reflect.DeepEqual(ids(out), want) {
+		t.Errorf("released %v, want %v", ids(out), want)
+	}
+	if wm := b.Watermark(); !wm.Equal(base.Add(5 * time.Second)) {
+		t.Errorf("Watermark() = %v, want 12:00:05", wm)
+	}
+	if b.Len() != 1 {
+		t.Errorf("Len() = %d, want 1", b.Len())
+	}
+
+	b.Flush()
+	if want := []int{1, 2, 3, 4}; !reflect.DeepEqual(ids(out), want) {
+		t.Errorf("released %v after flush, want %v", ids(out), want)
+	}
+}
+
+func TestBufferLatePolicies(t *testing.T) {
+	tests := []struct {
+		policy   Policy
+		released []int
+		side     []int
+	}{
+		{Drop, []int{1}, nil},
+		{SideOutput, []int{1}, []int{2}},
+		{Update, []int{2, 1}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.policy), func(t *testing.T) {
+			var out, side []casino.Event
+			b := NewBuffer(time.Second, tt.policy, func(e casino.Event) { out = append(out, e) })
+			b.SetSideOutput(func(e casino.Event) { side = append(side, e) })
+
+			b.Add(at(1, time.Minute))
+			if late := b.Add(at(2, 0)); !late {
+				t.Error("Add() of an event behind the watermark = false, want late")
+			}
+			b.Flush()
+
+			if !reflect.DeepEqual(ids(out), tt.released) {
+				t.Errorf("released %v, want %v", ids(out), tt.released)
+			}
+			if len(side) != len(tt.side) || (len(side) > 0 && side[0].ID != tt.side[0]) {
+				t.Errorf("side output %v, want %v", ids(side), tt.side)
+			}
+		})
+	}
+}
+
+func TestBufferCapsSkewedEvent(t *testing.T) {
+	var out []casino.Event
+	b := NewBuffer(5*time.Second, Drop, func(e casino.Event) { out = append(out, e) })
+	b.now = func() time.Time { return base.Add(time.Minute) }
+
+	b.Add(at(1, 10*time.Second))
+	b.Add(at(2, 365*24*time.Hour)) // producer clock a year ahead
+	if wm := b.Watermark(); !wm.Equal(base.Add(5 * time.Second)) {
+		t.Errorf("Watermark() = %v, want 12:00:05 unmoved by the skewed event", wm)
+	}
+
+	// Events after the skewed one are not late.
+	if late := b.Add(at(3, 30*time.Second)); late {
+		t.Error("Add() after a skewed event = late, want it buffered")
+	}
+	b.Flush()
+	if want := []int{1, 3, 2}; !reflect.DeepEqual(ids(out), want) {
+		t.Errorf("released %v, want %v", ids(out), want)
+	}
+}
+
+func TestBufferRunFlushesWhenIdle(t *testing.T) {
+	released := make(chan casino.Event, 1)
+	defer func(d time.Duration) { minIdle = d }(minIdle)
+	minIdle = 20 * time.Millisecond
+	b := NewBuffer(20*time.Millisecond, Drop, func(e casino.Event) { released <- e })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go b.Run(ctx)
+
+	b.Add(at(1, 0))
+	select {
+	case e := <-released:
+		if e.ID != 1 {
+			t.Errorf("released event %d, want 1", e.ID)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("idle buffer was not flushed")
+	}
+}
+
+func TestParsePolicy(t *testing.T) {
+	for _, s := range []string{"drop", "side-output", "update"} {
+		if p, err := ParsePolicy(s); err != nil || string(p) != s {
+			t.Errorf("ParsePolicy(%q) = %q, %v", s, p, err)
+		}
+	}
+	if _, err := ParsePolicy("ignore"); err == nil {
+		t.Error("ParsePolicy(ignore) error = nil")
+	}
+}